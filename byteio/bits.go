@@ -0,0 +1,215 @@
+package byteio
+
+import (
+	"errors"
+	"io"
+	"math"
+	mbits "math/bits"
+)
+
+// BitOrder selects the order in which bits are taken from, or placed into,
+// each byte by a BitReader or BitWriter.
+type BitOrder int
+
+const (
+	// MSBFirst packs fields starting at the most significant bit of each
+	// byte, with the most significant bit of a field first. This is the
+	// order used by H.264 and most network protocol headers.
+	MSBFirst BitOrder = iota
+
+	// LSBFirst packs fields starting at the least significant bit of each
+	// byte, with the least significant bit of a field first. This is the
+	// order used by DEFLATE and CAN.
+	LSBFirst
+)
+
+var errBitCount = errors.New("byteio: bit count must be between 0 and 64")
+
+// BitReader reads fields of arbitrary bit width from an underlying Reader. It
+// never reads more than one byte ahead, so after a call to Align the
+// underlying Reader may be used directly.
+type BitReader struct {
+	bin   Reader
+	order BitOrder
+	cur   byte // partially consumed byte
+	n     uint // number of unread bits in cur
+}
+
+// NewBitReader returns a BitReader which reads from bin in the given bit
+// order.
+func NewBitReader(bin Reader, order BitOrder) *BitReader {
+	return &BitReader{bin: bin, order: order}
+}
+
+// ReadBits reads a field of n bits (0 ≤ n ≤ 64). If the stream ends before
+// any bits of the field have been read, io.EOF is returned; if it ends
+// part-way through the field, io.ErrUnexpectedEOF is returned.
+func (br *BitReader) ReadBits(n int) (uint64, error) {
+	if n < 0 || n > 64 {
+		return 0, errBitCount
+	}
+
+	var v uint64
+	for got := uint(0); got < uint(n); {
+		if br.n == 0 {
+			b, err := br.bin.ReadByte()
+			if err != nil {
+				if got > 0 && err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return 0, err
+			}
+			br.cur, br.n = b, 8
+		}
+
+		take := uint(n) - got
+		if take > br.n {
+			take = br.n
+		}
+		mask := byte(1<<take - 1)
+		if br.order == LSBFirst {
+			x := (br.cur >> (8 - br.n)) & mask
+			v |= uint64(x) << got
+		} else {
+			x := (br.cur >> (br.n - take)) & mask
+			v = v<<take | uint64(x)
+		}
+		br.n -= take
+		got += take
+	}
+	return v, nil
+}
+
+// ReadUE reads an unsigned Exp-Golomb code, as used by H.264 for ue(v)
+// fields. ErrOverflow is returned if the code does not fit into 64 bits.
+func (br *BitReader) ReadUE() (uint64, error) {
+	lz := 0
+	for {
+		b, err := br.ReadBits(1)
+		if err != nil {
+			if lz > 0 && err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		if b == 1 {
+			break
+		}
+		if lz++; lz > 63 {
+			return 0, ErrOverflow
+		}
+	}
+
+	v, err := br.ReadBits(lz)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return 0, err
+	}
+	return (1<<uint(lz) | v) - 1, nil
+}
+
+// ReadSE reads a signed Exp-Golomb code, as used by H.264 for se(v) fields.
+func (br *BitReader) ReadSE() (int64, error) {
+	k, err := br.ReadUE()
+	if err != nil {
+		return 0, err
+	}
+	if k&1 != 0 {
+		return int64(k/2) + 1, nil
+	}
+	return -int64(k / 2), nil
+}
+
+// Align discards any unread bits of the current byte, so that the next read
+// starts on a byte boundary.
+func (br *BitReader) Align() {
+	br.n = 0
+}
+
+// BitWriter writes fields of arbitrary bit width to an underlying Writer.
+// Bytes are written to the underlying Writer as soon as they are complete;
+// Align must be called to write out any final partial byte.
+type BitWriter struct {
+	bout  Writer
+	order BitOrder
+	cur   byte // partially filled byte
+	n     uint // number of bits filled in cur
+}
+
+// NewBitWriter returns a BitWriter which writes to bout in the given bit
+// order.
+func NewBitWriter(bout Writer, order BitOrder) *BitWriter {
+	return &BitWriter{bout: bout, order: order}
+}
+
+// WriteBits writes the low n bits of v (0 ≤ n ≤ 64) as a single field.
+func (bw *BitWriter) WriteBits(v uint64, n int) error {
+	if n < 0 || n > 64 {
+		return errBitCount
+	}
+
+	for put := uint(0); put < uint(n); {
+		take := uint(n) - put
+		if take > 8-bw.n {
+			take = 8 - bw.n
+		}
+		mask := uint64(1)<<take - 1
+		if bw.order == LSBFirst {
+			x := byte((v >> put) & mask)
+			bw.cur |= x << bw.n
+		} else {
+			x := byte((v >> (uint(n) - put - take)) & mask)
+			bw.cur |= x << (8 - bw.n - take)
+		}
+		bw.n += take
+		put += take
+
+		if bw.n == 8 {
+			if err := bw.bout.WriteByte(bw.cur); err != nil {
+				return err
+			}
+			bw.cur, bw.n = 0, 0
+		}
+	}
+	return nil
+}
+
+// WriteUE writes an unsigned Exp-Golomb code. ErrOverflow is returned for
+// math.MaxUint64, which cannot be encoded.
+func (bw *BitWriter) WriteUE(v uint64) error {
+	if v == math.MaxUint64 {
+		return ErrOverflow
+	}
+	v++
+	lz := mbits.Len64(v) - 1
+	if err := bw.WriteBits(0, lz); err != nil {
+		return err
+	}
+	return bw.WriteBits(v, lz+1)
+}
+
+// WriteSE writes a signed Exp-Golomb code. ErrOverflow is returned for
+// math.MinInt64, which cannot be encoded.
+func (bw *BitWriter) WriteSE(v int64) error {
+	switch {
+	case v == math.MinInt64:
+		return ErrOverflow
+	case v > 0:
+		return bw.WriteUE(uint64(v)*2 - 1)
+	default:
+		return bw.WriteUE(uint64(-v) * 2)
+	}
+}
+
+// Align pads the current byte with zero bits, if it is partially filled, and
+// writes it out. After Align the underlying Writer may be used directly.
+func (bw *BitWriter) Align() error {
+	if bw.n == 0 {
+		return nil
+	}
+	err := bw.bout.WriteByte(bw.cur)
+	bw.cur, bw.n = 0, 0
+	return err
+}
@@ -0,0 +1,232 @@
+package byteio_test
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// bitString renders buf as a string of '0'/'1' characters, MSB first.
+func bitString(buf []byte) string {
+	var sb strings.Builder
+	for _, b := range buf {
+		for i := 7; i >= 0; i-- {
+			sb.WriteByte('0' + (b>>uint(i))&1)
+		}
+	}
+	return sb.String()
+}
+
+// TestBitReaderMSB checks MSB-first reads against a known bit pattern.
+func TestBitReaderMSB(t *testing.T) {
+	br := byteio.NewBitReader(bytes.NewReader([]byte{0xB3, 0x5A, 0xF0}),
+		byteio.MSBFirst)
+	// 1011 0011 0101 1010 1111 0000
+	for _, f := range []struct {
+		n   int
+		exp uint64
+	}{
+		{1, 1}, {3, 0x3}, {0, 0}, {6, 0x0D}, {10, 0x1AF}, {4, 0},
+	} {
+		act, err := br.ReadBits(f.n)
+		if err != nil {
+			t.Fatalf("ReadBits(%d): unexpected error %v", f.n, err)
+		}
+		if act != f.exp {
+			t.Errorf("ReadBits(%d): act %X ≠ exp %X", f.n, act, f.exp)
+		}
+	}
+	if _, err := br.ReadBits(1); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+// TestBitReaderLSB checks LSB-first reads against a known bit pattern.
+func TestBitReaderLSB(t *testing.T) {
+	br := byteio.NewBitReader(bytes.NewReader([]byte{0xB3, 0x5A}),
+		byteio.LSBFirst)
+	for _, f := range []struct {
+		n   int
+		exp uint64
+	}{
+		{1, 1}, {3, 0x1}, {6, 0x2B}, {6, 0x16},
+	} {
+		act, err := br.ReadBits(f.n)
+		if err != nil {
+			t.Fatalf("ReadBits(%d): unexpected error %v", f.n, err)
+		}
+		if act != f.exp {
+			t.Errorf("ReadBits(%d): act %X ≠ exp %X", f.n, act, f.exp)
+		}
+	}
+}
+
+// TestBitRoundTrip writes random fields of random widths and reads them back
+// in both bit orders.
+func TestBitRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, order := range []byteio.BitOrder{byteio.MSBFirst, byteio.LSBFirst} {
+		widths := make([]int, 500)
+		vals := make([]uint64, len(widths))
+		buf := bytes.NewBuffer(nil)
+		bw := byteio.NewBitWriter(buf, order)
+		for i := range widths {
+			widths[i] = rng.Intn(65)
+			vals[i] = rng.Uint64()
+			if widths[i] < 64 {
+				vals[i] &= 1<<uint(widths[i]) - 1
+			}
+			if err := bw.WriteBits(vals[i], widths[i]); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if err := bw.Align(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		br := byteio.NewBitReader(buf, order)
+		for i := range widths {
+			act, err := br.ReadBits(widths[i])
+			if err != nil {
+				t.Fatalf("%d: unexpected error %v", i, err)
+			}
+			if act != vals[i] {
+				t.Errorf("%d: ReadBits(%d): act %X ≠ exp %X",
+					i, widths[i], act, vals[i])
+			}
+		}
+	}
+}
+
+// TestBitAlign checks that Align skips to (or pads to) a byte boundary.
+func TestBitAlign(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	bw := byteio.NewBitWriter(buf, byteio.MSBFirst)
+	bw.WriteBits(0x5, 3)
+	bw.Align()
+	bw.Align() // no-op
+	bw.WriteBits(0xFF, 8)
+	bw.WriteBits(1, 1)
+	bw.Align()
+	if exp := "101000001111111110000000"; bitString(buf.Bytes()) != exp {
+		t.Errorf("act %s ≠ exp %s", bitString(buf.Bytes()), exp)
+	}
+
+	br := byteio.NewBitReader(buf, byteio.MSBFirst)
+	br.ReadBits(2)
+	br.Align()
+	if v, _ := br.ReadBits(8); v != 0xFF {
+		t.Errorf("after Align: act %X ≠ exp FF", v)
+	}
+	br.Align() // no-op
+	if v, _ := br.ReadBits(1); v != 1 {
+		t.Errorf("after Align: act %X ≠ exp 1", v)
+	}
+}
+
+// TestExpGolomb checks ue(v) and se(v) codes against known encodings.
+func TestExpGolomb(t *testing.T) {
+	ue := []struct {
+		v   uint64
+		enc string
+	}{
+		{0, "1"}, {1, "010"}, {2, "011"}, {3, "00100"}, {6, "00111"},
+		{7, "0001000"},
+	}
+	for _, c := range ue {
+		buf := bytes.NewBuffer(nil)
+		bw := byteio.NewBitWriter(buf, byteio.MSBFirst)
+		bw.WriteUE(c.v)
+		bw.Align()
+		act := bitString(buf.Bytes())
+		if !strings.HasPrefix(act, c.enc) || strings.Contains(act[len(c.enc):], "1") {
+			t.Errorf("WriteUE(%d): act %s ≠ exp %s", c.v, act, c.enc)
+		}
+		br := byteio.NewBitReader(buf, byteio.MSBFirst)
+		if v, err := br.ReadUE(); err != nil || v != c.v {
+			t.Errorf("ReadUE(%s): act %d, %v ≠ exp %d", c.enc, v, err, c.v)
+		}
+	}
+
+	se := []int64{0, 1, -1, 2, -2, 1000, -1000, math.MaxInt64, math.MinInt64 + 1}
+	buf := bytes.NewBuffer(nil)
+	bw := byteio.NewBitWriter(buf, byteio.MSBFirst)
+	for _, v := range se {
+		if err := bw.WriteSE(v); err != nil {
+			t.Fatalf("WriteSE(%d): unexpected error %v", v, err)
+		}
+	}
+	bw.WriteUE(math.MaxUint64 - 1)
+	bw.Align()
+	br := byteio.NewBitReader(buf, byteio.MSBFirst)
+	for _, exp := range se {
+		if act, err := br.ReadSE(); err != nil || act != exp {
+			t.Errorf("ReadSE: act %d, %v ≠ exp %d", act, err, exp)
+		}
+	}
+	if act, err := br.ReadUE(); err != nil || act != math.MaxUint64-1 {
+		t.Errorf("ReadUE: act %d, %v ≠ exp %d", act, err, uint64(math.MaxUint64-1))
+	}
+
+	if err := bw.WriteUE(math.MaxUint64); err != byteio.ErrOverflow {
+		t.Errorf("WriteUE(max): unexpected error %v", err)
+	}
+	if err := bw.WriteSE(math.MinInt64); err != byteio.ErrOverflow {
+		t.Errorf("WriteSE(min): unexpected error %v", err)
+	}
+	br = byteio.NewBitReader(bytes.NewReader(make([]byte, 9)), byteio.MSBFirst)
+	if _, err := br.ReadUE(); err != byteio.ErrOverflow {
+		t.Errorf("ReadUE(zeros): unexpected error %v", err)
+	}
+}
+
+// TestBitReaderEOF ensures that io.EOF is returned only when a field starts
+// at the end of the stream, and io.ErrUnexpectedEOF otherwise.
+func TestBitReaderEOF(t *testing.T) {
+	br := byteio.NewBitReader(bytes.NewReader([]byte{0xFF}), byteio.MSBFirst)
+	if _, err := br.ReadBits(12); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+
+	br = byteio.NewBitReader(bytes.NewReader([]byte{0xFF}), byteio.MSBFirst)
+	br.ReadBits(4)
+	if _, err := br.ReadBits(8); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+
+	br = byteio.NewBitReader(bytes.NewReader([]byte{0x00}), byteio.MSBFirst)
+	if _, err := br.ReadUE(); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadUE: expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	br = byteio.NewBitReader(bytes.NewReader([]byte{0x01}), byteio.MSBFirst)
+	if _, err := br.ReadUE(); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadUE: expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	br = byteio.NewBitReader(bytes.NewReader(nil), byteio.MSBFirst)
+	if _, err := br.ReadUE(); err != io.EOF {
+		t.Errorf("ReadUE: expected io.EOF, got %v", err)
+	}
+
+	if _, err := br.ReadBits(65); err == nil {
+		t.Error("ReadBits(65) did not return error")
+	}
+	if err := byteio.NewBitWriter(nil, byteio.MSBFirst).WriteBits(0, -1); err == nil {
+		t.Error("WriteBits(-1) did not return error")
+	}
+}
+
+// TestBitWriterErr ensures that write errors are propagated.
+func TestBitWriterErr(t *testing.T) {
+	bw := byteio.NewBitWriter(&AbortWriter{when: 1}, byteio.MSBFirst)
+	if err := bw.WriteBits(0, 8); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := bw.WriteBits(0, 12); err != ErrAbortWriter {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,732 @@
+package byteio
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"sync"
+)
+
+const (
+	// bulkChunk is the size of the scratch buffer used by the bulk
+	// functions.
+	bulkChunk = 4096
+
+	// bulkMinBytes is the size below which the bulk functions fall back
+	// to per-value calls, which are faster for small amounts of data.
+	bulkMinBytes = 32
+)
+
+var bulkPool = sync.Pool{
+	New: func() interface{} { return new([bulkChunk]byte) },
+}
+
+// bulkRead reads count values of width bytes each from bin, a chunk at a time,
+// passing each chunk to decode along with the index of its first value. Errors
+// are reported at the offset of the value being read, as by the value readers.
+func bulkRead(bin Reader, width, count int,
+	decode func(b []byte, first int)) error {
+	scratch := bulkPool.Get().(*[bulkChunk]byte)
+	defer bulkPool.Put(scratch)
+
+	per := bulkChunk / width
+	for first := 0; first < count; first += per {
+		n := count - first
+		if n > per {
+			n = per
+		}
+		b := scratch[:n*width]
+		if m, err := io.ReadFull(bin, b); err != nil {
+			err = midRecord(first > 0 || m > 0, err)
+			return readErr(bin, m%width, err)
+		}
+		decode(b, first)
+	}
+	return nil
+}
+
+// elemErr adjusts the error from reading value i of a slice one at a time. The
+// value reader has already passed any error through readErr, except for io.EOF
+// at its start, which after the first value becomes io.ErrUnexpectedEOF at the
+// offset of value i, as bulkRead reports it.
+func elemErr(bin Reader, i int, err error) error {
+	if err == io.EOF && i > 0 {
+		return readErr(bin, 0, io.ErrUnexpectedEOF)
+	}
+	return midRecord(i > 0, err)
+}
+
+// bulkWrite writes count values of width bytes each to bout, a chunk at a time,
+// calling encode to fill each chunk starting at the given value index.
+func bulkWrite(bout Writer, width, count int,
+	encode func(b []byte, first int)) error {
+	scratch := bulkPool.Get().(*[bulkChunk]byte)
+	defer bulkPool.Put(scratch)
+
+	per := bulkChunk / width
+	for first := 0; first < count; first += per {
+		n := count - first
+		if n > per {
+			n = per
+		}
+		b := scratch[:n*width]
+		encode(b, first)
+		if _, err := bout.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadUint16sBE fills dst with unsigned uint16s in big-endian (network) byte
+// order. If bin is at end of file before any data has been read, io.EOF is
+// returned; if it ends part-way through, io.ErrUnexpectedEOF is returned and
+// the contents of dst are unspecified.
+func ReadUint16sBE(bin Reader, dst []uint16) error {
+	if len(dst)*2 < bulkMinBytes {
+		for i := range dst {
+			v, err := ReadUint16BE(bin)
+			if err != nil {
+				return elemErr(bin, i, err)
+			}
+			dst[i] = v
+		}
+		return nil
+	}
+	return bulkRead(bin, 2, len(dst), func(b []byte, first int) {
+		d := dst[first : first+len(b)/2]
+		for i := range d {
+			d[i] = binary.BigEndian.Uint16(b[2*i:])
+		}
+	})
+}
+
+// WriteUint16sBE writes the unsigned uint16s in src in big-endian (network)
+// byte order.
+func WriteUint16sBE(bout Writer, src []uint16) error {
+	if len(src)*2 < bulkMinBytes {
+		for _, v := range src {
+			if err := WriteUint16BE(bout, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return bulkWrite(bout, 2, len(src), func(b []byte, first int) {
+		for i, v := range src[first : first+len(b)/2] {
+			binary.BigEndian.PutUint16(b[2*i:], v)
+		}
+	})
+}
+
+// ReadInt16sBE fills dst with signed int16s in big-endian (network) byte order.
+// If bin is at end of file before any data has been read, io.EOF is returned;
+// if it ends part-way through, io.ErrUnexpectedEOF is returned and the contents
+// of dst are unspecified.
+func ReadInt16sBE(bin Reader, dst []int16) error {
+	if len(dst)*2 < bulkMinBytes {
+		for i := range dst {
+			v, err := ReadInt16BE(bin)
+			if err != nil {
+				return elemErr(bin, i, err)
+			}
+			dst[i] = v
+		}
+		return nil
+	}
+	return bulkRead(bin, 2, len(dst), func(b []byte, first int) {
+		d := dst[first : first+len(b)/2]
+		for i := range d {
+			d[i] = int16(binary.BigEndian.Uint16(b[2*i:]))
+		}
+	})
+}
+
+// WriteInt16sBE writes the signed int16s in src in big-endian (network) byte
+// order.
+func WriteInt16sBE(bout Writer, src []int16) error {
+	if len(src)*2 < bulkMinBytes {
+		for _, v := range src {
+			if err := WriteInt16BE(bout, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return bulkWrite(bout, 2, len(src), func(b []byte, first int) {
+		for i, v := range src[first : first+len(b)/2] {
+			binary.BigEndian.PutUint16(b[2*i:], uint16(v))
+		}
+	})
+}
+
+// ReadUint32sBE fills dst with unsigned uint32s in big-endian (network) byte
+// order. If bin is at end of file before any data has been read, io.EOF is
+// returned; if it ends part-way through, io.ErrUnexpectedEOF is returned and
+// the contents of dst are unspecified.
+func ReadUint32sBE(bin Reader, dst []uint32) error {
+	if len(dst)*4 < bulkMinBytes {
+		for i := range dst {
+			v, err := ReadUint32BE(bin)
+			if err != nil {
+				return elemErr(bin, i, err)
+			}
+			dst[i] = v
+		}
+		return nil
+	}
+	return bulkRead(bin, 4, len(dst), func(b []byte, first int) {
+		d := dst[first : first+len(b)/4]
+		for i := range d {
+			d[i] = binary.BigEndian.Uint32(b[4*i:])
+		}
+	})
+}
+
+// WriteUint32sBE writes the unsigned uint32s in src in big-endian (network)
+// byte order.
+func WriteUint32sBE(bout Writer, src []uint32) error {
+	if len(src)*4 < bulkMinBytes {
+		for _, v := range src {
+			if err := WriteUint32BE(bout, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return bulkWrite(bout, 4, len(src), func(b []byte, first int) {
+		for i, v := range src[first : first+len(b)/4] {
+			binary.BigEndian.PutUint32(b[4*i:], v)
+		}
+	})
+}
+
+// ReadInt32sBE fills dst with signed int32s in big-endian (network) byte order.
+// If bin is at end of file before any data has been read, io.EOF is returned;
+// if it ends part-way through, io.ErrUnexpectedEOF is returned and the contents
+// of dst are unspecified.
+func ReadInt32sBE(bin Reader, dst []int32) error {
+	if len(dst)*4 < bulkMinBytes {
+		for i := range dst {
+			v, err := ReadInt32BE(bin)
+			if err != nil {
+				return elemErr(bin, i, err)
+			}
+			dst[i] = v
+		}
+		return nil
+	}
+	return bulkRead(bin, 4, len(dst), func(b []byte, first int) {
+		d := dst[first : first+len(b)/4]
+		for i := range d {
+			d[i] = int32(binary.BigEndian.Uint32(b[4*i:]))
+		}
+	})
+}
+
+// WriteInt32sBE writes the signed int32s in src in big-endian (network) byte
+// order.
+func WriteInt32sBE(bout Writer, src []int32) error {
+	if len(src)*4 < bulkMinBytes {
+		for _, v := range src {
+			if err := WriteInt32BE(bout, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return bulkWrite(bout, 4, len(src), func(b []byte, first int) {
+		for i, v := range src[first : first+len(b)/4] {
+			binary.BigEndian.PutUint32(b[4*i:], uint32(v))
+		}
+	})
+}
+
+// ReadFloat32sBE fills dst with IEEE-754 32-bit floating point numbers in
+// big-endian (network) byte order. If bin is at end of file before any data has
+// been read, io.EOF is returned; if it ends part-way through,
+// io.ErrUnexpectedEOF is returned and the contents of dst are unspecified.
+func ReadFloat32sBE(bin Reader, dst []float32) error {
+	if len(dst)*4 < bulkMinBytes {
+		for i := range dst {
+			v, err := ReadFloat32BE(bin)
+			if err != nil {
+				return elemErr(bin, i, err)
+			}
+			dst[i] = v
+		}
+		return nil
+	}
+	return bulkRead(bin, 4, len(dst), func(b []byte, first int) {
+		d := dst[first : first+len(b)/4]
+		for i := range d {
+			d[i] = math.Float32frombits(binary.BigEndian.Uint32(b[4*i:]))
+		}
+	})
+}
+
+// WriteFloat32sBE writes the IEEE-754 32-bit floating point numbers in src in
+// big-endian (network) byte order.
+func WriteFloat32sBE(bout Writer, src []float32) error {
+	if len(src)*4 < bulkMinBytes {
+		for _, v := range src {
+			if err := WriteFloat32BE(bout, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return bulkWrite(bout, 4, len(src), func(b []byte, first int) {
+		for i, v := range src[first : first+len(b)/4] {
+			binary.BigEndian.PutUint32(b[4*i:], math.Float32bits(v))
+		}
+	})
+}
+
+// ReadUint64sBE fills dst with unsigned uint64s in big-endian (network) byte
+// order. If bin is at end of file before any data has been read, io.EOF is
+// returned; if it ends part-way through, io.ErrUnexpectedEOF is returned and
+// the contents of dst are unspecified.
+func ReadUint64sBE(bin Reader, dst []uint64) error {
+	if len(dst)*8 < bulkMinBytes {
+		for i := range dst {
+			v, err := ReadUint64BE(bin)
+			if err != nil {
+				return elemErr(bin, i, err)
+			}
+			dst[i] = v
+		}
+		return nil
+	}
+	return bulkRead(bin, 8, len(dst), func(b []byte, first int) {
+		d := dst[first : first+len(b)/8]
+		for i := range d {
+			d[i] = binary.BigEndian.Uint64(b[8*i:])
+		}
+	})
+}
+
+// WriteUint64sBE writes the unsigned uint64s in src in big-endian (network)
+// byte order.
+func WriteUint64sBE(bout Writer, src []uint64) error {
+	if len(src)*8 < bulkMinBytes {
+		for _, v := range src {
+			if err := WriteUint64BE(bout, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return bulkWrite(bout, 8, len(src), func(b []byte, first int) {
+		for i, v := range src[first : first+len(b)/8] {
+			binary.BigEndian.PutUint64(b[8*i:], v)
+		}
+	})
+}
+
+// ReadInt64sBE fills dst with signed int64s in big-endian (network) byte order.
+// If bin is at end of file before any data has been read, io.EOF is returned;
+// if it ends part-way through, io.ErrUnexpectedEOF is returned and the contents
+// of dst are unspecified.
+func ReadInt64sBE(bin Reader, dst []int64) error {
+	if len(dst)*8 < bulkMinBytes {
+		for i := range dst {
+			v, err := ReadInt64BE(bin)
+			if err != nil {
+				return elemErr(bin, i, err)
+			}
+			dst[i] = v
+		}
+		return nil
+	}
+	return bulkRead(bin, 8, len(dst), func(b []byte, first int) {
+		d := dst[first : first+len(b)/8]
+		for i := range d {
+			d[i] = int64(binary.BigEndian.Uint64(b[8*i:]))
+		}
+	})
+}
+
+// WriteInt64sBE writes the signed int64s in src in big-endian (network) byte
+// order.
+func WriteInt64sBE(bout Writer, src []int64) error {
+	if len(src)*8 < bulkMinBytes {
+		for _, v := range src {
+			if err := WriteInt64BE(bout, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return bulkWrite(bout, 8, len(src), func(b []byte, first int) {
+		for i, v := range src[first : first+len(b)/8] {
+			binary.BigEndian.PutUint64(b[8*i:], uint64(v))
+		}
+	})
+}
+
+// ReadFloat64sBE fills dst with IEEE-754 64-bit floating point numbers in
+// big-endian (network) byte order. If bin is at end of file before any data has
+// been read, io.EOF is returned; if it ends part-way through,
+// io.ErrUnexpectedEOF is returned and the contents of dst are unspecified.
+func ReadFloat64sBE(bin Reader, dst []float64) error {
+	if len(dst)*8 < bulkMinBytes {
+		for i := range dst {
+			v, err := ReadFloat64BE(bin)
+			if err != nil {
+				return elemErr(bin, i, err)
+			}
+			dst[i] = v
+		}
+		return nil
+	}
+	return bulkRead(bin, 8, len(dst), func(b []byte, first int) {
+		d := dst[first : first+len(b)/8]
+		for i := range d {
+			d[i] = math.Float64frombits(binary.BigEndian.Uint64(b[8*i:]))
+		}
+	})
+}
+
+// WriteFloat64sBE writes the IEEE-754 64-bit floating point numbers in src in
+// big-endian (network) byte order.
+func WriteFloat64sBE(bout Writer, src []float64) error {
+	if len(src)*8 < bulkMinBytes {
+		for _, v := range src {
+			if err := WriteFloat64BE(bout, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return bulkWrite(bout, 8, len(src), func(b []byte, first int) {
+		for i, v := range src[first : first+len(b)/8] {
+			binary.BigEndian.PutUint64(b[8*i:], math.Float64bits(v))
+		}
+	})
+}
+
+// ReadUint16sLE fills dst with unsigned uint16s in little-endian byte order. If
+// bin is at end of file before any data has been read, io.EOF is returned; if
+// it ends part-way through, io.ErrUnexpectedEOF is returned and the contents of
+// dst are unspecified.
+func ReadUint16sLE(bin Reader, dst []uint16) error {
+	if len(dst)*2 < bulkMinBytes {
+		for i := range dst {
+			v, err := ReadUint16LE(bin)
+			if err != nil {
+				return elemErr(bin, i, err)
+			}
+			dst[i] = v
+		}
+		return nil
+	}
+	return bulkRead(bin, 2, len(dst), func(b []byte, first int) {
+		d := dst[first : first+len(b)/2]
+		for i := range d {
+			d[i] = binary.LittleEndian.Uint16(b[2*i:])
+		}
+	})
+}
+
+// WriteUint16sLE writes the unsigned uint16s in src in little-endian byte
+// order.
+func WriteUint16sLE(bout Writer, src []uint16) error {
+	if len(src)*2 < bulkMinBytes {
+		for _, v := range src {
+			if err := WriteUint16LE(bout, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return bulkWrite(bout, 2, len(src), func(b []byte, first int) {
+		for i, v := range src[first : first+len(b)/2] {
+			binary.LittleEndian.PutUint16(b[2*i:], v)
+		}
+	})
+}
+
+// ReadInt16sLE fills dst with signed int16s in little-endian byte order. If bin
+// is at end of file before any data has been read, io.EOF is returned; if it
+// ends part-way through, io.ErrUnexpectedEOF is returned and the contents of
+// dst are unspecified.
+func ReadInt16sLE(bin Reader, dst []int16) error {
+	if len(dst)*2 < bulkMinBytes {
+		for i := range dst {
+			v, err := ReadInt16LE(bin)
+			if err != nil {
+				return elemErr(bin, i, err)
+			}
+			dst[i] = v
+		}
+		return nil
+	}
+	return bulkRead(bin, 2, len(dst), func(b []byte, first int) {
+		d := dst[first : first+len(b)/2]
+		for i := range d {
+			d[i] = int16(binary.LittleEndian.Uint16(b[2*i:]))
+		}
+	})
+}
+
+// WriteInt16sLE writes the signed int16s in src in little-endian byte order.
+func WriteInt16sLE(bout Writer, src []int16) error {
+	if len(src)*2 < bulkMinBytes {
+		for _, v := range src {
+			if err := WriteInt16LE(bout, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return bulkWrite(bout, 2, len(src), func(b []byte, first int) {
+		for i, v := range src[first : first+len(b)/2] {
+			binary.LittleEndian.PutUint16(b[2*i:], uint16(v))
+		}
+	})
+}
+
+// ReadUint32sLE fills dst with unsigned uint32s in little-endian byte order. If
+// bin is at end of file before any data has been read, io.EOF is returned; if
+// it ends part-way through, io.ErrUnexpectedEOF is returned and the contents of
+// dst are unspecified.
+func ReadUint32sLE(bin Reader, dst []uint32) error {
+	if len(dst)*4 < bulkMinBytes {
+		for i := range dst {
+			v, err := ReadUint32LE(bin)
+			if err != nil {
+				return elemErr(bin, i, err)
+			}
+			dst[i] = v
+		}
+		return nil
+	}
+	return bulkRead(bin, 4, len(dst), func(b []byte, first int) {
+		d := dst[first : first+len(b)/4]
+		for i := range d {
+			d[i] = binary.LittleEndian.Uint32(b[4*i:])
+		}
+	})
+}
+
+// WriteUint32sLE writes the unsigned uint32s in src in little-endian byte
+// order.
+func WriteUint32sLE(bout Writer, src []uint32) error {
+	if len(src)*4 < bulkMinBytes {
+		for _, v := range src {
+			if err := WriteUint32LE(bout, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return bulkWrite(bout, 4, len(src), func(b []byte, first int) {
+		for i, v := range src[first : first+len(b)/4] {
+			binary.LittleEndian.PutUint32(b[4*i:], v)
+		}
+	})
+}
+
+// ReadInt32sLE fills dst with signed int32s in little-endian byte order. If bin
+// is at end of file before any data has been read, io.EOF is returned; if it
+// ends part-way through, io.ErrUnexpectedEOF is returned and the contents of
+// dst are unspecified.
+func ReadInt32sLE(bin Reader, dst []int32) error {
+	if len(dst)*4 < bulkMinBytes {
+		for i := range dst {
+			v, err := ReadInt32LE(bin)
+			if err != nil {
+				return elemErr(bin, i, err)
+			}
+			dst[i] = v
+		}
+		return nil
+	}
+	return bulkRead(bin, 4, len(dst), func(b []byte, first int) {
+		d := dst[first : first+len(b)/4]
+		for i := range d {
+			d[i] = int32(binary.LittleEndian.Uint32(b[4*i:]))
+		}
+	})
+}
+
+// WriteInt32sLE writes the signed int32s in src in little-endian byte order.
+func WriteInt32sLE(bout Writer, src []int32) error {
+	if len(src)*4 < bulkMinBytes {
+		for _, v := range src {
+			if err := WriteInt32LE(bout, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return bulkWrite(bout, 4, len(src), func(b []byte, first int) {
+		for i, v := range src[first : first+len(b)/4] {
+			binary.LittleEndian.PutUint32(b[4*i:], uint32(v))
+		}
+	})
+}
+
+// ReadFloat32sLE fills dst with IEEE-754 32-bit floating point numbers in
+// little-endian byte order. If bin is at end of file before any data has been
+// read, io.EOF is returned; if it ends part-way through, io.ErrUnexpectedEOF is
+// returned and the contents of dst are unspecified.
+func ReadFloat32sLE(bin Reader, dst []float32) error {
+	if len(dst)*4 < bulkMinBytes {
+		for i := range dst {
+			v, err := ReadFloat32LE(bin)
+			if err != nil {
+				return elemErr(bin, i, err)
+			}
+			dst[i] = v
+		}
+		return nil
+	}
+	return bulkRead(bin, 4, len(dst), func(b []byte, first int) {
+		d := dst[first : first+len(b)/4]
+		for i := range d {
+			d[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+		}
+	})
+}
+
+// WriteFloat32sLE writes the IEEE-754 32-bit floating point numbers in src in
+// little-endian byte order.
+func WriteFloat32sLE(bout Writer, src []float32) error {
+	if len(src)*4 < bulkMinBytes {
+		for _, v := range src {
+			if err := WriteFloat32LE(bout, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return bulkWrite(bout, 4, len(src), func(b []byte, first int) {
+		for i, v := range src[first : first+len(b)/4] {
+			binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(v))
+		}
+	})
+}
+
+// ReadUint64sLE fills dst with unsigned uint64s in little-endian byte order. If
+// bin is at end of file before any data has been read, io.EOF is returned; if
+// it ends part-way through, io.ErrUnexpectedEOF is returned and the contents of
+// dst are unspecified.
+func ReadUint64sLE(bin Reader, dst []uint64) error {
+	if len(dst)*8 < bulkMinBytes {
+		for i := range dst {
+			v, err := ReadUint64LE(bin)
+			if err != nil {
+				return elemErr(bin, i, err)
+			}
+			dst[i] = v
+		}
+		return nil
+	}
+	return bulkRead(bin, 8, len(dst), func(b []byte, first int) {
+		d := dst[first : first+len(b)/8]
+		for i := range d {
+			d[i] = binary.LittleEndian.Uint64(b[8*i:])
+		}
+	})
+}
+
+// WriteUint64sLE writes the unsigned uint64s in src in little-endian byte
+// order.
+func WriteUint64sLE(bout Writer, src []uint64) error {
+	if len(src)*8 < bulkMinBytes {
+		for _, v := range src {
+			if err := WriteUint64LE(bout, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return bulkWrite(bout, 8, len(src), func(b []byte, first int) {
+		for i, v := range src[first : first+len(b)/8] {
+			binary.LittleEndian.PutUint64(b[8*i:], v)
+		}
+	})
+}
+
+// ReadInt64sLE fills dst with signed int64s in little-endian byte order. If bin
+// is at end of file before any data has been read, io.EOF is returned; if it
+// ends part-way through, io.ErrUnexpectedEOF is returned and the contents of
+// dst are unspecified.
+func ReadInt64sLE(bin Reader, dst []int64) error {
+	if len(dst)*8 < bulkMinBytes {
+		for i := range dst {
+			v, err := ReadInt64LE(bin)
+			if err != nil {
+				return elemErr(bin, i, err)
+			}
+			dst[i] = v
+		}
+		return nil
+	}
+	return bulkRead(bin, 8, len(dst), func(b []byte, first int) {
+		d := dst[first : first+len(b)/8]
+		for i := range d {
+			d[i] = int64(binary.LittleEndian.Uint64(b[8*i:]))
+		}
+	})
+}
+
+// WriteInt64sLE writes the signed int64s in src in little-endian byte order.
+func WriteInt64sLE(bout Writer, src []int64) error {
+	if len(src)*8 < bulkMinBytes {
+		for _, v := range src {
+			if err := WriteInt64LE(bout, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return bulkWrite(bout, 8, len(src), func(b []byte, first int) {
+		for i, v := range src[first : first+len(b)/8] {
+			binary.LittleEndian.PutUint64(b[8*i:], uint64(v))
+		}
+	})
+}
+
+// ReadFloat64sLE fills dst with IEEE-754 64-bit floating point numbers in
+// little-endian byte order. If bin is at end of file before any data has been
+// read, io.EOF is returned; if it ends part-way through, io.ErrUnexpectedEOF is
+// returned and the contents of dst are unspecified.
+func ReadFloat64sLE(bin Reader, dst []float64) error {
+	if len(dst)*8 < bulkMinBytes {
+		for i := range dst {
+			v, err := ReadFloat64LE(bin)
+			if err != nil {
+				return elemErr(bin, i, err)
+			}
+			dst[i] = v
+		}
+		return nil
+	}
+	return bulkRead(bin, 8, len(dst), func(b []byte, first int) {
+		d := dst[first : first+len(b)/8]
+		for i := range d {
+			d[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[8*i:]))
+		}
+	})
+}
+
+// WriteFloat64sLE writes the IEEE-754 64-bit floating point numbers in src in
+// little-endian byte order.
+func WriteFloat64sLE(bout Writer, src []float64) error {
+	if len(src)*8 < bulkMinBytes {
+		for _, v := range src {
+			if err := WriteFloat64LE(bout, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return bulkWrite(bout, 8, len(src), func(b []byte, first int) {
+		for i, v := range src[first : first+len(b)/8] {
+			binary.LittleEndian.PutUint64(b[8*i:], math.Float64bits(v))
+		}
+	})
+}
@@ -0,0 +1,387 @@
+package byteio_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// bulkCase describes one pair of bulk functions. The read and write members
+// adapt the typed functions to interface{} so that a single test loop may
+// cover every type.
+type bulkCase struct {
+	name  string
+	width int
+	order binary.ByteOrder
+	gen   func(rng *rand.Rand, n int) interface{}
+	read  func(bin byteio.Reader, n int) (interface{}, error)
+	write func(bout byteio.Writer, v interface{}) error
+}
+
+var bulkCases = []bulkCase{
+	{"Uint16sBE", 2, binary.BigEndian,
+		func(rng *rand.Rand, n int) interface{} {
+			v := make([]uint16, n)
+			for i := range v {
+				v[i] = uint16(rng.Uint32())
+			}
+			return v
+		},
+		func(bin byteio.Reader, n int) (interface{}, error) {
+			v := make([]uint16, n)
+			return v, byteio.ReadUint16sBE(bin, v)
+		},
+		func(bout byteio.Writer, v interface{}) error {
+			return byteio.WriteUint16sBE(bout, v.([]uint16))
+		}},
+	{"Uint16sLE", 2, binary.LittleEndian,
+		func(rng *rand.Rand, n int) interface{} {
+			v := make([]uint16, n)
+			for i := range v {
+				v[i] = uint16(rng.Uint32())
+			}
+			return v
+		},
+		func(bin byteio.Reader, n int) (interface{}, error) {
+			v := make([]uint16, n)
+			return v, byteio.ReadUint16sLE(bin, v)
+		},
+		func(bout byteio.Writer, v interface{}) error {
+			return byteio.WriteUint16sLE(bout, v.([]uint16))
+		}},
+	{"Int16sBE", 2, binary.BigEndian,
+		func(rng *rand.Rand, n int) interface{} {
+			v := make([]int16, n)
+			for i := range v {
+				v[i] = int16(rng.Uint32())
+			}
+			return v
+		},
+		func(bin byteio.Reader, n int) (interface{}, error) {
+			v := make([]int16, n)
+			return v, byteio.ReadInt16sBE(bin, v)
+		},
+		func(bout byteio.Writer, v interface{}) error {
+			return byteio.WriteInt16sBE(bout, v.([]int16))
+		}},
+	{"Int16sLE", 2, binary.LittleEndian,
+		func(rng *rand.Rand, n int) interface{} {
+			v := make([]int16, n)
+			for i := range v {
+				v[i] = int16(rng.Uint32())
+			}
+			return v
+		},
+		func(bin byteio.Reader, n int) (interface{}, error) {
+			v := make([]int16, n)
+			return v, byteio.ReadInt16sLE(bin, v)
+		},
+		func(bout byteio.Writer, v interface{}) error {
+			return byteio.WriteInt16sLE(bout, v.([]int16))
+		}},
+	{"Uint32sBE", 4, binary.BigEndian,
+		func(rng *rand.Rand, n int) interface{} {
+			v := make([]uint32, n)
+			for i := range v {
+				v[i] = rng.Uint32()
+			}
+			return v
+		},
+		func(bin byteio.Reader, n int) (interface{}, error) {
+			v := make([]uint32, n)
+			return v, byteio.ReadUint32sBE(bin, v)
+		},
+		func(bout byteio.Writer, v interface{}) error {
+			return byteio.WriteUint32sBE(bout, v.([]uint32))
+		}},
+	{"Uint32sLE", 4, binary.LittleEndian,
+		func(rng *rand.Rand, n int) interface{} {
+			v := make([]uint32, n)
+			for i := range v {
+				v[i] = rng.Uint32()
+			}
+			return v
+		},
+		func(bin byteio.Reader, n int) (interface{}, error) {
+			v := make([]uint32, n)
+			return v, byteio.ReadUint32sLE(bin, v)
+		},
+		func(bout byteio.Writer, v interface{}) error {
+			return byteio.WriteUint32sLE(bout, v.([]uint32))
+		}},
+	{"Int32sBE", 4, binary.BigEndian,
+		func(rng *rand.Rand, n int) interface{} {
+			v := make([]int32, n)
+			for i := range v {
+				v[i] = int32(rng.Uint32())
+			}
+			return v
+		},
+		func(bin byteio.Reader, n int) (interface{}, error) {
+			v := make([]int32, n)
+			return v, byteio.ReadInt32sBE(bin, v)
+		},
+		func(bout byteio.Writer, v interface{}) error {
+			return byteio.WriteInt32sBE(bout, v.([]int32))
+		}},
+	{"Int32sLE", 4, binary.LittleEndian,
+		func(rng *rand.Rand, n int) interface{} {
+			v := make([]int32, n)
+			for i := range v {
+				v[i] = int32(rng.Uint32())
+			}
+			return v
+		},
+		func(bin byteio.Reader, n int) (interface{}, error) {
+			v := make([]int32, n)
+			return v, byteio.ReadInt32sLE(bin, v)
+		},
+		func(bout byteio.Writer, v interface{}) error {
+			return byteio.WriteInt32sLE(bout, v.([]int32))
+		}},
+	{"Float32sBE", 4, binary.BigEndian,
+		func(rng *rand.Rand, n int) interface{} {
+			v := make([]float32, n)
+			for i := range v {
+				v[i] = float32(rng.NormFloat64())
+			}
+			return v
+		},
+		func(bin byteio.Reader, n int) (interface{}, error) {
+			v := make([]float32, n)
+			return v, byteio.ReadFloat32sBE(bin, v)
+		},
+		func(bout byteio.Writer, v interface{}) error {
+			return byteio.WriteFloat32sBE(bout, v.([]float32))
+		}},
+	{"Float32sLE", 4, binary.LittleEndian,
+		func(rng *rand.Rand, n int) interface{} {
+			v := make([]float32, n)
+			for i := range v {
+				v[i] = float32(rng.NormFloat64())
+			}
+			return v
+		},
+		func(bin byteio.Reader, n int) (interface{}, error) {
+			v := make([]float32, n)
+			return v, byteio.ReadFloat32sLE(bin, v)
+		},
+		func(bout byteio.Writer, v interface{}) error {
+			return byteio.WriteFloat32sLE(bout, v.([]float32))
+		}},
+	{"Uint64sBE", 8, binary.BigEndian,
+		func(rng *rand.Rand, n int) interface{} {
+			v := make([]uint64, n)
+			for i := range v {
+				v[i] = rng.Uint64()
+			}
+			return v
+		},
+		func(bin byteio.Reader, n int) (interface{}, error) {
+			v := make([]uint64, n)
+			return v, byteio.ReadUint64sBE(bin, v)
+		},
+		func(bout byteio.Writer, v interface{}) error {
+			return byteio.WriteUint64sBE(bout, v.([]uint64))
+		}},
+	{"Uint64sLE", 8, binary.LittleEndian,
+		func(rng *rand.Rand, n int) interface{} {
+			v := make([]uint64, n)
+			for i := range v {
+				v[i] = rng.Uint64()
+			}
+			return v
+		},
+		func(bin byteio.Reader, n int) (interface{}, error) {
+			v := make([]uint64, n)
+			return v, byteio.ReadUint64sLE(bin, v)
+		},
+		func(bout byteio.Writer, v interface{}) error {
+			return byteio.WriteUint64sLE(bout, v.([]uint64))
+		}},
+	{"Int64sBE", 8, binary.BigEndian,
+		func(rng *rand.Rand, n int) interface{} {
+			v := make([]int64, n)
+			for i := range v {
+				v[i] = int64(rng.Uint64())
+			}
+			return v
+		},
+		func(bin byteio.Reader, n int) (interface{}, error) {
+			v := make([]int64, n)
+			return v, byteio.ReadInt64sBE(bin, v)
+		},
+		func(bout byteio.Writer, v interface{}) error {
+			return byteio.WriteInt64sBE(bout, v.([]int64))
+		}},
+	{"Int64sLE", 8, binary.LittleEndian,
+		func(rng *rand.Rand, n int) interface{} {
+			v := make([]int64, n)
+			for i := range v {
+				v[i] = int64(rng.Uint64())
+			}
+			return v
+		},
+		func(bin byteio.Reader, n int) (interface{}, error) {
+			v := make([]int64, n)
+			return v, byteio.ReadInt64sLE(bin, v)
+		},
+		func(bout byteio.Writer, v interface{}) error {
+			return byteio.WriteInt64sLE(bout, v.([]int64))
+		}},
+	{"Float64sBE", 8, binary.BigEndian,
+		func(rng *rand.Rand, n int) interface{} {
+			v := make([]float64, n)
+			for i := range v {
+				v[i] = rng.NormFloat64()
+			}
+			return v
+		},
+		func(bin byteio.Reader, n int) (interface{}, error) {
+			v := make([]float64, n)
+			return v, byteio.ReadFloat64sBE(bin, v)
+		},
+		func(bout byteio.Writer, v interface{}) error {
+			return byteio.WriteFloat64sBE(bout, v.([]float64))
+		}},
+	{"Float64sLE", 8, binary.LittleEndian,
+		func(rng *rand.Rand, n int) interface{} {
+			v := make([]float64, n)
+			for i := range v {
+				v[i] = rng.NormFloat64()
+			}
+			return v
+		},
+		func(bin byteio.Reader, n int) (interface{}, error) {
+			v := make([]float64, n)
+			return v, byteio.ReadFloat64sLE(bin, v)
+		},
+		func(bout byteio.Writer, v interface{}) error {
+			return byteio.WriteFloat64sLE(bout, v.([]float64))
+		}},
+}
+
+// bulkSizes covers empty slices, the per-value fallback, a single chunk and
+// multiple (partial) chunks.
+var bulkSizes = []int{0, 1, 3, 4, 16, 17, 511, 512, 513, 3000}
+
+// TestBulk compares the bulk functions against encoding/binary.
+func TestBulk(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, bc := range bulkCases {
+		for _, n := range bulkSizes {
+			vals := bc.gen(rng, n)
+			exp := bytes.NewBuffer(nil)
+			binary.Write(exp, bc.order, vals)
+
+			buf := bytes.NewBuffer(nil)
+			if err := bc.write(buf, vals); err != nil {
+				t.Fatalf("Write%s/%d: unexpected error %v", bc.name, n, err)
+			}
+			if !bytes.Equal(buf.Bytes(), exp.Bytes()) {
+				t.Errorf("Write%s/%d: output differs from encoding/binary",
+					bc.name, n)
+			}
+
+			act, err := bc.read(byteio.NewReader(&MockReaderFrom{buf: exp.Bytes()}), n)
+			if err != nil {
+				t.Fatalf("Read%s/%d: unexpected error %v", bc.name, n, err)
+			}
+			if !reflect.DeepEqual(act, vals) {
+				t.Errorf("Read%s/%d: values differ", bc.name, n)
+			}
+		}
+	}
+}
+
+// MockReaderFrom returns data from buf in deliberately small pieces, so that
+// the bulk readers must cope with short reads from the underlying reader.
+type MockReaderFrom struct {
+	buf []byte
+}
+
+func (r *MockReaderFrom) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		return 0, io.EOF
+	}
+	if len(p) > 7 {
+		p = p[:7]
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// TestBulkShort ensures that io.EOF is returned when no data is available and
+// io.ErrUnexpectedEOF when the data ends part-way through.
+func TestBulkShort(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, bc := range bulkCases {
+		for _, n := range bulkSizes[1:] {
+			if _, err := bc.read(bytes.NewReader(nil), n); err != io.EOF {
+				t.Errorf("Read%s/%d(empty): unexpected error %v",
+					bc.name, n, err)
+			}
+
+			buf := bytes.NewBuffer(nil)
+			bc.write(buf, bc.gen(rng, n))
+			full := buf.Bytes()
+			for _, short := range []int{1, bc.width, len(full) - 1} {
+				if short <= 0 || short >= len(full) {
+					continue
+				}
+				_, err := bc.read(bytes.NewReader(full[:short]), n)
+				if err != io.ErrUnexpectedEOF {
+					t.Errorf("Read%s/%d(%d bytes): unexpected error %v",
+						bc.name, n, short, err)
+				}
+			}
+		}
+	}
+}
+
+// TestBulkOffset ensures that a short read through a CountingReader reports
+// the offset of the value being read, whether or not the bulk path is used.
+func TestBulkOffset(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, bc := range bulkCases {
+		for _, n := range []int{3, 513} {
+			buf := bytes.NewBuffer(nil)
+			bc.write(buf, bc.gen(rng, n))
+			full := buf.Bytes()
+			for _, short := range []int{bc.width, len(full) - 1} {
+				cr := byteio.NewCountingReader(bytes.NewReader(full[:short]))
+				cr.WrapErrors = true
+				_, err := bc.read(cr, n)
+				exp := int64(short / bc.width * bc.width)
+				oerr, ok := err.(*byteio.OffsetError)
+				if !ok || oerr.Err != io.ErrUnexpectedEOF || oerr.Offset != exp {
+					t.Errorf("Read%s/%d(%d bytes): act %v ≠ exp offset 0x%x",
+						bc.name, n, short, err, exp)
+				}
+			}
+		}
+	}
+}
+
+// TestBulkWriteErr ensures that write errors are propagated.
+func TestBulkWriteErr(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, bc := range bulkCases {
+		for _, n := range bulkSizes[1:] {
+			vals := bc.gen(rng, n)
+			for _, when := range []int{0, n*bc.width - 1} {
+				err := bc.write(&AbortWriter{when: when}, vals)
+				if err != ErrAbortWriter {
+					t.Errorf("Write%s/%d(%d): unexpected error %v",
+						bc.name, n, when, err)
+				}
+			}
+		}
+	}
+}
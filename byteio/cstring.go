@@ -0,0 +1,50 @@
+package byteio
+
+import (
+	"io"
+	"strings"
+)
+
+// ReadUntil reads bytes up to and including the first occurrence of delim,
+// returning the bytes before it. If delim is not found within max bytes, a
+// *LengthError is returned. If the stream ends before delim is found, io.EOF
+// is returned if no bytes were read and io.ErrUnexpectedEOF otherwise.
+func ReadUntil(bin Reader, delim byte, max int) ([]byte, error) {
+	var buf []byte
+	for {
+		b, err := bin.ReadByte()
+		if err != nil {
+			return nil, readErr(bin, len(buf), err)
+		}
+		if b == delim {
+			if buf == nil {
+				buf = []byte{}
+			}
+			return buf, nil
+		}
+		if len(buf) >= max {
+			return nil, &LengthError{Length: uint64(len(buf)) + 1,
+				Max: uint64(maxInt(max, 0))}
+		}
+		buf = append(buf, b)
+	}
+}
+
+// ReadCString reads a NUL-terminated string, as for ReadUntil. The terminator
+// is consumed but not returned.
+func ReadCString(bin Reader, max int) (string, error) {
+	buf, err := ReadUntil(bin, 0, max)
+	return string(buf), err
+}
+
+// WriteCString writes s followed by a NUL terminator. An error is returned if
+// s itself contains NUL.
+func WriteCString(bout Writer, s string) error {
+	if strings.IndexByte(s, 0) >= 0 {
+		return errEmbeddedNUL
+	}
+	if _, err := io.WriteString(bout, s); err != nil {
+		return err
+	}
+	return bout.WriteByte(0)
+}
@@ -0,0 +1,69 @@
+package byteio_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// TestReadCString checks reading of consecutive strings, including the empty
+// string, and that the terminator is consumed.
+func TestReadCString(t *testing.T) {
+	bin := bytes.NewReader([]byte("hello\x00\x00world\x00"))
+	for _, exp := range []string{"hello", "", "world"} {
+		act, err := byteio.ReadCString(bin, 5)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if act != exp {
+			t.Errorf("act %q ≠ exp %q", act, exp)
+		}
+	}
+	if _, err := byteio.ReadCString(bin, 5); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+// TestReadUntil checks the delimiter, limit and truncation behaviour.
+func TestReadUntil(t *testing.T) {
+	act, err := byteio.ReadUntil(bytes.NewReader([]byte("a,b")), ',', 1)
+	if err != nil || !bytes.Equal(act, []byte("a")) {
+		t.Errorf("act %q/%v ≠ exp \"a\"", act, err)
+	}
+	act, err = byteio.ReadUntil(bytes.NewReader([]byte(",")), ',', 0)
+	if err != nil || act == nil || len(act) != 0 {
+		t.Errorf("act %#v/%v ≠ exp empty slice", act, err)
+	}
+
+	_, err = byteio.ReadUntil(bytes.NewReader([]byte("abc,")), ',', 2)
+	if le, ok := err.(*byteio.LengthError); !ok || le.Max != 2 {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	if _, err = byteio.ReadUntil(bytes.NewReader([]byte("abc")), ',', 10); err != io.ErrUnexpectedEOF {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err = byteio.ReadUntil(byteio.NewReader(&AbortReader{when: 1}), ',', 10); err != ErrAbortReader {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+// TestWriteCString checks the terminator is written and embedded NULs are
+// rejected.
+func TestWriteCString(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	if err := byteio.WriteCString(buf, "hi"); err != nil {
+		t.Fatal(err)
+	}
+	if exp := []byte("hi\x00"); !bytes.Equal(buf.Bytes(), exp) {
+		t.Errorf("act % X ≠ exp % X", buf.Bytes(), exp)
+	}
+	if err := byteio.WriteCString(buf, "a\x00b"); err == nil {
+		t.Error("expected error for embedded NUL")
+	}
+	if err := byteio.WriteCString(&AbortWriter{when: 2}, "hi"); err != ErrAbortWriter {
+		t.Errorf("unexpected error %v", err)
+	}
+}
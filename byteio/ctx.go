@@ -0,0 +1,269 @@
+package byteio
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// AbortError is returned by a ContextReader when its context is cancelled or
+// its deadline passes. If the abort interrupted a multi-byte value part way
+// through, Partial is set and errors.Is reports the error as matching
+// io.ErrUnexpectedEOF as well as the context's error.
+type AbortError struct {
+	Err     error // the context's error
+	Partial bool
+}
+
+func (e *AbortError) Error() string {
+	if e.Partial {
+		return "byteio: read of partial value aborted: " + e.Err.Error()
+	}
+	return "byteio: read aborted: " + e.Err.Error()
+}
+
+// Unwrap returns the context's error.
+func (e *AbortError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is io.ErrUnexpectedEOF and the value being read
+// was interrupted part way through.
+func (e *AbortError) Is(target error) bool {
+	return e.Partial && target == io.ErrUnexpectedEOF
+}
+
+// deadliner is implemented by network connections and os.File.
+type deadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// aLongTimeAgo is a deadline in the past, used to interrupt a blocked read.
+var aLongTimeAgo = time.Unix(1, 0)
+
+// ContextReader is a buffered Reader whose reads are bound to a
+// context.Context. Once the context is done, reads return an *AbortError,
+// including a read which is blocked at the time. Data already buffered is
+// still returned after the context is done.
+//
+// If the underlying reader has a SetReadDeadline method (as net.Conn does),
+// the context's deadline is used as the read deadline, and cancellation sets
+// a deadline in the past to interrupt a blocked read. The ContextReader
+// therefore controls the read deadline, so the caller should not set one
+// itself. Use context.WithTimeout or context.WithDeadline to bound the time
+// taken to read a value.
+//
+// Otherwise, the underlying reader is read by a single long-lived goroutine,
+// which exits once the context is done. A read which is blocked when the
+// context is done cannot be interrupted: it is abandoned, and its goroutine
+// leaks until the underlying read returns. Any data it then returns is
+// delivered by the next read after SetContext.
+type ContextReader struct {
+	br  *bufio.Reader
+	src ctxSource
+}
+
+// NewContextReader returns a ContextReader which reads from r, bound to ctx.
+// As with NewReader, r must not be used directly after calling this function.
+func NewContextReader(ctx context.Context, r io.Reader) *ContextReader {
+	cr := &ContextReader{src: ctxSource{r: r}}
+	cr.src.d, _ = r.(deadliner)
+	cr.src.setContext(ctx)
+	cr.br = bufio.NewReader(&cr.src)
+	return cr
+}
+
+// SetContext binds subsequent reads to ctx. It may be used to resume reading
+// after an abort, though any partially-read value has been lost.
+func (cr *ContextReader) SetContext(ctx context.Context) {
+	cr.src.setContext(ctx)
+}
+
+func (cr *ContextReader) Read(buf []byte) (int, error) {
+	return cr.br.Read(buf)
+}
+
+func (cr *ContextReader) ReadByte() (byte, error) {
+	return cr.br.ReadByte()
+}
+
+func (cr *ContextReader) ReadRune() (rune, int, error) {
+	return cr.br.ReadRune()
+}
+
+// ctxSource is the underlying reader of a ContextReader's bufio.Reader.
+type ctxSource struct {
+	r   io.Reader
+	d   deadliner // r, if it supports read deadlines
+	ctx context.Context
+	p   pump // used if r does not support read deadlines
+
+	// mu guards the read deadline and ctx against the watcher goroutine
+	mu       sync.Mutex
+	deadline time.Time     // read deadline last set on d
+	stop     chan struct{} // closed to stop the watcher of ctx
+}
+
+// setContext binds reads to ctx. If r supports read deadlines and ctx may be
+// cancelled, a goroutine is started to interrupt a blocked read once ctx is
+// done.
+func (s *ctxSource) setContext(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+	s.ctx = ctx
+	if s.d != nil && ctx.Done() != nil {
+		s.stop = make(chan struct{})
+		go s.watch(ctx, s.stop)
+	}
+}
+
+// watch sets a read deadline in the past once ctx is done, unless stop is
+// closed first.
+func (s *ctxSource) watch(ctx context.Context, stop chan struct{}) {
+	select {
+	case <-ctx.Done():
+		s.mu.Lock()
+		if s.ctx == ctx {
+			s.setDeadline(aLongTimeAgo)
+		}
+		s.mu.Unlock()
+	case <-stop:
+	}
+}
+
+// setDeadline sets the read deadline of d, if it has changed. s.mu must be
+// held.
+func (s *ctxSource) setDeadline(t time.Time) {
+	if !t.Equal(s.deadline) {
+		s.d.SetReadDeadline(t)
+		s.deadline = t
+	}
+}
+
+func (s *ctxSource) Read(buf []byte) (int, error) {
+	if s.d == nil {
+		return s.p.read(s.ctx, s.r, buf)
+	}
+
+	// holding the lock, either the watcher has already set a past
+	// deadline and the context reports an error, or it will do so after
+	// the deadline is set here
+	s.mu.Lock()
+	err := s.ctx.Err()
+	if err == nil {
+		t, _ := s.ctx.Deadline()
+		s.setDeadline(t)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return 0, &AbortError{Err: err}
+	}
+
+	n, err := s.r.Read(buf)
+	if err != nil && s.ctx.Done() != nil &&
+		errors.Is(err, os.ErrDeadlineExceeded) {
+		// the only deadlines are the context's, which may pass a
+		// moment before the context itself reports it
+		<-s.ctx.Done()
+		err = &AbortError{Err: s.ctx.Err()}
+	}
+	return n, err
+}
+
+// pump reads from a source without read deadlines on a long-lived goroutine,
+// so that a blocked read may be abandoned once the context is done.
+type pump struct {
+	req     chan int        // size of a read requested of the goroutine
+	res     chan pumpResult // result of the requested read
+	exited  chan struct{}   // closed when the goroutine exits
+	pending bool            // whether a requested read has not been received
+	rest    []byte          // data from the last result not yet returned
+	err     error           // error from the last result, after rest
+}
+
+// pumpResult is the result of a read by a pump's goroutine. The data is held
+// in the goroutine's buffer, which is not reused until the next request.
+type pumpResult struct {
+	data []byte
+	err  error
+}
+
+// read reads from r into buf, returning early with an *AbortError if ctx is
+// done first.
+func (p *pump) read(ctx context.Context, r io.Reader, buf []byte) (int,
+	error) {
+	if len(p.rest) == 0 && p.err == nil {
+		if err := ctx.Err(); err != nil {
+			return 0, &AbortError{Err: err}
+		}
+		if !p.pending && ctx.Done() == nil {
+			// cannot be cancelled, so there is no need for the
+			// goroutine
+			return r.Read(buf)
+		}
+		for !p.pending {
+			if p.exited == nil {
+				p.start(ctx, r)
+			}
+			select {
+			case p.req <- len(buf):
+				p.pending = true
+			case <-p.exited:
+				// the goroutine's context, set before a call
+				// to SetContext, is done
+				p.exited = nil
+			case <-ctx.Done():
+				return 0, &AbortError{Err: ctx.Err()}
+			}
+		}
+		select {
+		case res := <-p.res:
+			p.pending = false
+			p.rest, p.err = res.data, res.err
+		case <-ctx.Done():
+			return 0, &AbortError{Err: ctx.Err()}
+		}
+	}
+
+	n := copy(buf, p.rest)
+	p.rest = p.rest[n:]
+	if len(p.rest) > 0 {
+		return n, nil
+	}
+	err := p.err
+	p.err = nil
+	return n, err
+}
+
+// start starts a goroutine reading from r on request, until ctx is done.
+func (p *pump) start(ctx context.Context, r io.Reader) {
+	if p.req == nil {
+		p.req = make(chan int)
+		p.res = make(chan pumpResult, 1)
+	}
+	p.exited = make(chan struct{})
+	go func(req <-chan int, res chan<- pumpResult, exited chan struct{}) {
+		defer close(exited)
+		var buf []byte
+		for {
+			select {
+			case n := <-req:
+				if cap(buf) < n {
+					buf = make([]byte, n)
+				}
+				n, err := r.Read(buf[:n])
+				res <- pumpResult{data: buf[:n], err: err}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}(p.req, p.res, p.exited)
+}
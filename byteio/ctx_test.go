@@ -0,0 +1,183 @@
+package byteio_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// notifyConn is a net.Conn which signals on reading each time Read is
+// called, so that a test may cancel a context once a read is under way.
+type notifyConn struct {
+	net.Conn
+	reading chan struct{}
+}
+
+func (c *notifyConn) Read(buf []byte) (int, error) {
+	c.reading <- struct{}{}
+	return c.Conn.Read(buf)
+}
+
+// notifyReader is the equivalent of notifyConn for a reader without read
+// deadlines. The signal is dropped if one is already pending.
+type notifyReader struct {
+	io.Reader
+	reading chan struct{}
+}
+
+func (r *notifyReader) Read(buf []byte) (int, error) {
+	select {
+	case r.reading <- struct{}{}:
+	default:
+	}
+	return r.Reader.Read(buf)
+}
+
+// TestContextReaderPartial checks that a read blocked part way through a
+// value is interrupted when the context is cancelled, and that reading may
+// resume with a new context.
+func TestContextReaderPartial(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conn := &notifyConn{Conn: client, reading: make(chan struct{})}
+	cr := byteio.NewContextReader(ctx, conn)
+
+	// the first read receives part of the value, the second blocks
+	go server.Write([]byte{0x01, 0x02, 0x03})
+	go func() {
+		<-conn.reading
+		<-conn.reading
+		cancel()
+	}()
+	_, err := byteio.ReadUint64BE(cr)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	var ae *byteio.AbortError
+	if !errors.As(err, &ae) || !ae.Partial {
+		t.Errorf("expected partial *AbortError, got %#v", err)
+	}
+
+	// further reads abort immediately
+	if _, err = cr.ReadByte(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	cr.SetContext(context.Background())
+	go func() {
+		<-conn.reading
+		server.Write([]byte{0x12, 0x34})
+	}()
+	if v, err := byteio.ReadUint16BE(cr); err != nil || v != 0x1234 {
+		t.Errorf("after SetContext: act %X/%v ≠ exp 1234", v, err)
+	}
+}
+
+// TestContextReaderDeadline checks that a context whose deadline has passed
+// aborts a read before it starts, which is not reported as
+// io.ErrUnexpectedEOF.
+func TestContextReaderDeadline(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Unix(1, 0))
+	defer cancel()
+	cr := byteio.NewContextReader(ctx, client)
+	_, err := byteio.ReadUint32BE(cr)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("unexpected io.ErrUnexpectedEOF in %v", err)
+	}
+}
+
+// TestContextReaderCancel checks that cancelling the context interrupts a
+// read blocked before the start of a value, which is not reported as
+// io.ErrUnexpectedEOF.
+func TestContextReaderCancel(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	conn := &notifyConn{Conn: client, reading: make(chan struct{})}
+	cr := byteio.NewContextReader(ctx, conn)
+	go func() {
+		<-conn.reading
+		cancel()
+	}()
+
+	_, err := byteio.ReadUint32LE(cr)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("unexpected io.ErrUnexpectedEOF in %v", err)
+	}
+}
+
+// TestContextReaderNoDeadline checks that a reader without SetReadDeadline is
+// read normally, and that the context is checked before each read.
+func TestContextReaderNoDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cr := byteio.NewContextReader(ctx, bytes.NewReader([]byte("abc")))
+	if b, err := cr.ReadByte(); err != nil || b != 'a' {
+		t.Fatalf("ReadByte: act %q/%v", b, err)
+	}
+
+	// buffered data remains available after cancellation
+	cancel()
+	buf := make([]byte, 4)
+	if n, err := cr.Read(buf); err != nil || string(buf[:n]) != "bc" {
+		t.Errorf("Read: act %q/%v ≠ exp \"bc\"", buf[:n], err)
+	}
+	if _, _, err := cr.ReadRune(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+// TestContextReaderAbandon checks that a blocked read of a reader without
+// SetReadDeadline is abandoned when the context is cancelled, and that the
+// data it eventually returns is not lost.
+func TestContextReaderAbandon(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &notifyReader{Reader: pr, reading: make(chan struct{}, 1)}
+	cr := byteio.NewContextReader(ctx, r)
+	go func() {
+		<-r.reading
+		cancel()
+	}()
+	if _, err := cr.ReadByte(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	cr.SetContext(ctx)
+	go pw.Write([]byte{0x12, 0x34})
+	if v, err := byteio.ReadUint16BE(cr); err != nil || v != 0x1234 {
+		t.Errorf("after SetContext: act %X/%v ≠ exp 1234", v, err)
+	}
+	pw.Close()
+	if _, err := cr.ReadByte(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
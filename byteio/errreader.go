@@ -0,0 +1,737 @@
+package byteio
+
+// ErrReader wraps a Reader, recording the first error encountered. Once an
+// error has occurred, all further reads do nothing and return zero values.
+// This allows a fixed-layout structure to be decoded with straight-line code
+// and the error checked once at the end:
+//
+//	er := byteio.NewErrReader(bin)
+//	hdr.Magic = er.Uint32BE()
+//	hdr.Version = er.Uint16BE()
+//	hdr.Flags = er.Uint16BE()
+//	if err := er.Err(); err != nil {
+//		return err
+//	}
+//
+// The methods mirror the package-level Read* functions and have the same
+// error semantics, except that the ErrReader treats everything read through
+// it as one record: once any value has been read successfully, a later io.EOF
+// is recorded as io.ErrUnexpectedEOF. Use a new ErrReader for each record, so
+// that a clean end of stream before a record is still reported as io.EOF.
+type ErrReader struct {
+	bin     Reader
+	err     error
+	started bool // whether any read has succeeded
+}
+
+// NewErrReader returns an ErrReader which reads from bin.
+func NewErrReader(bin Reader) *ErrReader {
+	return &ErrReader{bin: bin}
+}
+
+// Err returns the first error encountered, if any.
+func (er *ErrReader) Err() error {
+	return er.err
+}
+
+// set records the outcome of a read.
+func (er *ErrReader) set(err error) {
+	if err != nil {
+		er.err = midRecord(er.started, err)
+	} else {
+		er.started = true
+	}
+}
+
+func (er *ErrReader) Read(buf []byte) (int, error) {
+	if er.err != nil {
+		return 0, er.err
+	}
+	n, err := er.bin.Read(buf)
+	if n > 0 {
+		er.started = true
+	}
+	if err != nil {
+		er.set(err)
+	}
+	return n, err
+}
+
+func (er *ErrReader) ReadByte() (byte, error) {
+	if er.err != nil {
+		return 0, er.err
+	}
+	b, err := er.bin.ReadByte()
+	er.set(err)
+	return b, err
+}
+
+func (er *ErrReader) ReadRune() (rune, int, error) {
+	if er.err != nil {
+		return 0, 0, er.err
+	}
+	r, size, err := er.bin.ReadRune()
+	er.set(err)
+	return r, size, err
+}
+
+// Uint8 reads a single byte.
+func (er *ErrReader) Uint8() uint8 {
+	b, _ := er.ReadByte()
+	return b
+}
+
+// Int8 reads a single byte as a signed integer.
+func (er *ErrReader) Int8() int8 {
+	b, _ := er.ReadByte()
+	return int8(b)
+}
+
+// Bytes reads exactly n bytes into a newly allocated slice.
+func (er *ErrReader) Bytes(n int) []byte {
+	if er.err != nil {
+		return nil
+	}
+	if n > 0 {
+		buf, err := ReadBytes(er.bin, n)
+		er.set(err)
+		return buf
+	}
+	return []byte{}
+}
+
+// Skip discards the next n bytes, as for the package-level Skip.
+func (er *ErrReader) Skip(n int64) {
+	if er.err == nil && n > 0 {
+		er.set(Skip(er.bin, n))
+	}
+}
+
+// Uint16BE is the sticky-error equivalent of ReadUint16BE.
+func (er *ErrReader) Uint16BE() uint16 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadUint16BE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Int16BE is the sticky-error equivalent of ReadInt16BE.
+func (er *ErrReader) Int16BE() int16 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadInt16BE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Uint24BE is the sticky-error equivalent of ReadUint24BE.
+func (er *ErrReader) Uint24BE() uint32 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadUint24BE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Int24BE is the sticky-error equivalent of ReadInt24BE.
+func (er *ErrReader) Int24BE() int32 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadInt24BE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Uint32BE is the sticky-error equivalent of ReadUint32BE.
+func (er *ErrReader) Uint32BE() uint32 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadUint32BE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Int32BE is the sticky-error equivalent of ReadInt32BE.
+func (er *ErrReader) Int32BE() int32 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadInt32BE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Float32BE is the sticky-error equivalent of ReadFloat32BE.
+func (er *ErrReader) Float32BE() float32 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadFloat32BE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Uint48BE is the sticky-error equivalent of ReadUint48BE.
+func (er *ErrReader) Uint48BE() uint64 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadUint48BE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Int48BE is the sticky-error equivalent of ReadInt48BE.
+func (er *ErrReader) Int48BE() int64 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadInt48BE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Uint64BE is the sticky-error equivalent of ReadUint64BE.
+func (er *ErrReader) Uint64BE() uint64 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadUint64BE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Int64BE is the sticky-error equivalent of ReadInt64BE.
+func (er *ErrReader) Int64BE() int64 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadInt64BE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Float64BE is the sticky-error equivalent of ReadFloat64BE.
+func (er *ErrReader) Float64BE() float64 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadFloat64BE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Float16BE is the sticky-error equivalent of ReadFloat16BE.
+func (er *ErrReader) Float16BE() float32 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadFloat16BE(er.bin)
+	er.set(err)
+	return v
+}
+
+// BFloat16BE is the sticky-error equivalent of ReadBFloat16BE.
+func (er *ErrReader) BFloat16BE() float32 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadBFloat16BE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Uint16LE is the sticky-error equivalent of ReadUint16LE.
+func (er *ErrReader) Uint16LE() uint16 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadUint16LE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Int16LE is the sticky-error equivalent of ReadInt16LE.
+func (er *ErrReader) Int16LE() int16 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadInt16LE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Uint24LE is the sticky-error equivalent of ReadUint24LE.
+func (er *ErrReader) Uint24LE() uint32 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadUint24LE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Int24LE is the sticky-error equivalent of ReadInt24LE.
+func (er *ErrReader) Int24LE() int32 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadInt24LE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Uint32LE is the sticky-error equivalent of ReadUint32LE.
+func (er *ErrReader) Uint32LE() uint32 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadUint32LE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Int32LE is the sticky-error equivalent of ReadInt32LE.
+func (er *ErrReader) Int32LE() int32 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadInt32LE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Float32LE is the sticky-error equivalent of ReadFloat32LE.
+func (er *ErrReader) Float32LE() float32 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadFloat32LE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Uint48LE is the sticky-error equivalent of ReadUint48LE.
+func (er *ErrReader) Uint48LE() uint64 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadUint48LE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Int48LE is the sticky-error equivalent of ReadInt48LE.
+func (er *ErrReader) Int48LE() int64 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadInt48LE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Uint64LE is the sticky-error equivalent of ReadUint64LE.
+func (er *ErrReader) Uint64LE() uint64 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadUint64LE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Int64LE is the sticky-error equivalent of ReadInt64LE.
+func (er *ErrReader) Int64LE() int64 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadInt64LE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Float64LE is the sticky-error equivalent of ReadFloat64LE.
+func (er *ErrReader) Float64LE() float64 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadFloat64LE(er.bin)
+	er.set(err)
+	return v
+}
+
+// Float16LE is the sticky-error equivalent of ReadFloat16LE.
+func (er *ErrReader) Float16LE() float32 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadFloat16LE(er.bin)
+	er.set(err)
+	return v
+}
+
+// BFloat16LE is the sticky-error equivalent of ReadBFloat16LE.
+func (er *ErrReader) BFloat16LE() float32 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadBFloat16LE(er.bin)
+	er.set(err)
+	return v
+}
+
+// UintN is the sticky-error equivalent of ReadUintN.
+func (er *ErrReader) UintN(width int, order Order) uint64 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadUintN(er.bin, width, order)
+	er.set(err)
+	return v
+}
+
+// IntN is the sticky-error equivalent of ReadIntN.
+func (er *ErrReader) IntN(width int, order Order) int64 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadIntN(er.bin, width, order)
+	er.set(err)
+	return v
+}
+
+// Uvarint is the sticky-error equivalent of ReadUvarint.
+func (er *ErrReader) Uvarint() uint64 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadUvarint(er.bin)
+	er.set(err)
+	return v
+}
+
+// Varint is the sticky-error equivalent of ReadVarint.
+func (er *ErrReader) Varint() int64 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadVarint(er.bin)
+	er.set(err)
+	return v
+}
+
+// SLEB128 is the sticky-error equivalent of ReadSLEB128.
+func (er *ErrReader) SLEB128() int64 {
+	if er.err != nil {
+		return 0
+	}
+	v, err := ReadSLEB128(er.bin)
+	er.set(err)
+	return v
+}
+
+// ErrWriter wraps a Writer, recording the first error encountered. Once an
+// error has occurred, all further writes do nothing. The methods mirror the
+// package-level Write* functions, and Err returns the first error.
+type ErrWriter struct {
+	bout Writer
+	err  error
+}
+
+// NewErrWriter returns an ErrWriter which writes to bout.
+func NewErrWriter(bout Writer) *ErrWriter {
+	return &ErrWriter{bout: bout}
+}
+
+// Err returns the first error encountered, if any.
+func (ew *ErrWriter) Err() error {
+	return ew.err
+}
+
+func (ew *ErrWriter) Write(buf []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	n, err := ew.bout.Write(buf)
+	ew.err = err
+	return n, err
+}
+
+func (ew *ErrWriter) WriteByte(b byte) error {
+	if ew.err != nil {
+		return ew.err
+	}
+	ew.err = ew.bout.WriteByte(b)
+	return ew.err
+}
+
+func (ew *ErrWriter) WriteRune(r rune) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	n, err := ew.bout.WriteRune(r)
+	ew.err = err
+	return n, err
+}
+
+// Flush flushes the underlying writer, if it requires flushing and no error
+// has occurred, and returns the first error encountered.
+func (ew *ErrWriter) Flush() error {
+	if ew.err == nil {
+		ew.err = FlushIfNecessary(ew.bout)
+	}
+	return ew.err
+}
+
+// PutUint8 writes a single byte.
+func (ew *ErrWriter) PutUint8(n uint8) {
+	ew.WriteByte(n)
+}
+
+// PutInt8 writes a signed integer as a single byte.
+func (ew *ErrWriter) PutInt8(i int8) {
+	ew.WriteByte(byte(i))
+}
+
+// PutBytes writes buf.
+func (ew *ErrWriter) PutBytes(buf []byte) {
+	ew.Write(buf)
+}
+
+// PutUint16BE is the sticky-error equivalent of WriteUint16BE.
+func (ew *ErrWriter) PutUint16BE(n uint16) {
+	if ew.err == nil {
+		ew.err = WriteUint16BE(ew.bout, n)
+	}
+}
+
+// PutInt16BE is the sticky-error equivalent of WriteInt16BE.
+func (ew *ErrWriter) PutInt16BE(i int16) {
+	if ew.err == nil {
+		ew.err = WriteInt16BE(ew.bout, i)
+	}
+}
+
+// PutUint24BE is the sticky-error equivalent of WriteUint24BE.
+func (ew *ErrWriter) PutUint24BE(n uint32) {
+	if ew.err == nil {
+		ew.err = WriteUint24BE(ew.bout, n)
+	}
+}
+
+// PutInt24BE is the sticky-error equivalent of WriteInt24BE.
+func (ew *ErrWriter) PutInt24BE(i int32) {
+	if ew.err == nil {
+		ew.err = WriteInt24BE(ew.bout, i)
+	}
+}
+
+// PutUint32BE is the sticky-error equivalent of WriteUint32BE.
+func (ew *ErrWriter) PutUint32BE(n uint32) {
+	if ew.err == nil {
+		ew.err = WriteUint32BE(ew.bout, n)
+	}
+}
+
+// PutInt32BE is the sticky-error equivalent of WriteInt32BE.
+func (ew *ErrWriter) PutInt32BE(i int32) {
+	if ew.err == nil {
+		ew.err = WriteInt32BE(ew.bout, i)
+	}
+}
+
+// PutFloat32BE is the sticky-error equivalent of WriteFloat32BE.
+func (ew *ErrWriter) PutFloat32BE(f float32) {
+	if ew.err == nil {
+		ew.err = WriteFloat32BE(ew.bout, f)
+	}
+}
+
+// PutUint48BE is the sticky-error equivalent of WriteUint48BE.
+func (ew *ErrWriter) PutUint48BE(n uint64) {
+	if ew.err == nil {
+		ew.err = WriteUint48BE(ew.bout, n)
+	}
+}
+
+// PutInt48BE is the sticky-error equivalent of WriteInt48BE.
+func (ew *ErrWriter) PutInt48BE(i int64) {
+	if ew.err == nil {
+		ew.err = WriteInt48BE(ew.bout, i)
+	}
+}
+
+// PutUint64BE is the sticky-error equivalent of WriteUint64BE.
+func (ew *ErrWriter) PutUint64BE(n uint64) {
+	if ew.err == nil {
+		ew.err = WriteUint64BE(ew.bout, n)
+	}
+}
+
+// PutInt64BE is the sticky-error equivalent of WriteInt64BE.
+func (ew *ErrWriter) PutInt64BE(i int64) {
+	if ew.err == nil {
+		ew.err = WriteInt64BE(ew.bout, i)
+	}
+}
+
+// PutFloat64BE is the sticky-error equivalent of WriteFloat64BE.
+func (ew *ErrWriter) PutFloat64BE(f float64) {
+	if ew.err == nil {
+		ew.err = WriteFloat64BE(ew.bout, f)
+	}
+}
+
+// PutFloat16BE is the sticky-error equivalent of WriteFloat16BE.
+func (ew *ErrWriter) PutFloat16BE(f float32) {
+	if ew.err == nil {
+		ew.err = WriteFloat16BE(ew.bout, f)
+	}
+}
+
+// PutBFloat16BE is the sticky-error equivalent of WriteBFloat16BE.
+func (ew *ErrWriter) PutBFloat16BE(f float32) {
+	if ew.err == nil {
+		ew.err = WriteBFloat16BE(ew.bout, f)
+	}
+}
+
+// PutUint16LE is the sticky-error equivalent of WriteUint16LE.
+func (ew *ErrWriter) PutUint16LE(n uint16) {
+	if ew.err == nil {
+		ew.err = WriteUint16LE(ew.bout, n)
+	}
+}
+
+// PutInt16LE is the sticky-error equivalent of WriteInt16LE.
+func (ew *ErrWriter) PutInt16LE(i int16) {
+	if ew.err == nil {
+		ew.err = WriteInt16LE(ew.bout, i)
+	}
+}
+
+// PutUint24LE is the sticky-error equivalent of WriteUint24LE.
+func (ew *ErrWriter) PutUint24LE(n uint32) {
+	if ew.err == nil {
+		ew.err = WriteUint24LE(ew.bout, n)
+	}
+}
+
+// PutInt24LE is the sticky-error equivalent of WriteInt24LE.
+func (ew *ErrWriter) PutInt24LE(i int32) {
+	if ew.err == nil {
+		ew.err = WriteInt24LE(ew.bout, i)
+	}
+}
+
+// PutUint32LE is the sticky-error equivalent of WriteUint32LE.
+func (ew *ErrWriter) PutUint32LE(n uint32) {
+	if ew.err == nil {
+		ew.err = WriteUint32LE(ew.bout, n)
+	}
+}
+
+// PutInt32LE is the sticky-error equivalent of WriteInt32LE.
+func (ew *ErrWriter) PutInt32LE(i int32) {
+	if ew.err == nil {
+		ew.err = WriteInt32LE(ew.bout, i)
+	}
+}
+
+// PutFloat32LE is the sticky-error equivalent of WriteFloat32LE.
+func (ew *ErrWriter) PutFloat32LE(f float32) {
+	if ew.err == nil {
+		ew.err = WriteFloat32LE(ew.bout, f)
+	}
+}
+
+// PutUint48LE is the sticky-error equivalent of WriteUint48LE.
+func (ew *ErrWriter) PutUint48LE(n uint64) {
+	if ew.err == nil {
+		ew.err = WriteUint48LE(ew.bout, n)
+	}
+}
+
+// PutInt48LE is the sticky-error equivalent of WriteInt48LE.
+func (ew *ErrWriter) PutInt48LE(i int64) {
+	if ew.err == nil {
+		ew.err = WriteInt48LE(ew.bout, i)
+	}
+}
+
+// PutUint64LE is the sticky-error equivalent of WriteUint64LE.
+func (ew *ErrWriter) PutUint64LE(n uint64) {
+	if ew.err == nil {
+		ew.err = WriteUint64LE(ew.bout, n)
+	}
+}
+
+// PutInt64LE is the sticky-error equivalent of WriteInt64LE.
+func (ew *ErrWriter) PutInt64LE(i int64) {
+	if ew.err == nil {
+		ew.err = WriteInt64LE(ew.bout, i)
+	}
+}
+
+// PutFloat64LE is the sticky-error equivalent of WriteFloat64LE.
+func (ew *ErrWriter) PutFloat64LE(f float64) {
+	if ew.err == nil {
+		ew.err = WriteFloat64LE(ew.bout, f)
+	}
+}
+
+// PutFloat16LE is the sticky-error equivalent of WriteFloat16LE.
+func (ew *ErrWriter) PutFloat16LE(f float32) {
+	if ew.err == nil {
+		ew.err = WriteFloat16LE(ew.bout, f)
+	}
+}
+
+// PutBFloat16LE is the sticky-error equivalent of WriteBFloat16LE.
+func (ew *ErrWriter) PutBFloat16LE(f float32) {
+	if ew.err == nil {
+		ew.err = WriteBFloat16LE(ew.bout, f)
+	}
+}
+
+// PutUintN is the sticky-error equivalent of WriteUintN.
+func (ew *ErrWriter) PutUintN(width int, order Order, n uint64) {
+	if ew.err == nil {
+		ew.err = WriteUintN(ew.bout, width, order, n)
+	}
+}
+
+// PutIntN is the sticky-error equivalent of WriteIntN.
+func (ew *ErrWriter) PutIntN(width int, order Order, i int64) {
+	if ew.err == nil {
+		ew.err = WriteIntN(ew.bout, width, order, i)
+	}
+}
+
+// PutUvarint is the sticky-error equivalent of WriteUvarint.
+func (ew *ErrWriter) PutUvarint(n uint64) {
+	if ew.err == nil {
+		ew.err = WriteUvarint(ew.bout, n)
+	}
+}
+
+// PutVarint is the sticky-error equivalent of WriteVarint.
+func (ew *ErrWriter) PutVarint(i int64) {
+	if ew.err == nil {
+		ew.err = WriteVarint(ew.bout, i)
+	}
+}
+
+// PutSLEB128 is the sticky-error equivalent of WriteSLEB128.
+func (ew *ErrWriter) PutSLEB128(i int64) {
+	if ew.err == nil {
+		ew.err = WriteSLEB128(ew.bout, i)
+	}
+}
@@ -0,0 +1,117 @@
+package byteio_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// TestErrReader checks straight-line decoding and that the first error is
+// sticky.
+func TestErrReader(t *testing.T) {
+	in := []byte{
+		0x01,
+		0x02, 0x03,
+		0x04, 0x05, 0x06, 0x07,
+		0xAC, 0x02,
+		0x08, 0x09, 0x0A,
+	}
+	er := byteio.NewErrReader(bytes.NewReader(in))
+
+	if act := er.Uint8(); act != 0x01 {
+		t.Errorf("Uint8: act %X ≠ exp 01", act)
+	}
+	if act := er.Uint16LE(); act != 0x0302 {
+		t.Errorf("Uint16LE: act %X ≠ exp 0302", act)
+	}
+	if act := er.Uint32BE(); act != 0x04050607 {
+		t.Errorf("Uint32BE: act %X ≠ exp 04050607", act)
+	}
+	if act := er.Uvarint(); act != 300 {
+		t.Errorf("Uvarint: act %d ≠ exp 300", act)
+	}
+	if err := er.Err(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// only 3 bytes remain, so this must fail and leave later calls inert
+	if act := er.Uint64BE(); act != 0 {
+		t.Errorf("Uint64BE: act %X ≠ exp 0", act)
+	}
+	if err := er.Err(); err != io.ErrUnexpectedEOF {
+		t.Errorf("unexpected error %v", err)
+	}
+	if act := er.Bytes(1); act != nil {
+		t.Errorf("Bytes after error: act % X", act)
+	}
+	if _, err := er.ReadByte(); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadByte after error: unexpected error %v", err)
+	}
+}
+
+// TestErrReaderBoundary checks that a record cut off at a value boundary is
+// reported as io.ErrUnexpectedEOF, while an empty stream is a clean io.EOF.
+func TestErrReaderBoundary(t *testing.T) {
+	er := byteio.NewErrReader(bytes.NewReader([]byte{1, 2, 3, 4}))
+	if act := er.Uint32BE(); act != 0x01020304 {
+		t.Errorf("Uint32BE: act %X ≠ exp 01020304", act)
+	}
+	er.Uint16BE()
+	if err := er.Err(); err != io.ErrUnexpectedEOF {
+		t.Errorf("truncated: act %v ≠ exp io.ErrUnexpectedEOF", err)
+	}
+
+	er = byteio.NewErrReader(bytes.NewReader(nil))
+	er.Bytes(0)
+	er.Uint16BE()
+	if err := er.Err(); err != io.EOF {
+		t.Errorf("empty: act %v ≠ exp io.EOF", err)
+	}
+}
+
+// TestErrReaderAbort checks that an underlying error is recorded.
+func TestErrReaderAbort(t *testing.T) {
+	er := byteio.NewErrReader(byteio.NewReader(&AbortReader{when: 3}))
+	er.Uint16BE()
+	er.Uint16BE()
+	er.Int64LE()
+	if err := er.Err(); err != ErrAbortReader {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+// TestErrWriter checks straight-line encoding and that the first error is
+// sticky.
+func TestErrWriter(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	ew := byteio.NewErrWriter(buf)
+	ew.PutUint8(0x01)
+	ew.PutUint16LE(0x0302)
+	ew.PutInt24BE(-1)
+	ew.PutVarint(-1)
+	ew.PutBytes([]byte("ab"))
+	if err := ew.Flush(); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	exp := []byte{0x01, 0x02, 0x03, 0xFF, 0xFF, 0xFF, 0x01, 'a', 'b'}
+	if !bytes.Equal(buf.Bytes(), exp) {
+		t.Errorf("act % X ≠ exp % X", buf.Bytes(), exp)
+	}
+
+	aw := &AbortWriter{when: 3}
+	ew = byteio.NewErrWriter(aw)
+	ew.PutUint16BE(0)
+	ew.PutUint16BE(0)
+	ew.PutUint16BE(0)
+	if err := ew.Err(); err != ErrAbortWriter {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	ew = byteio.NewErrWriter(bytes.NewBuffer(nil))
+	ew.PutUintN(9, byteio.BigEndian, 0)
+	if ew.Err() == nil {
+		t.Error("expected error for invalid width")
+	}
+}
@@ -0,0 +1,284 @@
+package byteio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+)
+
+var (
+	errFixedFormat = errors.New("byteio: invalid fixed-point format")
+	errFixedRange  = errors.New("byteio: value out of fixed-point range")
+	errBCDWidth    = errors.New("byteio: BCD width must be 1 to 10 bytes")
+	errBCDRange    = errors.New("byteio: value too large for BCD field")
+	errBCDOverflow = errors.New("byteio: BCD value overflows a 64-bit integer")
+)
+
+// FixedFormat describes a binary fixed-point number in Q format: an integer of
+// Bits bits (a multiple of 8, at most 64) whose lowest Frac bits are the
+// fractional part. For example, Q15 is a signed 16-bit value with 15
+// fractional bits, representing the range [-1, 1).
+type FixedFormat struct {
+	Bits, Frac int
+	Signed     bool
+}
+
+// Common fixed-point formats.
+var (
+	Q15    = FixedFormat{Bits: 16, Frac: 15, Signed: true}
+	Q31    = FixedFormat{Bits: 32, Frac: 31, Signed: true}
+	Q16_16 = FixedFormat{Bits: 32, Frac: 16, Signed: true}
+)
+
+func (f FixedFormat) String() string {
+	u := ""
+	if !f.Signed {
+		u = "U"
+	}
+	return fmt.Sprintf("%sQ%d.%d", u, f.Bits-f.Frac, f.Frac)
+}
+
+func (f FixedFormat) valid() bool {
+	return f.Bits%8 == 0 && f.Bits >= 8 && f.Bits <= 64 &&
+		f.Frac >= 0 && f.Frac <= f.Bits
+}
+
+// readFixedRaw reads the integer underlying a fixed-point value. Signed values
+// are sign-extended.
+func readFixedRaw(bin Reader, f FixedFormat, order Order) (int64, uint64,
+	error) {
+	if !f.valid() {
+		return 0, 0, errFixedFormat
+	}
+	raw, err := ReadUintN(bin, f.Bits/8, order)
+	if err != nil {
+		return 0, 0, err
+	}
+	shift := uint(64 - f.Bits)
+	return int64(raw<<shift) >> shift, raw, nil
+}
+
+// ReadFixed reads a fixed-point value of format f in the given byte order.
+// Formats wider than 53 bits may lose precision; use ReadFixedRat to obtain
+// the exact value.
+func ReadFixed(bin Reader, f FixedFormat, order Order) (float64, error) {
+	x, raw, err := readFixedRaw(bin, f, order)
+	if err != nil {
+		return 0, err
+	}
+	if f.Signed {
+		return math.Ldexp(float64(x), -f.Frac), nil
+	}
+	return math.Ldexp(float64(raw), -f.Frac), nil
+}
+
+// ReadFixedRat reads a fixed-point value of format f in the given byte order,
+// returning its exact value.
+func ReadFixedRat(bin Reader, f FixedFormat, order Order) (*big.Rat, error) {
+	x, raw, err := readFixedRaw(bin, f, order)
+	if err != nil {
+		return nil, err
+	}
+	num := new(big.Int).SetUint64(raw)
+	if f.Signed {
+		num.SetInt64(x)
+	}
+	den := new(big.Int).Lsh(big.NewInt(1), uint(f.Frac))
+	return new(big.Rat).SetFrac(num, den), nil
+}
+
+// WriteFixed writes v as a fixed-point value of format f in the given byte
+// order, rounding to the nearest representable value (ties to even). An error
+// is returned if v is out of range.
+func WriteFixed(bout Writer, f FixedFormat, order Order, v float64) error {
+	if !f.valid() {
+		return errFixedFormat
+	}
+	x := math.RoundToEven(math.Ldexp(v, f.Frac))
+	var raw uint64
+	if f.Signed {
+		lim := math.Ldexp(1, f.Bits-1)
+		if !(x >= -lim && x < lim) {
+			return errFixedRange
+		}
+		raw = uint64(int64(x))
+	} else {
+		if !(x >= 0 && x < math.Ldexp(1, f.Bits)) {
+			return errFixedRange
+		}
+		raw = uint64(x)
+	}
+	return WriteUintN(bout, f.Bits/8, order, raw)
+}
+
+// WriteFixedRat writes r as a fixed-point value of format f in the given byte
+// order, rounding to the nearest representable value (ties to even). An error
+// is returned if r is out of range.
+func WriteFixedRat(bout Writer, f FixedFormat, order Order, r *big.Rat) error {
+	if !f.valid() {
+		return errFixedFormat
+	}
+
+	num := new(big.Int).Lsh(r.Num(), uint(f.Frac))
+	x, m := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	m.Lsh(m.Abs(m), 1)
+	if c := m.Cmp(r.Denom()); c > 0 || (c == 0 && x.Bit(0) == 1) {
+		x.Add(x, big.NewInt(int64(num.Sign())))
+	}
+
+	lo, hi := new(big.Int), new(big.Int).Lsh(big.NewInt(1), uint(f.Bits))
+	if f.Signed {
+		hi.Rsh(hi, 1)
+		lo.Neg(hi)
+	}
+	if x.Cmp(lo) < 0 || x.Cmp(hi) >= 0 {
+		return errFixedRange
+	}
+
+	raw := x.Uint64()
+	if f.Signed {
+		raw = uint64(x.Int64())
+	}
+	return WriteUintN(bout, f.Bits/8, order, raw)
+}
+
+// NibbleError is returned when a BCD or packed decimal field contains an
+// invalid nibble. The whole field has been consumed.
+type NibbleError struct {
+	Index int  // index of the offending byte within the field
+	Byte  byte // value of the offending byte
+}
+
+func (e *NibbleError) Error() string {
+	return fmt.Sprintf("byteio: invalid decimal nibble in byte %d (0x%02X)",
+		e.Index, e.Byte)
+}
+
+// readDecimal reads an n-byte decimal field into buf.
+func readDecimal(bin Reader, buf *[10]byte, n int) ([]byte, error) {
+	if n < 1 || n > len(buf) {
+		return nil, errBCDWidth
+	}
+	b := buf[:n]
+	if m, err := io.ReadFull(bin, b); err != nil {
+		return nil, readErr(bin, m, err)
+	}
+	return b, nil
+}
+
+// ReadBCD reads an n-byte packed BCD value (two digits per byte, most
+// significant first). A *NibbleError is returned if any nibble is not a
+// decimal digit, and an error if the value does not fit in a uint64.
+func ReadBCD(bin Reader, n int) (uint64, error) {
+	var buf [10]byte
+	b, err := readDecimal(bin, &buf, n)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for i, c := range b {
+		hi, lo := c>>4, c&0xF
+		if hi > 9 || lo > 9 {
+			return 0, &NibbleError{Index: i, Byte: c}
+		}
+		d := uint64(hi*10 + lo)
+		if v > (math.MaxUint64-d)/100 {
+			return 0, errBCDOverflow
+		}
+		v = v*100 + d
+	}
+	return v, nil
+}
+
+// WriteBCD writes v as an n-byte packed BCD value. An error is returned if v
+// has more than 2n digits.
+func WriteBCD(bout Writer, n int, v uint64) error {
+	var buf [10]byte
+	if n < 1 || n > len(buf) {
+		return errBCDWidth
+	}
+	for i := n - 1; i >= 0; i-- {
+		buf[i] = byte(v%10) | byte(v/10%10)<<4
+		v /= 100
+	}
+	if v != 0 {
+		return errBCDRange
+	}
+	_, err := bout.Write(buf[:n])
+	return err
+}
+
+// ReadPackedDecimal reads an n-byte packed decimal (COBOL COMP-3) value: 2n-1
+// digits followed by a sign nibble. Sign nibbles 0xB and 0xD denote a negative
+// value, and 0xA, 0xC, 0xE and 0xF a positive one. A *NibbleError is returned
+// for any other sign or for a digit nibble greater than 9, and an error if the
+// value does not fit in an int64.
+func ReadPackedDecimal(bin Reader, n int) (int64, error) {
+	var buf [10]byte
+	b, err := readDecimal(bin, &buf, n)
+	if err != nil {
+		return 0, err
+	}
+
+	var v uint64
+	for i, c := range b {
+		digits := [2]byte{c >> 4, c & 0xF}
+		nd := 2
+		if i == n-1 {
+			nd = 1
+		}
+		for _, d := range digits[:nd] {
+			if d > 9 {
+				return 0, &NibbleError{Index: i, Byte: c}
+			}
+			if v > (math.MaxInt64+1-uint64(d))/10 {
+				return 0, errBCDOverflow
+			}
+			v = v*10 + uint64(d)
+		}
+	}
+
+	last := b[n-1]
+	switch last & 0xF {
+	case 0xA, 0xC, 0xE, 0xF:
+		if v > math.MaxInt64 {
+			return 0, errBCDOverflow
+		}
+		return int64(v), nil
+	case 0xB, 0xD:
+		return -int64(v), nil
+	default:
+		return 0, &NibbleError{Index: n - 1, Byte: last}
+	}
+}
+
+// WritePackedDecimal writes v as an n-byte packed decimal (COBOL COMP-3)
+// value, using sign nibble 0xC for positive values (and zero) and 0xD for
+// negative values. An error is returned if v has more than 2n-1 digits.
+func WritePackedDecimal(bout Writer, n int, v int64) error {
+	var buf [10]byte
+	if n < 1 || n > len(buf) {
+		return errBCDWidth
+	}
+
+	sign := byte(0xC)
+	u := uint64(v)
+	if v < 0 {
+		sign = 0xD
+		u = -u
+	}
+
+	buf[n-1] = byte(u%10)<<4 | sign
+	u /= 10
+	for i := n - 2; i >= 0; i-- {
+		buf[i] = byte(u%10) | byte(u/10%10)<<4
+		u /= 100
+	}
+	if u != 0 {
+		return errBCDRange
+	}
+	_, err := bout.Write(buf[:n])
+	return err
+}
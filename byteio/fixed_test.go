@@ -0,0 +1,217 @@
+package byteio_test
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// TestFixed checks encoding and decoding of fixed-point values against known
+// bit patterns.
+func TestFixed(t *testing.T) {
+	for _, c := range []struct {
+		f     byteio.FixedFormat
+		order byteio.Order
+		v     float64
+		exp   []byte
+	}{
+		{byteio.Q15, byteio.BigEndian, 0.5, []byte{0x40, 0x00}},
+		{byteio.Q15, byteio.LittleEndian, -1, []byte{0x00, 0x80}},
+		{byteio.Q15, byteio.BigEndian, -0.25, []byte{0xE0, 0x00}},
+		{byteio.Q31, byteio.BigEndian, 0.75, []byte{0x60, 0x00, 0x00, 0x00}},
+		{byteio.Q16_16, byteio.LittleEndian, -2.5, []byte{0x00, 0x80, 0xFD, 0xFF}},
+		{byteio.FixedFormat{Bits: 8, Frac: 4}, byteio.BigEndian, 15.5, []byte{0xF8}},
+		{byteio.FixedFormat{Bits: 24, Frac: 8, Signed: true}, byteio.BigEndian, -1, []byte{0xFF, 0xFF, 0x00}},
+	} {
+		buf := bytes.NewBuffer(nil)
+		if err := byteio.WriteFixed(buf, c.f, c.order, c.v); err != nil {
+			t.Errorf("%v/%v: unexpected error %v", c.f, c.v, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), c.exp) {
+			t.Errorf("%v/%v: act % X ≠ exp % X", c.f, c.v, buf.Bytes(), c.exp)
+		}
+
+		act, err := byteio.ReadFixed(bytes.NewReader(c.exp), c.f, c.order)
+		if err != nil || act != c.v {
+			t.Errorf("%v: act %v/%v ≠ exp %v", c.f, act, err, c.v)
+		}
+
+		r, err := byteio.ReadFixedRat(bytes.NewReader(c.exp), c.f, c.order)
+		if exp := new(big.Rat).SetFloat64(c.v); err != nil || r.Cmp(exp) != 0 {
+			t.Errorf("%v: act %v/%v ≠ exp %v", c.f, r, err, exp)
+		}
+		buf.Reset()
+		if err = byteio.WriteFixedRat(buf, c.f, c.order, r); err != nil || !bytes.Equal(buf.Bytes(), c.exp) {
+			t.Errorf("%v: WriteFixedRat act % X/%v ≠ exp % X", c.f, buf.Bytes(), err, c.exp)
+		}
+	}
+}
+
+// TestFixedRounding checks that both writers round to nearest, ties to even.
+func TestFixedRounding(t *testing.T) {
+	f := byteio.FixedFormat{Bits: 8, Frac: 1, Signed: true}
+	for _, c := range []struct {
+		num, den int64
+		exp      byte
+	}{
+		{1, 4, 0x00},  // 0.5 units → 0
+		{3, 4, 0x02},  // 1.5 units → 2
+		{-3, 4, 0xFE}, // -1.5 units → -2
+		{5, 8, 0x01},  // 1.25 units → 1
+		{-7, 8, 0xFE}, // -1.75 units → -2
+		{-1, 4, 0x00}, // -0.5 units → 0
+	} {
+		r := big.NewRat(c.num, c.den)
+		v, _ := r.Float64()
+		for name, write := range map[string]func(bout byteio.Writer) error{
+			"WriteFixed": func(bout byteio.Writer) error {
+				return byteio.WriteFixed(bout, f, byteio.BigEndian, v)
+			},
+			"WriteFixedRat": func(bout byteio.Writer) error {
+				return byteio.WriteFixedRat(bout, f, byteio.BigEndian, r)
+			},
+		} {
+			buf := bytes.NewBuffer(nil)
+			if err := write(buf); err != nil || buf.Len() != 1 || buf.Bytes()[0] != c.exp {
+				t.Errorf("%s(%v): act % X/%v ≠ exp %02X", name, r, buf.Bytes(), err, c.exp)
+			}
+		}
+	}
+}
+
+// TestFixedErr checks the range and format errors.
+func TestFixedErr(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	for _, v := range []float64{1, -1.0001, 2} {
+		if err := byteio.WriteFixed(buf, byteio.Q15, byteio.BigEndian, v); err == nil {
+			t.Errorf("WriteFixed(%v): expected error", v)
+		}
+	}
+	if err := byteio.WriteFixedRat(buf, byteio.Q15, byteio.BigEndian, big.NewRat(1, 1)); err == nil {
+		t.Error("WriteFixedRat(1): expected error")
+	}
+	uq := byteio.FixedFormat{Bits: 16, Frac: 16}
+	if err := byteio.WriteFixed(buf, uq, byteio.BigEndian, -0.1); err == nil {
+		t.Error("WriteFixed(-0.1): expected error for unsigned format")
+	}
+	bad := byteio.FixedFormat{Bits: 12, Frac: 4}
+	if err := byteio.WriteFixed(buf, bad, byteio.BigEndian, 0); err == nil {
+		t.Error("expected error for invalid format")
+	}
+	if _, err := byteio.ReadFixed(bytes.NewReader(nil), bad, byteio.BigEndian); err == nil {
+		t.Error("expected error for invalid format")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("unexpected output % X", buf.Bytes())
+	}
+
+	if _, err := byteio.ReadFixed(bytes.NewReader([]byte{1}), byteio.Q15, byteio.BigEndian); err != io.ErrUnexpectedEOF {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+// TestBCD checks round-tripping of BCD values and detection of invalid
+// nibbles.
+func TestBCD(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	if err := byteio.WriteBCD(buf, 3, 12345); err != nil {
+		t.Fatal(err)
+	}
+	if exp := []byte{0x01, 0x23, 0x45}; !bytes.Equal(buf.Bytes(), exp) {
+		t.Errorf("act % X ≠ exp % X", buf.Bytes(), exp)
+	}
+	if v, err := byteio.ReadBCD(buf, 3); err != nil || v != 12345 {
+		t.Errorf("act %d/%v ≠ exp 12345", v, err)
+	}
+
+	if err := byteio.WriteBCD(buf, 2, 12345); err == nil {
+		t.Error("expected error for value too large")
+	}
+
+	bin := bytes.NewReader([]byte{0x12, 0x3A, 0x99})
+	_, err := byteio.ReadBCD(bin, 2)
+	if ne, ok := err.(*byteio.NibbleError); !ok || ne.Index != 1 || ne.Byte != 0x3A {
+		t.Errorf("unexpected error %v", err)
+	}
+	if v, err := byteio.ReadBCD(bin, 1); err != nil || v != 99 {
+		t.Errorf("field not fully consumed: act %d/%v", v, err)
+	}
+
+	max := []byte{0x18, 0x44, 0x67, 0x44, 0x07, 0x37, 0x09, 0x55, 0x16, 0x15}
+	if v, err := byteio.ReadBCD(bytes.NewReader(max), 10); err != nil || v != 18446744073709551615 {
+		t.Errorf("act %d/%v ≠ exp MaxUint64", v, err)
+	}
+	max[9] = 0x16
+	if _, err := byteio.ReadBCD(bytes.NewReader(max), 10); err == nil {
+		t.Error("expected overflow error")
+	}
+
+	if _, err := byteio.ReadBCD(bytes.NewReader(nil), 2); err != io.EOF {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := byteio.ReadBCD(bytes.NewReader([]byte{1}), 2); err != io.ErrUnexpectedEOF {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// a partial read is reported at the offset of the start of the field
+	cr := byteio.NewCountingReader(bytes.NewReader([]byte{1, 2, 3}))
+	cr.WrapErrors = true
+	cr.ReadByte()
+	_, err = byteio.ReadBCD(cr, 3)
+	if oerr, ok := err.(*byteio.OffsetError); !ok || oerr.Offset != 1 ||
+		oerr.Err != io.ErrUnexpectedEOF {
+		t.Errorf("WrapErrors: unexpected error %v", err)
+	}
+}
+
+// TestPackedDecimal checks round-tripping of packed decimal values, sign
+// handling and detection of invalid nibbles.
+func TestPackedDecimal(t *testing.T) {
+	for _, c := range []struct {
+		v   int64
+		exp []byte
+	}{
+		{12345, []byte{0x12, 0x34, 0x5C}},
+		{-12345, []byte{0x12, 0x34, 0x5D}},
+		{0, []byte{0x00, 0x00, 0x0C}},
+		{-9223372036854775808, []byte{0x92, 0x23, 0x37, 0x20, 0x36, 0x85, 0x47, 0x75, 0x80, 0x8D}},
+	} {
+		n := len(c.exp)
+		buf := bytes.NewBuffer(nil)
+		if err := byteio.WritePackedDecimal(buf, n, c.v); err != nil || !bytes.Equal(buf.Bytes(), c.exp) {
+			t.Errorf("%d: act % X/%v ≠ exp % X", c.v, buf.Bytes(), err, c.exp)
+		}
+		if v, err := byteio.ReadPackedDecimal(bytes.NewReader(c.exp), n); err != nil || v != c.v {
+			t.Errorf("%d: act %d/%v", c.v, v, err)
+		}
+	}
+
+	if v, err := byteio.ReadPackedDecimal(bytes.NewReader([]byte{0x12, 0x3F}), 2); err != nil || v != 123 {
+		t.Errorf("unsigned: act %d/%v ≠ exp 123", v, err)
+	}
+	if v, err := byteio.ReadPackedDecimal(bytes.NewReader([]byte{0x12, 0x3B}), 2); err != nil || v != -123 {
+		t.Errorf("alternate negative: act %d/%v ≠ exp -123", v, err)
+	}
+	for _, in := range [][]byte{{0x12, 0x35}, {0x1A, 0x3C}, {0x12, 0xAC}} {
+		if _, err := byteio.ReadPackedDecimal(bytes.NewReader(in), 2); err == nil {
+			t.Errorf("% X: expected *NibbleError", in)
+		} else if _, ok := err.(*byteio.NibbleError); !ok {
+			t.Errorf("% X: unexpected error %v", in, err)
+		}
+	}
+	over := []byte{0x92, 0x23, 0x37, 0x20, 0x36, 0x85, 0x47, 0x75, 0x80, 0x8C}
+	if _, err := byteio.ReadPackedDecimal(bytes.NewReader(over), 10); err == nil {
+		t.Error("expected overflow error")
+	}
+
+	if err := byteio.WritePackedDecimal(bytes.NewBuffer(nil), 2, 1000); err == nil {
+		t.Error("expected error for value too large")
+	}
+	if err := byteio.WritePackedDecimal(bytes.NewBuffer(nil), 11, 0); err == nil {
+		t.Error("expected error for invalid width")
+	}
+}
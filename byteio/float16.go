@@ -0,0 +1,158 @@
+package byteio
+
+import "math"
+
+// Float16frombits returns the float32 value of the IEEE-754 binary16
+// (half-precision) number with bit pattern h. The conversion is exact;
+// subnormals become normal float32 values and NaN payloads are preserved.
+func Float16frombits(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1F
+	mant := uint32(h & 0x3FF)
+
+	switch exp {
+	case 0:
+		if mant == 0 {
+			return math.Float32frombits(sign)
+		}
+		// subnormal: normalise the mantissa
+		exp = 127 - 15 + 1
+		for mant&0x400 == 0 {
+			mant <<= 1
+			exp--
+		}
+		mant &= 0x3FF
+	case 0x1F:
+		// infinity or NaN
+		exp = 0xFF
+	default:
+		exp += 127 - 15
+	}
+	return math.Float32frombits(sign | exp<<23 | mant<<13)
+}
+
+// Float16bits returns the IEEE-754 binary16 (half-precision) bit pattern
+// nearest to f, rounding to nearest even. Values too large to represent
+// become infinities and values too small become (signed) zero. NaN payloads
+// are truncated to their most significant 10 bits, keeping the value a NaN.
+func Float16bits(f float32) uint16 {
+	b := math.Float32bits(f)
+	sign := uint16(b>>16) & 0x8000
+	exp := int(b>>23) & 0xFF
+	mant := b & 0x7FFFFF
+
+	if exp == 0xFF {
+		if mant == 0 {
+			return sign | 0x7C00
+		}
+		m := uint16(mant >> 13)
+		if m == 0 {
+			m = 0x200 // payload lost; make a quiet NaN
+		}
+		return sign | 0x7C00 | m
+	}
+
+	var (
+		h, rem, half uint32
+		e            = exp - 127 + 15
+	)
+	switch {
+	case e >= 0x1F:
+		return sign | 0x7C00
+	case e > 0:
+		h = uint32(e)<<10 | mant>>13
+		rem, half = mant&0x1FFF, 0x1000
+	default:
+		// result is subnormal (or zero); h counts units of 2^-24
+		s := uint(126 - exp)
+		if s >= 25 {
+			return sign
+		}
+		m := mant | 0x800000
+		h = m >> s
+		rem, half = m&(1<<s-1), 1<<(s-1)
+	}
+
+	// round to nearest even; a carry may correctly propagate into the
+	// exponent, including to infinity
+	if rem > half || (rem == half && h&1 != 0) {
+		h++
+	}
+	return sign | uint16(h)
+}
+
+// BFloat16frombits returns the float32 value of the bfloat16 number with bit
+// pattern h. The conversion is exact.
+func BFloat16frombits(h uint16) float32 {
+	return math.Float32frombits(uint32(h) << 16)
+}
+
+// BFloat16bits returns the bfloat16 bit pattern nearest to f, rounding to
+// nearest even. NaN payloads are truncated to their most significant 7 bits,
+// keeping the value a NaN.
+func BFloat16bits(f float32) uint16 {
+	b := math.Float32bits(f)
+	if b&0x7FFFFFFF > 0x7F800000 {
+		h := uint16(b >> 16)
+		if h&0x7F == 0 {
+			h |= 0x40 // payload lost; make a quiet NaN
+		}
+		return h
+	}
+	b += 0x7FFF + (b>>16)&1
+	return uint16(b >> 16)
+}
+
+// ReadFloat16BE reads an IEEE-754 binary16 (half-precision) floating point
+// number in big-endian (network) byte order.
+func ReadFloat16BE(bin Reader) (float32, error) {
+	n, err := readUint16BE(bin, "float16BE")
+	return Float16frombits(n), err
+}
+
+// ReadFloat16LE reads an IEEE-754 binary16 (half-precision) floating point
+// number in little-endian byte order.
+func ReadFloat16LE(bin Reader) (float32, error) {
+	n, err := readUint16LE(bin, "float16LE")
+	return Float16frombits(n), err
+}
+
+// ReadBFloat16BE reads a bfloat16 floating point number in big-endian
+// (network) byte order.
+func ReadBFloat16BE(bin Reader) (float32, error) {
+	n, err := readUint16BE(bin, "bfloat16BE")
+	return BFloat16frombits(n), err
+}
+
+// ReadBFloat16LE reads a bfloat16 floating point number in little-endian byte
+// order.
+func ReadBFloat16LE(bin Reader) (float32, error) {
+	n, err := readUint16LE(bin, "bfloat16LE")
+	return BFloat16frombits(n), err
+}
+
+// WriteFloat16BE writes f as an IEEE-754 binary16 (half-precision) floating
+// point number in big-endian (network) byte order, rounding as described for
+// Float16bits.
+func WriteFloat16BE(bout Writer, f float32) error {
+	return WriteUint16BE(bout, Float16bits(f))
+}
+
+// WriteFloat16LE writes f as an IEEE-754 binary16 (half-precision) floating
+// point number in little-endian byte order, rounding as described for
+// Float16bits.
+func WriteFloat16LE(bout Writer, f float32) error {
+	return WriteUint16LE(bout, Float16bits(f))
+}
+
+// WriteBFloat16BE writes f as a bfloat16 floating point number in big-endian
+// (network) byte order, rounding as described for BFloat16bits.
+func WriteBFloat16BE(bout Writer, f float32) error {
+	return WriteUint16BE(bout, BFloat16bits(f))
+}
+
+// WriteBFloat16LE writes f as a bfloat16 floating point number in
+// little-endian byte order, rounding as described for BFloat16bits.
+func WriteBFloat16LE(bout Writer, f float32) error {
+	return WriteUint16LE(bout, BFloat16bits(f))
+}
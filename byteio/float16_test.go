@@ -0,0 +1,188 @@
+package byteio_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// float16Ref computes the value of a binary16 bit pattern from first
+// principles.
+func float16Ref(h uint16) float64 {
+	exp := int(h>>10) & 0x1F
+	mant := float64(h & 0x3FF)
+	var v float64
+	switch exp {
+	case 0:
+		v = math.Ldexp(mant, -24)
+	case 0x1F:
+		if mant != 0 {
+			return math.NaN()
+		}
+		v = math.Inf(1)
+	default:
+		v = math.Ldexp(1024+mant, exp-25)
+	}
+	if h&0x8000 != 0 {
+		v = -v
+	}
+	return v
+}
+
+// TestFloat16Exhaustive checks every binary16 bit pattern against a reference
+// conversion, and checks that each one survives a round trip.
+func TestFloat16Exhaustive(t *testing.T) {
+	for i := 0; i < 1<<16; i++ {
+		h := uint16(i)
+		act := byteio.Float16frombits(h)
+		exp := float16Ref(h)
+
+		if math.IsNaN(exp) {
+			bits := math.Float32bits(act)
+			if !math.IsNaN(float64(act)) ||
+				uint16(bits>>13)&0x3FF != h&0x3FF ||
+				uint16(bits>>16)&0x8000 != h&0x8000 {
+				t.Errorf("%04X: NaN not preserved (%08X)", h, bits)
+			}
+		} else if float64(act) != exp || math.Signbit(float64(act)) != math.Signbit(exp) {
+			t.Errorf("%04X: act %g ≠ exp %g", h, act, exp)
+		}
+
+		if rt := byteio.Float16bits(act); rt != h {
+			t.Errorf("%04X: round trip gave %04X", h, rt)
+		}
+	}
+}
+
+// TestFloat16Rounding checks round-to-nearest-even at, and either side of,
+// the midpoint between every pair of adjacent finite binary16 values.
+func TestFloat16Rounding(t *testing.T) {
+	for h := uint16(0); h < 0x7C00; h++ {
+		lo := float64(byteio.Float16frombits(h))
+		hi := float64(byteio.Float16frombits(h + 1))
+		if h == 0x7BFF {
+			hi = 65536 // would-be next value; the midpoint is 65520
+		}
+		mid := float32((lo + hi) / 2)
+
+		even := h
+		if h&1 != 0 {
+			even = h + 1
+		}
+		if act := byteio.Float16bits(mid); act != even {
+			t.Errorf("%04X: midpoint %g: act %04X ≠ exp %04X", h, mid, act, even)
+		}
+		if act := byteio.Float16bits(-mid); act != even|0x8000 {
+			t.Errorf("%04X: midpoint %g: act %04X ≠ exp %04X", h, -mid, act, even|0x8000)
+		}
+		below := math.Nextafter32(mid, 0)
+		if act := byteio.Float16bits(below); act != h {
+			t.Errorf("%04X: below midpoint %g: act %04X", h, below, act)
+		}
+		above := math.Nextafter32(mid, float32(math.Inf(1)))
+		if act := byteio.Float16bits(above); act != h+1 {
+			t.Errorf("%04X: above midpoint %g: act %04X", h, above, act)
+		}
+	}
+
+	for _, c := range []struct {
+		f   float32
+		exp uint16
+	}{
+		{1e-10, 0}, {-1e-10, 0x8000}, {math.SmallestNonzeroFloat32, 0},
+		{1e10, 0x7C00}, {-1e10, 0xFC00}, {math.MaxFloat32, 0x7C00},
+		{float32(math.Inf(-1)), 0xFC00},
+	} {
+		if act := byteio.Float16bits(c.f); act != c.exp {
+			t.Errorf("%g: act %04X ≠ exp %04X", c.f, act, c.exp)
+		}
+	}
+
+	// a NaN whose payload lies only in the low bits must stay a NaN
+	nan := math.Float32frombits(0x7F800001)
+	if act := byteio.Float16bits(nan); act&0x7C00 != 0x7C00 || act&0x3FF == 0 {
+		t.Errorf("NaN became %04X", act)
+	}
+}
+
+// TestBFloat16Exhaustive checks every bfloat16 bit pattern, including
+// rounding at the midpoint to the following value.
+func TestBFloat16Exhaustive(t *testing.T) {
+	for i := 0; i < 1<<16; i++ {
+		h := uint16(i)
+		act := byteio.BFloat16frombits(h)
+		if bits := math.Float32bits(act); bits != uint32(h)<<16 {
+			t.Errorf("%04X: act %08X", h, bits)
+		}
+		if rt := byteio.BFloat16bits(act); rt != h {
+			t.Errorf("%04X: round trip gave %04X", h, rt)
+		}
+
+		// exponent field all-ones is Inf/NaN; no midpoint to test
+		if h&0x7F80 == 0x7F80 || h&0x7FFF == 0x7F7F {
+			continue
+		}
+		mid := math.Float32frombits(uint32(h)<<16 | 0x8000)
+		even := h
+		if h&1 != 0 {
+			even = h + 1
+		}
+		if act := byteio.BFloat16bits(mid); act != even {
+			t.Errorf("%04X: midpoint: act %04X ≠ exp %04X", h, act, even)
+		}
+		below := math.Float32frombits(uint32(h)<<16 | 0x7FFF)
+		if act := byteio.BFloat16bits(below); act != h {
+			t.Errorf("%04X: below midpoint: act %04X", h, act)
+		}
+	}
+
+	if act := byteio.BFloat16bits(math.MaxFloat32); act != 0x7F80 {
+		t.Errorf("MaxFloat32: act %04X ≠ exp 7F80", act)
+	}
+	nan := math.Float32frombits(0xFF800001)
+	if act := byteio.BFloat16bits(nan); act != 0xFFC0 {
+		t.Errorf("NaN: act %04X ≠ exp FFC0", act)
+	}
+}
+
+// TestFloat16ReadWrite checks the stream functions in both byte orders.
+func TestFloat16ReadWrite(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	for i := 0; i < 1<<16; i++ {
+		f := byteio.Float16frombits(uint16(i))
+		byteio.WriteFloat16BE(buf, f)
+		byteio.WriteFloat16LE(buf, f)
+		b := byteio.BFloat16frombits(uint16(i))
+		byteio.WriteBFloat16BE(buf, b)
+		byteio.WriteBFloat16LE(buf, b)
+	}
+
+	for i := 0; i < 1<<16; i++ {
+		h := uint16(i)
+		if n, _ := byteio.ReadUint16BE(bytes.NewReader(buf.Bytes()[:2])); n != h {
+			t.Fatalf("%04X: WriteFloat16BE wrote %04X", h, n)
+		}
+		if n, _ := byteio.ReadUint16LE(bytes.NewReader(buf.Bytes()[2:4])); n != h {
+			t.Fatalf("%04X: WriteFloat16LE wrote %04X", h, n)
+		}
+
+		f, err := byteio.ReadFloat16BE(buf)
+		if err != nil || byteio.Float16bits(f) != h {
+			t.Fatalf("%04X: ReadFloat16BE: %g, %v", h, f, err)
+		}
+		f, err = byteio.ReadFloat16LE(buf)
+		if err != nil || byteio.Float16bits(f) != h {
+			t.Fatalf("%04X: ReadFloat16LE: %g, %v", h, f, err)
+		}
+		f, err = byteio.ReadBFloat16BE(buf)
+		if err != nil || byteio.BFloat16bits(f) != h {
+			t.Fatalf("%04X: ReadBFloat16BE: %g, %v", h, f, err)
+		}
+		f, err = byteio.ReadBFloat16LE(buf)
+		if err != nil || byteio.BFloat16bits(f) != h {
+			t.Fatalf("%04X: ReadBFloat16LE: %g, %v", h, f, err)
+		}
+	}
+}
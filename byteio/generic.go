@@ -0,0 +1,75 @@
+package byteio
+
+import "unsafe"
+
+// Integer is the set of fixed-width integer types accepted by Read and Write.
+// The platform-dependent int, uint and uintptr are deliberately excluded.
+type Integer interface {
+	~int8 | ~int16 | ~int32 | ~int64 | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// Float is the set of floating point types accepted by Read and Write.
+type Float interface {
+	~float32 | ~float64
+}
+
+// isFloat reports whether T is a floating point type.
+func isFloat[T Integer | Float]() bool {
+	var half T = 1
+	half /= 2
+	return half != 0
+}
+
+// Read reads a value of type T in the given byte order, dispatching on the
+// size of T to the width-specific functions. Named types whose underlying
+// type is a fixed-width integer or float are accepted.
+func Read[T Integer | Float](bin Reader, order Order) (T, error) {
+	var v T
+	if isFloat[T]() {
+		if unsafe.Sizeof(v) == 4 {
+			f, err := order.ReadFloat32(bin)
+			return T(f), err
+		}
+		f, err := order.ReadFloat64(bin)
+		return T(f), err
+	}
+
+	// a conversion between integers of the same width preserves the bits,
+	// so the unsigned readers serve for signed types too
+	switch unsafe.Sizeof(v) {
+	case 1:
+		b, err := bin.ReadByte()
+		return T(b), err
+	case 2:
+		n, err := order.ReadUint16(bin)
+		return T(n), err
+	case 4:
+		n, err := order.ReadUint32(bin)
+		return T(n), err
+	default:
+		n, err := order.ReadUint64(bin)
+		return T(n), err
+	}
+}
+
+// Write writes v in the given byte order, dispatching on the size of T to the
+// width-specific functions.
+func Write[T Integer | Float](bout Writer, order Order, v T) error {
+	if isFloat[T]() {
+		if unsafe.Sizeof(v) == 4 {
+			return order.WriteFloat32(bout, float32(v))
+		}
+		return order.WriteFloat64(bout, float64(v))
+	}
+
+	switch unsafe.Sizeof(v) {
+	case 1:
+		return bout.WriteByte(byte(v))
+	case 2:
+		return order.WriteUint16(bout, uint16(v))
+	case 4:
+		return order.WriteUint32(bout, uint32(v))
+	default:
+		return order.WriteUint64(bout, uint64(v))
+	}
+}
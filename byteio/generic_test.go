@@ -0,0 +1,77 @@
+package byteio_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+type (
+	genericEnum  uint16
+	genericDelta int32
+	genericGain  float32
+)
+
+// roundTrip writes v with Write, checks the bytes against exp, and reads them
+// back with Read.
+func roundTrip[T byteio.Integer | byteio.Float](t *testing.T,
+	order byteio.Order, v T, exp []byte) {
+	t.Helper()
+	buf := bytes.NewBuffer(nil)
+	if err := byteio.Write(buf, order, v); err != nil {
+		t.Fatalf("%T(%v): unexpected error %v", v, v, err)
+	}
+	if !bytes.Equal(buf.Bytes(), exp) {
+		t.Errorf("%T(%v): act % X ≠ exp % X", v, v, buf.Bytes(), exp)
+	}
+	act, err := byteio.Read[T](buf, order)
+	if err != nil || act != v {
+		t.Errorf("%T(%v): act %v/%v", v, v, act, err)
+	}
+}
+
+// TestGeneric checks every supported underlying type, including named types,
+// against the byte patterns produced by the width-specific writers.
+func TestGeneric(t *testing.T) {
+	be, le := byteio.BigEndian, byteio.LittleEndian
+	roundTrip(t, be, uint8(0xA5), []byte{0xA5})
+	roundTrip(t, be, int8(-2), []byte{0xFE})
+	roundTrip(t, be, uint16(0x0102), []byte{0x01, 0x02})
+	roundTrip(t, le, int16(-0x0102), []byte{0xFE, 0xFE})
+	roundTrip(t, be, uint32(0x01020304), []byte{0x01, 0x02, 0x03, 0x04})
+	roundTrip(t, le, int32(-2), []byte{0xFE, 0xFF, 0xFF, 0xFF})
+	roundTrip(t, be, uint64(0x0102030405060708), []byte{1, 2, 3, 4, 5, 6, 7, 8})
+	roundTrip(t, le, int64(-2), []byte{0xFE, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF})
+	roundTrip(t, be, float32(1.5), []byte{0x3F, 0xC0, 0x00, 0x00})
+	roundTrip(t, le, float64(-2), []byte{0, 0, 0, 0, 0, 0, 0x00, 0xC0})
+	roundTrip(t, le, genericEnum(7), []byte{0x07, 0x00})
+	roundTrip(t, be, genericDelta(-1), []byte{0xFF, 0xFF, 0xFF, 0xFF})
+	roundTrip(t, be, genericGain(0.25), []byte{0x3E, 0x80, 0x00, 0x00})
+}
+
+// TestGenericErr checks that errors from the width-specific readers and
+// writers are propagated.
+func TestGenericErr(t *testing.T) {
+	if _, err := byteio.Read[uint32](bytes.NewReader([]byte{1, 2}), byteio.BigEndian); err == nil {
+		t.Error("expected error for short read")
+	}
+	if err := byteio.Write(&AbortWriter{when: 7}, byteio.LittleEndian, 1.0); err != ErrAbortWriter {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+// TestGenericAllocs ensures that Read and Write do not allocate.
+func TestGenericAllocs(t *testing.T) {
+	buf := bytes.NewBuffer(make([]byte, 0, 64))
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		byteio.Write(buf, byteio.BigEndian, uint64(1))
+		byteio.Write(buf, byteio.LittleEndian, float32(2))
+		byteio.Read[uint64](buf, byteio.BigEndian)
+		byteio.Read[float32](buf, byteio.LittleEndian)
+	})
+	if allocs != 0 {
+		t.Errorf("act %v allocations ≠ exp 0", allocs)
+	}
+}
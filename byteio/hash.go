@@ -0,0 +1,129 @@
+package byteio
+
+import (
+	"hash"
+	"unicode/utf8"
+)
+
+// HashingReader wraps a Reader, feeding every byte consumed through it into a
+// hash.Hash. Hashing may be suspended with End and resumed with Begin, so that
+// a checksum covers only part of a record; call Reset on the hash to start a
+// fresh checksum.
+type HashingReader struct {
+	bin       Reader
+	h         hash.Hash
+	suspended bool
+	rb        runeBuf
+	scratch   [utf8.UTFMax]byte // avoids allocating when hashing
+}
+
+// NewHashingReader returns a HashingReader which reads from bin and feeds h.
+// Hashing is initially active.
+func NewHashingReader(bin Reader, h hash.Hash) *HashingReader {
+	return &HashingReader{bin: bin, h: h}
+}
+
+// Begin resumes hashing of consumed bytes.
+func (hr *HashingReader) Begin() {
+	hr.suspended = false
+}
+
+// End suspends hashing; bytes consumed until the next call to Begin are not
+// fed to the hash.
+func (hr *HashingReader) End() {
+	hr.suspended = true
+}
+
+// Hash returns the hash which consumed bytes are fed to.
+func (hr *HashingReader) Hash() hash.Hash {
+	return hr.h
+}
+
+func (hr *HashingReader) Read(buf []byte) (int, error) {
+	n, err := hr.rb.read(hr.bin, buf)
+	if !hr.suspended {
+		hr.h.Write(buf[:n])
+	}
+	return n, err
+}
+
+func (hr *HashingReader) ReadByte() (byte, error) {
+	b, err := hr.rb.readByte(hr.bin)
+	if err == nil && !hr.suspended {
+		hr.scratch[0] = b
+		hr.h.Write(hr.scratch[:1])
+	}
+	return b, err
+}
+
+func (hr *HashingReader) ReadRune() (rune, int, error) {
+	r, size, raw, err := hr.rb.readRune(hr.bin)
+	if !hr.suspended {
+		copy(hr.scratch[:], raw[:size])
+		hr.h.Write(hr.scratch[:size])
+	}
+	return r, size, err
+}
+
+// HashingWriter wraps a Writer, feeding every byte written through it into a
+// hash.Hash. Hashing may be suspended with End and resumed with Begin, as for
+// HashingReader.
+type HashingWriter struct {
+	bout      Writer
+	h         hash.Hash
+	suspended bool
+	scratch   [utf8.UTFMax]byte // avoids allocating when hashing
+}
+
+// NewHashingWriter returns a HashingWriter which writes to bout and feeds h.
+// Hashing is initially active.
+func NewHashingWriter(bout Writer, h hash.Hash) *HashingWriter {
+	return &HashingWriter{bout: bout, h: h}
+}
+
+// Begin resumes hashing of written bytes.
+func (hw *HashingWriter) Begin() {
+	hw.suspended = false
+}
+
+// End suspends hashing; bytes written until the next call to Begin are not
+// fed to the hash.
+func (hw *HashingWriter) End() {
+	hw.suspended = true
+}
+
+// Hash returns the hash which written bytes are fed to.
+func (hw *HashingWriter) Hash() hash.Hash {
+	return hw.h
+}
+
+func (hw *HashingWriter) Write(buf []byte) (int, error) {
+	n, err := hw.bout.Write(buf)
+	if !hw.suspended {
+		hw.h.Write(buf[:n])
+	}
+	return n, err
+}
+
+func (hw *HashingWriter) WriteByte(b byte) error {
+	err := hw.bout.WriteByte(b)
+	if err == nil && !hw.suspended {
+		hw.scratch[0] = b
+		hw.h.Write(hw.scratch[:1])
+	}
+	return err
+}
+
+func (hw *HashingWriter) WriteRune(r rune) (int, error) {
+	n, err := hw.bout.WriteRune(r)
+	if !hw.suspended {
+		utf8.EncodeRune(hw.scratch[:], r)
+		hw.h.Write(hw.scratch[:n])
+	}
+	return n, err
+}
+
+// Flush flushes the underlying writer, if it requires flushing.
+func (hw *HashingWriter) Flush() error {
+	return FlushIfNecessary(hw.bout)
+}
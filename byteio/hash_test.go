@@ -0,0 +1,122 @@
+package byteio_test
+
+import (
+	"bytes"
+	"hash/adler32"
+	"hash/crc32"
+	"io"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// TestHashingReader checks that bytes consumed through every read path are
+// hashed, including runes decoded from invalid UTF-8.
+func TestHashingReader(t *testing.T) {
+	in := []byte("a€\xC3Z\xF0\x9F\x98bcdefg\xE2\x82")
+	h := crc32.NewIEEE()
+	hr := byteio.NewHashingReader(bytes.NewReader(in), h)
+
+	expRunes := []struct {
+		r    rune
+		size int
+	}{
+		{'a', 1}, {'€', 3}, {utf8.RuneError, 1}, {'Z', 1},
+		{utf8.RuneError, 1},
+	}
+	for _, exp := range expRunes {
+		r, size, err := hr.ReadRune()
+		if err != nil || r != exp.r || size != exp.size {
+			t.Fatalf("ReadRune: act %q/%d/%v ≠ exp %q/%d",
+				r, size, err, exp.r, exp.size)
+		}
+	}
+
+	// the bytes held back after the invalid sequence must be returned
+	// next, by whichever method
+	if b, err := hr.ReadByte(); err != nil || b != 0x9F {
+		t.Fatalf("ReadByte: act %X/%v ≠ exp 9F", b, err)
+	}
+	buf := make([]byte, 3)
+	if n, err := io.ReadFull(hr, buf); err != nil || !bytes.Equal(buf[:n], []byte{0x98, 'b', 'c'}) {
+		t.Fatalf("Read: act % X/%v", buf[:n], err)
+	}
+	if _, err := byteio.ReadUint32BE(hr); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		_, _, err := hr.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if act, exp := h.Sum32(), crc32.ChecksumIEEE(in); act != exp {
+		t.Errorf("checksum: act %08X ≠ exp %08X", act, exp)
+	}
+	if hr.Hash() != h {
+		t.Error("Hash() returned wrong hash")
+	}
+}
+
+// TestHashingReaderRegion checks that only the region between Begin and End
+// is hashed.
+func TestHashingReaderRegion(t *testing.T) {
+	in := []byte("headerBODYBODYtrailer")
+	h := adler32.New()
+	hr := byteio.NewHashingReader(bytes.NewReader(in), h)
+
+	hdr := make([]byte, 6)
+	io.ReadFull(hr, hdr)
+	h.Reset()
+	body := make([]byte, 8)
+	io.ReadFull(hr, body)
+	hr.End()
+	io.ReadFull(hr, make([]byte, 4))
+	hr.Begin()
+	hr.ReadByte()
+	hr.End()
+	io.Copy(io.Discard, hr)
+
+	if act, exp := h.Sum32(), adler32.Checksum([]byte("BODYBODYl")); act != exp {
+		t.Errorf("checksum: act %08X ≠ exp %08X", act, exp)
+	}
+}
+
+// TestHashingWriter checks that bytes written through every write path are
+// hashed, and that regions are respected.
+func TestHashingWriter(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	h := crc32.NewIEEE()
+	hw := byteio.NewHashingWriter(buf, h)
+
+	hw.WriteByte('x')
+	hw.WriteRune('€')
+	hw.WriteRune(-1)
+	hw.Write([]byte("abc"))
+	byteio.WriteUint32LE(hw, 0xDEADBEEF)
+	hw.End()
+	hw.Write([]byte("not hashed"))
+	hw.Begin()
+	hw.WriteByte('!')
+
+	data := buf.Bytes()
+	exp := crc32.NewIEEE()
+	exp.Write(data[:len(data)-11])
+	exp.Write([]byte{'!'})
+	if act := h.Sum32(); act != exp.Sum32() {
+		t.Errorf("checksum: act %08X ≠ exp %08X", act, exp.Sum32())
+	}
+
+	h.Reset()
+	hw = byteio.NewHashingWriter(&AbortWriter{when: 1}, h)
+	if _, err := hw.WriteRune('€'); err != ErrAbortWriter {
+		t.Errorf("unexpected error %v", err)
+	}
+	if act, exp := h.Sum32(), crc32.ChecksumIEEE([]byte{0xE2}); act != exp {
+		t.Errorf("partial rune: act %08X ≠ exp %08X", act, exp)
+	}
+}
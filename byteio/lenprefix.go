@@ -0,0 +1,272 @@
+package byteio
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// LengthError is returned when a length-prefixed field declares (or, when
+// writing, has) a length greater than permitted. When reading, the length
+// prefix itself has been consumed but none of the data has.
+type LengthError struct {
+	Length, Max uint64
+}
+
+func (e *LengthError) Error() string {
+	return fmt.Sprintf("byteio: length %d exceeds maximum %d",
+		e.Length, e.Max)
+}
+
+// readPrefixed completes a length-prefixed read once the prefix n has been
+// read (with error err). The data is read in chunks so that memory is only
+// allocated as data actually arrives.
+func readPrefixed(bin Reader, n uint64, err error, max int) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	if max < 0 {
+		max = 0
+	}
+	if n > uint64(max) {
+		return nil, &LengthError{Length: n, Max: uint64(max)}
+	}
+	buf, err := ReadBytes(bin, int(n))
+	return buf, midRecord(true, err)
+}
+
+// checkLength ensures that a field of length n may be encoded in a prefix
+// whose maximum value is max.
+func checkLength(n int, max uint64) error {
+	if uint64(n) > max {
+		return &LengthError{Length: uint64(n), Max: max}
+	}
+	return nil
+}
+
+// ReadBytes reads exactly n bytes into a new slice. Memory is allocated in
+// chunks as the data arrives, rather than all at once, so n may safely be
+// taken from the stream. If no bytes are available, io.EOF is returned; if
+// only some are, io.ErrUnexpectedEOF.
+func ReadBytes(bin Reader, n int) ([]byte, error) {
+	if n < 0 {
+		return nil, errNegativeCount
+	}
+	const chunk = 64 << 10
+	buf := make([]byte, 0, minInt(n, chunk))
+	for len(buf) < n {
+		m := minInt(n-len(buf), chunk)
+		if cap(buf)-len(buf) < m {
+			nbuf := make([]byte, len(buf), 2*cap(buf)+m)
+			copy(nbuf, buf)
+			buf = nbuf
+		}
+		if err := readFull(bin, buf[len(buf):len(buf)+m]); err != nil {
+			return nil, midRecord(len(buf) > 0, err)
+		}
+		buf = buf[:len(buf)+m]
+	}
+	return buf, nil
+}
+
+// ReadBytesU8 reads a byte slice prefixed by its length as a uint8. If the
+// length exceeds max, a *LengthError is returned.
+func ReadBytesU8(bin Reader, max int) ([]byte, error) {
+	n, err := bin.ReadByte()
+	return readPrefixed(bin, uint64(n), err, max)
+}
+
+// ReadBytesU16BE reads a byte slice prefixed by its length as a big-endian
+// uint16. If the length exceeds max, a *LengthError is returned.
+func ReadBytesU16BE(bin Reader, max int) ([]byte, error) {
+	n, err := ReadUint16BE(bin)
+	return readPrefixed(bin, uint64(n), err, max)
+}
+
+// ReadBytesU16LE reads a byte slice prefixed by its length as a little-endian
+// uint16. If the length exceeds max, a *LengthError is returned.
+func ReadBytesU16LE(bin Reader, max int) ([]byte, error) {
+	n, err := ReadUint16LE(bin)
+	return readPrefixed(bin, uint64(n), err, max)
+}
+
+// ReadBytesU32BE reads a byte slice prefixed by its length as a big-endian
+// uint32. If the length exceeds max, a *LengthError is returned.
+func ReadBytesU32BE(bin Reader, max int) ([]byte, error) {
+	n, err := ReadUint32BE(bin)
+	return readPrefixed(bin, uint64(n), err, max)
+}
+
+// ReadBytesU32LE reads a byte slice prefixed by its length as a little-endian
+// uint32. If the length exceeds max, a *LengthError is returned.
+func ReadBytesU32LE(bin Reader, max int) ([]byte, error) {
+	n, err := ReadUint32LE(bin)
+	return readPrefixed(bin, uint64(n), err, max)
+}
+
+// ReadStringU8 reads a string prefixed by its length as a uint8. If the
+// length exceeds max, a *LengthError is returned.
+func ReadStringU8(bin Reader, max int) (string, error) {
+	b, err := ReadBytesU8(bin, max)
+	return string(b), err
+}
+
+// ReadStringU16BE reads a string prefixed by its length as a big-endian
+// uint16. If the length exceeds max, a *LengthError is returned.
+func ReadStringU16BE(bin Reader, max int) (string, error) {
+	b, err := ReadBytesU16BE(bin, max)
+	return string(b), err
+}
+
+// ReadStringU16LE reads a string prefixed by its length as a little-endian
+// uint16. If the length exceeds max, a *LengthError is returned.
+func ReadStringU16LE(bin Reader, max int) (string, error) {
+	b, err := ReadBytesU16LE(bin, max)
+	return string(b), err
+}
+
+// ReadStringU32BE reads a string prefixed by its length as a big-endian
+// uint32. If the length exceeds max, a *LengthError is returned.
+func ReadStringU32BE(bin Reader, max int) (string, error) {
+	b, err := ReadBytesU32BE(bin, max)
+	return string(b), err
+}
+
+// ReadStringU32LE reads a string prefixed by its length as a little-endian
+// uint32. If the length exceeds max, a *LengthError is returned.
+func ReadStringU32LE(bin Reader, max int) (string, error) {
+	b, err := ReadBytesU32LE(bin, max)
+	return string(b), err
+}
+
+// WriteBytesU8 writes a byte slice prefixed by its length as a uint8. A
+// *LengthError is returned if the slice is too long for the prefix.
+func WriteBytesU8(bout Writer, b []byte) error {
+	if err := checkLength(len(b), math.MaxUint8); err != nil {
+		return err
+	}
+	if err := bout.WriteByte(byte(len(b))); err != nil {
+		return err
+	}
+	_, err := bout.Write(b)
+	return err
+}
+
+// WriteBytesU16BE writes a byte slice prefixed by its length as a big-endian
+// uint16. A *LengthError is returned if the slice is too long for the prefix.
+func WriteBytesU16BE(bout Writer, b []byte) error {
+	if err := checkLength(len(b), math.MaxUint16); err != nil {
+		return err
+	}
+	if err := WriteUint16BE(bout, uint16(len(b))); err != nil {
+		return err
+	}
+	_, err := bout.Write(b)
+	return err
+}
+
+// WriteBytesU16LE writes a byte slice prefixed by its length as a
+// little-endian uint16. A *LengthError is returned if the slice is too long
+// for the prefix.
+func WriteBytesU16LE(bout Writer, b []byte) error {
+	if err := checkLength(len(b), math.MaxUint16); err != nil {
+		return err
+	}
+	if err := WriteUint16LE(bout, uint16(len(b))); err != nil {
+		return err
+	}
+	_, err := bout.Write(b)
+	return err
+}
+
+// WriteBytesU32BE writes a byte slice prefixed by its length as a big-endian
+// uint32. A *LengthError is returned if the slice is too long for the prefix.
+func WriteBytesU32BE(bout Writer, b []byte) error {
+	if err := checkLength(len(b), math.MaxUint32); err != nil {
+		return err
+	}
+	if err := WriteUint32BE(bout, uint32(len(b))); err != nil {
+		return err
+	}
+	_, err := bout.Write(b)
+	return err
+}
+
+// WriteBytesU32LE writes a byte slice prefixed by its length as a
+// little-endian uint32. A *LengthError is returned if the slice is too long
+// for the prefix.
+func WriteBytesU32LE(bout Writer, b []byte) error {
+	if err := checkLength(len(b), math.MaxUint32); err != nil {
+		return err
+	}
+	if err := WriteUint32LE(bout, uint32(len(b))); err != nil {
+		return err
+	}
+	_, err := bout.Write(b)
+	return err
+}
+
+// WriteStringU8 writes a string prefixed by its length as a uint8. A
+// *LengthError is returned if the string is too long for the prefix.
+func WriteStringU8(bout Writer, s string) error {
+	if err := checkLength(len(s), math.MaxUint8); err != nil {
+		return err
+	}
+	if err := bout.WriteByte(byte(len(s))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(bout, s)
+	return err
+}
+
+// WriteStringU16BE writes a string prefixed by its length as a big-endian
+// uint16. A *LengthError is returned if the string is too long for the prefix.
+func WriteStringU16BE(bout Writer, s string) error {
+	if err := checkLength(len(s), math.MaxUint16); err != nil {
+		return err
+	}
+	if err := WriteUint16BE(bout, uint16(len(s))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(bout, s)
+	return err
+}
+
+// WriteStringU16LE writes a string prefixed by its length as a little-endian
+// uint16. A *LengthError is returned if the string is too long for the prefix.
+func WriteStringU16LE(bout Writer, s string) error {
+	if err := checkLength(len(s), math.MaxUint16); err != nil {
+		return err
+	}
+	if err := WriteUint16LE(bout, uint16(len(s))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(bout, s)
+	return err
+}
+
+// WriteStringU32BE writes a string prefixed by its length as a big-endian
+// uint32. A *LengthError is returned if the string is too long for the prefix.
+func WriteStringU32BE(bout Writer, s string) error {
+	if err := checkLength(len(s), math.MaxUint32); err != nil {
+		return err
+	}
+	if err := WriteUint32BE(bout, uint32(len(s))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(bout, s)
+	return err
+}
+
+// WriteStringU32LE writes a string prefixed by its length as a little-endian
+// uint32. A *LengthError is returned if the string is too long for the prefix.
+func WriteStringU32LE(bout Writer, s string) error {
+	if err := checkLength(len(s), math.MaxUint32); err != nil {
+		return err
+	}
+	if err := WriteUint32LE(bout, uint32(len(s))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(bout, s)
+	return err
+}
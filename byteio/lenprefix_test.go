@@ -0,0 +1,182 @@
+package byteio_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+type prefixFuncs struct {
+	name     string
+	size     int // size of prefix in bytes
+	max      int // maximum encodable length
+	readB    func(byteio.Reader, int) ([]byte, error)
+	readS    func(byteio.Reader, int) (string, error)
+	writeB   func(byteio.Writer, []byte) error
+	writeS   func(byteio.Writer, string) error
+	encodeFn func(n int) []byte
+}
+
+var prefixTests = []prefixFuncs{
+	{"U8", 1, 0xFF, byteio.ReadBytesU8, byteio.ReadStringU8,
+		byteio.WriteBytesU8, byteio.WriteStringU8,
+		func(n int) []byte { return []byte{byte(n)} }},
+	{"U16BE", 2, 0xFFFF, byteio.ReadBytesU16BE, byteio.ReadStringU16BE,
+		byteio.WriteBytesU16BE, byteio.WriteStringU16BE,
+		func(n int) []byte { return []byte{byte(n >> 8), byte(n)} }},
+	{"U16LE", 2, 0xFFFF, byteio.ReadBytesU16LE, byteio.ReadStringU16LE,
+		byteio.WriteBytesU16LE, byteio.WriteStringU16LE,
+		func(n int) []byte { return []byte{byte(n), byte(n >> 8)} }},
+	{"U32BE", 4, -1, byteio.ReadBytesU32BE, byteio.ReadStringU32BE,
+		byteio.WriteBytesU32BE, byteio.WriteStringU32BE,
+		func(n int) []byte {
+			return []byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}
+		}},
+	{"U32LE", 4, -1, byteio.ReadBytesU32LE, byteio.ReadStringU32LE,
+		byteio.WriteBytesU32LE, byteio.WriteStringU32LE,
+		func(n int) []byte {
+			return []byte{byte(n), byte(n >> 8), byte(n >> 16), byte(n >> 24)}
+		}},
+}
+
+// TestPrefixed verifies that length-prefixed values are written with the
+// expected prefix and can be read back.
+func TestPrefixed(t *testing.T) {
+	for _, pt := range prefixTests {
+		for _, s := range []string{"", "x", "hello, world", strings.Repeat("z", 255)} {
+			exp := append(pt.encodeFn(len(s)), s...)
+
+			buf := bytes.NewBuffer(nil)
+			if err := pt.writeS(buf, s); err != nil {
+				t.Fatalf("WriteString%s: unexpected error %v", pt.name, err)
+			}
+			if err := pt.writeB(buf, []byte(s)); err != nil {
+				t.Fatalf("WriteBytes%s: unexpected error %v", pt.name, err)
+			}
+			if !bytes.Equal(buf.Bytes(), append(exp, exp...)) {
+				t.Errorf("Write%s(%q): act % X", pt.name, s, buf.Bytes())
+			}
+
+			if act, err := pt.readS(buf, len(s)); err != nil {
+				t.Errorf("ReadString%s: unexpected error %v", pt.name, err)
+			} else if act != s {
+				t.Errorf("ReadString%s: act %q ≠ exp %q", pt.name, act, s)
+			}
+			if act, err := pt.readB(buf, len(s)); err != nil {
+				t.Errorf("ReadBytes%s: unexpected error %v", pt.name, err)
+			} else if string(act) != s {
+				t.Errorf("ReadBytes%s: act %q ≠ exp %q", pt.name, act, s)
+			}
+		}
+	}
+}
+
+// TestPrefixedMax ensures that a declared length over the limit results in a
+// *LengthError without consuming or allocating the data.
+func TestPrefixedMax(t *testing.T) {
+	for _, pt := range prefixTests {
+		in := append(pt.encodeFn(10), make([]byte, 10)...)
+		bin := bytes.NewReader(in)
+		_, err := pt.readB(bin, 9)
+		if lerr, ok := err.(*byteio.LengthError); !ok {
+			t.Errorf("ReadBytes%s: unexpected error %v", pt.name, err)
+		} else if lerr.Length != 10 || lerr.Max != 9 {
+			t.Errorf("ReadBytes%s: unexpected error fields %+v",
+				pt.name, lerr)
+		}
+		if bin.Len() != 10 {
+			t.Errorf("ReadBytes%s: consumed data on error", pt.name)
+		}
+	}
+
+	// a hostile 4GiB length must not be allocated
+	in := []byte{0xFF, 0xFF, 0xFF, 0xFF}
+	if _, err := byteio.ReadBytesU32BE(bytes.NewReader(in), 1<<20); err == nil {
+		t.Error("ReadBytesU32BE: accepted hostile length")
+	}
+}
+
+// TestPrefixedShort ensures that EOF semantics match the numeric readers.
+func TestPrefixedShort(t *testing.T) {
+	for _, pt := range prefixTests {
+		if _, err := pt.readB(bytes.NewReader(nil), 100); err != io.EOF {
+			t.Errorf("ReadBytes%s(empty): unexpected error %v",
+				pt.name, err)
+		}
+
+		full := append(pt.encodeFn(4), "abcd"...)
+		for i := 1; i < len(full); i++ {
+			_, err := pt.readB(bytes.NewReader(full[:i]), 100)
+			if err != io.ErrUnexpectedEOF {
+				t.Errorf("ReadBytes%s/%d: unexpected error %v",
+					pt.name, i, err)
+			}
+		}
+	}
+}
+
+// TestReadBytes checks reads spanning several allocation chunks, and that a
+// large count with little data available is reported as a short read.
+func TestReadBytes(t *testing.T) {
+	in := bytes.Repeat([]byte("0123456789"), 20000)
+	act, err := byteio.ReadBytes(bytes.NewReader(in), len(in))
+	if err != nil || !bytes.Equal(act, in) {
+		t.Errorf("ReadBytes(%d): act %d bytes/%v", len(in), len(act), err)
+	}
+	if act, err := byteio.ReadBytes(bytes.NewReader(nil), 0); err != nil ||
+		len(act) != 0 {
+		t.Errorf("ReadBytes(0): act % X/%v", act, err)
+	}
+	if _, err := byteio.ReadBytes(bytes.NewReader(nil), 1<<30); err != io.EOF {
+		t.Errorf("ReadBytes(empty): act %v ≠ exp %v", err, io.EOF)
+	}
+	_, err = byteio.ReadBytes(bytes.NewReader(in), 1<<30)
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadBytes(short): act %v ≠ exp %v", err, io.ErrUnexpectedEOF)
+	}
+	if _, err := byteio.ReadBytes(bytes.NewReader(in), -1); err == nil {
+		t.Error("ReadBytes(-1): expected error")
+	}
+}
+
+// TestPrefixedWriteTooLong ensures that data too long for the prefix is
+// rejected before anything is written.
+func TestPrefixedWriteTooLong(t *testing.T) {
+	for _, pt := range prefixTests {
+		if pt.max < 0 {
+			continue
+		}
+		buf := bytes.NewBuffer(nil)
+		err := pt.writeB(buf, make([]byte, pt.max+1))
+		if _, ok := err.(*byteio.LengthError); !ok {
+			t.Errorf("WriteBytes%s: unexpected error %v", pt.name, err)
+		}
+		err = pt.writeS(buf, strings.Repeat("x", pt.max+1))
+		if _, ok := err.(*byteio.LengthError); !ok {
+			t.Errorf("WriteString%s: unexpected error %v", pt.name, err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("Write%s: wrote data on error", pt.name)
+		}
+	}
+}
+
+// TestPrefixedWriteErr ensures that write errors are propagated at every byte
+// position.
+func TestPrefixedWriteErr(t *testing.T) {
+	for _, pt := range prefixTests {
+		for i := 0; i < pt.size+4; i++ {
+			if err := pt.writeB(&AbortWriter{when: i}, []byte("abcd")); err != ErrAbortWriter {
+				t.Errorf("WriteBytes%s/%d: unexpected error %v",
+					pt.name, i, err)
+			}
+			if err := pt.writeS(&AbortWriter{when: i}, "abcd"); err != ErrAbortWriter {
+				t.Errorf("WriteString%s/%d: unexpected error %v",
+					pt.name, i, err)
+			}
+		}
+	}
+}
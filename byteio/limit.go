@@ -0,0 +1,124 @@
+package byteio
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// LimitedReader reads from an underlying Reader but stops with io.EOF after a
+// fixed number of bytes. Unlike io.LimitedReader, it retains the ReadByte and
+// ReadRune methods and so is itself a Reader.
+type LimitedReader struct {
+	src limitSrc
+	rb  runeBuf
+}
+
+// limitSrc is an underlying Reader bounded by the number of bytes remaining.
+type limitSrc struct {
+	bin Reader
+	n   int64
+}
+
+func (ls *limitSrc) Read(buf []byte) (int, error) {
+	if ls.n <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(buf)) > ls.n {
+		buf = buf[:ls.n]
+	}
+	n, err := ls.bin.Read(buf)
+	ls.n -= int64(n)
+	return n, err
+}
+
+func (ls *limitSrc) ReadByte() (byte, error) {
+	if ls.n <= 0 {
+		return 0, io.EOF
+	}
+	b, err := ls.bin.ReadByte()
+	if err == nil {
+		ls.n--
+	}
+	return b, err
+}
+
+// LimitReader returns a Reader which reads at most n bytes from bin. It is
+// useful for parsing a sub-record whose length is known in advance.
+func LimitReader(bin Reader, n int64) *LimitedReader {
+	return &LimitedReader{src: limitSrc{bin: bin, n: n}}
+}
+
+// Remaining returns the number of bytes which may still be read before the
+// limit is reached.
+func (lr *LimitedReader) Remaining() int64 {
+	return lr.src.n + int64(lr.rb.n)
+}
+
+// Discard consumes any bytes remaining up to the limit, so that the
+// underlying reader is positioned just after the sub-record. It returns
+// io.ErrUnexpectedEOF if the underlying reader ends before the limit.
+func (lr *LimitedReader) Discard() error {
+	lr.rb.n = 0
+	// a deferred io.EOF at the limit is expected; one before the limit is
+	// reported again by Skip below
+	if err := lr.rb.takeErr(); err != nil && err != io.EOF {
+		return err
+	}
+	n := lr.src.n
+	lr.src.n = 0
+	err := Skip(lr.src.bin, n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (lr *LimitedReader) Read(buf []byte) (int, error) {
+	return lr.rb.read(&lr.src, buf)
+}
+
+func (lr *LimitedReader) ReadByte() (byte, error) {
+	return lr.rb.readByte(&lr.src)
+}
+
+func (lr *LimitedReader) ReadRune() (rune, int, error) {
+	if lr.rb.n == 0 && lr.rb.err == nil && lr.src.n >= utf8.UTFMax {
+		// cannot overrun the limit, so use the underlying reader's
+		// (possibly much faster) implementation
+		r, size, err := lr.src.bin.ReadRune()
+		lr.src.n -= int64(size)
+		return r, size, err
+	}
+	r, size, _, err := lr.rb.readRune(&lr.src)
+	return r, size, err
+}
+
+// discarder is implemented by readers (such as bufio.Reader) which can skip
+// input efficiently.
+type discarder interface {
+	Discard(n int) (int, error)
+}
+
+// Skip discards the next n bytes from bin. It returns io.EOF if no bytes were
+// available and io.ErrUnexpectedEOF if only some were.
+func Skip(bin Reader, n int64) error {
+	if n <= 0 {
+		return nil
+	}
+
+	var (
+		done int64
+		err  error
+	)
+	if d, ok := bin.(discarder); ok && int64(int(n)) == n {
+		var k int
+		k, err = d.Discard(int(n))
+		done = int64(k)
+	} else {
+		done, err = io.CopyN(io.Discard, bin, n)
+	}
+	if err != nil {
+		return readErr(bin, int(done), err)
+	}
+	return nil
+}
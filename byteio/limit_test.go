@@ -0,0 +1,186 @@
+package byteio_test
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+var _ byteio.Reader = (*byteio.LimitedReader)(nil)
+
+// TestLimitReader checks that each read path stops at the limit and that
+// Remaining tracks consumption.
+func TestLimitReader(t *testing.T) {
+	in := bytes.NewReader([]byte("abcdefgh"))
+	lr := byteio.LimitReader(in, 6)
+
+	if b, err := lr.ReadByte(); err != nil || b != 'a' {
+		t.Fatalf("ReadByte: act %q/%v ≠ exp 'a'", b, err)
+	}
+	if r, size, err := lr.ReadRune(); err != nil || r != 'b' || size != 1 {
+		t.Fatalf("ReadRune: act %q/%d/%v ≠ exp 'b'", r, size, err)
+	}
+	if act := lr.Remaining(); act != 4 {
+		t.Errorf("Remaining: act %d ≠ exp 4", act)
+	}
+
+	buf := make([]byte, 8)
+	n, err := lr.Read(buf)
+	if err != nil || string(buf[:n]) != "cdef" {
+		t.Fatalf("Read: act %q/%v ≠ exp \"cdef\"", buf[:n], err)
+	}
+	if _, err := lr.ReadByte(); err != io.EOF {
+		t.Errorf("ReadByte at limit: unexpected error %v", err)
+	}
+	if _, _, err := lr.ReadRune(); err != io.EOF {
+		t.Errorf("ReadRune at limit: unexpected error %v", err)
+	}
+	if _, err := lr.Read(buf); err != io.EOF {
+		t.Errorf("Read at limit: unexpected error %v", err)
+	}
+
+	// the underlying reader must not have been read past the limit
+	if b, err := in.ReadByte(); err != nil || b != 'g' {
+		t.Errorf("underlying: act %q/%v ≠ exp 'g'", b, err)
+	}
+}
+
+// TestLimitReaderRune checks that a rune straddling the limit is not read
+// beyond it.
+func TestLimitReaderRune(t *testing.T) {
+	in := bytes.NewReader([]byte("a€€"))
+	lr := byteio.LimitReader(in, 5)
+
+	exp := []struct {
+		r    rune
+		size int
+	}{
+		{'a', 1}, {'€', 3}, {utf8.RuneError, 1},
+	}
+	for _, e := range exp {
+		r, size, err := lr.ReadRune()
+		if err != nil || r != e.r || size != e.size {
+			t.Fatalf("ReadRune: act %q/%d/%v ≠ exp %q/%d",
+				r, size, err, e.r, e.size)
+		}
+	}
+	if act := lr.Remaining(); act != 0 {
+		t.Errorf("Remaining: act %d ≠ exp 0", act)
+	}
+	if _, _, err := lr.ReadRune(); err != io.EOF {
+		t.Errorf("unexpected error %v", err)
+	}
+	if act := in.Len(); act != 2 {
+		t.Errorf("underlying remaining: act %d ≠ exp 2", act)
+	}
+
+	// held-back bytes are returned by subsequent byte reads
+	lr = byteio.LimitReader(bytes.NewReader([]byte("\xE2\x82")), 2)
+	if r, size, err := lr.ReadRune(); err != nil || r != utf8.RuneError || size != 1 {
+		t.Fatalf("ReadRune: act %q/%d/%v", r, size, err)
+	}
+	if act := lr.Remaining(); act != 1 {
+		t.Errorf("Remaining: act %d ≠ exp 1", act)
+	}
+	if b, err := lr.ReadByte(); err != nil || b != 0x82 {
+		t.Errorf("ReadByte: act %X/%v ≠ exp 82", b, err)
+	}
+}
+
+// TestLimitReaderNested checks that nested sub-records may be parsed and
+// skipped.
+func TestLimitReaderNested(t *testing.T) {
+	in := bytes.NewReader([]byte{
+		0x00, 0x06, // outer length
+		0x00, 0x02, 0xAA, 0xBB, // inner record
+		0xCC, 0xDD, // trailing outer data
+		0xEE,
+	})
+
+	n, _ := byteio.ReadUint16BE(in)
+	outer := byteio.LimitReader(in, int64(n))
+	n, _ = byteio.ReadUint16BE(outer)
+	inner := byteio.LimitReader(outer, int64(n))
+	if b, err := inner.ReadByte(); err != nil || b != 0xAA {
+		t.Fatalf("inner: act %X/%v ≠ exp AA", b, err)
+	}
+	if err := inner.Discard(); err != nil {
+		t.Fatal(err)
+	}
+	if act := outer.Remaining(); act != 2 {
+		t.Errorf("outer remaining: act %d ≠ exp 2", act)
+	}
+	if err := outer.Discard(); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := in.ReadByte(); err != nil || b != 0xEE {
+		t.Errorf("underlying: act %X/%v ≠ exp EE", b, err)
+	}
+}
+
+// TestLimitReaderDiscardShort checks that Discard reports a truncated
+// sub-record.
+func TestLimitReaderDiscardShort(t *testing.T) {
+	lr := byteio.LimitReader(bytes.NewReader([]byte{1, 2}), 4)
+	if err := lr.Discard(); err != io.ErrUnexpectedEOF {
+		t.Errorf("unexpected error %v", err)
+	}
+	lr = byteio.LimitReader(bytes.NewReader(nil), 4)
+	if err := lr.Discard(); err != io.ErrUnexpectedEOF {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+// TestLimitReaderDiscardRune checks that Discard ignores the end of input
+// found by a preceding ReadRune, unless it came before the limit.
+func TestLimitReaderDiscardRune(t *testing.T) {
+	in := bytes.NewReader([]byte("\xE2\x82\xFF"))
+	lr := byteio.LimitReader(in, 2)
+	if r, _, err := lr.ReadRune(); err != nil || r != utf8.RuneError {
+		t.Fatalf("ReadRune: act %q/%v", r, err)
+	}
+	if err := lr.Discard(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if b, err := in.ReadByte(); err != nil || b != 0xFF {
+		t.Errorf("underlying: act %X/%v ≠ exp FF", b, err)
+	}
+
+	lr = byteio.LimitReader(bytes.NewReader([]byte("\xE2")), 4)
+	if r, _, err := lr.ReadRune(); err != nil || r != utf8.RuneError {
+		t.Fatalf("ReadRune: act %q/%v", r, err)
+	}
+	if err := lr.Discard(); err != io.ErrUnexpectedEOF {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+// TestSkip checks Skip against both a reader supporting Discard and one which
+// does not.
+func TestSkip(t *testing.T) {
+	for _, mk := range []func([]byte) byteio.Reader{
+		func(b []byte) byteio.Reader { return bytes.NewReader(b) },
+		func(b []byte) byteio.Reader { return bufio.NewReader(bytes.NewReader(b)) },
+	} {
+		bin := mk([]byte{1, 2, 3, 4, 5})
+		if err := byteio.Skip(bin, 3); err != nil {
+			t.Fatal(err)
+		}
+		if b, err := bin.ReadByte(); err != nil || b != 4 {
+			t.Errorf("%T: act %X/%v ≠ exp 04", bin, b, err)
+		}
+		if err := byteio.Skip(bin, 0); err != nil {
+			t.Errorf("%T: unexpected error %v", bin, err)
+		}
+		if err := byteio.Skip(bin, 2); err != io.ErrUnexpectedEOF {
+			t.Errorf("%T: unexpected error %v", bin, err)
+		}
+		if err := byteio.Skip(bin, 2); err != io.EOF {
+			t.Errorf("%T: unexpected error %v", bin, err)
+		}
+	}
+}
@@ -0,0 +1,575 @@
+package byteio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// UnsupportedTypeError is returned by Marshal and Unmarshal when they
+// encounter a type which has no binary encoding.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return "byteio: unsupported type " + e.Type.String()
+}
+
+var errNotStructPtr = errors.New("byteio: Unmarshal requires a non-nil " +
+	"pointer to a struct")
+
+// Unmarshal decodes a binary record from bin into the struct pointed to by v.
+// If bin is at end of file before any field has been read, io.EOF is returned;
+// if the record is truncated, io.ErrUnexpectedEOF is returned.
+//
+// Unmarshal and Marshal walk the fields of a struct in declaration order,
+// reading or writing each one with the package's fixed-width functions. The
+// encoding of each field may be controlled with a `byteio:"…"` struct tag
+// holding a comma-separated list of options:
+//
+//	be        big-endian (network) byte order; this is the default
+//	le        little-endian byte order
+//	len=Name  length of a slice or string is given by the earlier integer
+//	          field Name of the same struct
+//	skip=N    N bytes of padding precede the field
+//	-         the field is ignored entirely
+//
+// The byte order of a field also applies to the elements of an array or slice.
+// Nested structs use the tags of their own fields. Fields named _ are treated
+// as padding of the size of their type: they are discarded when reading and
+// written as zero bytes.
+//
+// Supported field types are bool, the sized integer and floating point types,
+// arrays of a supported type, nested structs, and slices or strings carrying a
+// len= option. The platform-dependent int, uint and uintptr types are not
+// supported.
+//
+// Where decoding speed matters, the cmd/byteiogen tool generates equivalent
+// DecodeFrom and EncodeTo methods which avoid reflection.
+func Unmarshal(bin Reader, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() ||
+		rv.Elem().Kind() != reflect.Struct {
+		return errNotStructPtr
+	}
+	rv = rv.Elem()
+	plan, err := planFor(rv.Type())
+	if err != nil {
+		return err
+	}
+	return plan.decode(bin, rv)
+}
+
+// Marshal encodes the struct v (or the struct pointed to by v) as a binary
+// record to bout, using the struct tags described for Unmarshal. It is an error
+// for the length of a slice or string to differ from the value of its len=
+// field.
+func Marshal(bout Writer, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return &UnsupportedTypeError{Type: reflect.TypeOf(v)}
+	}
+	if !rv.CanAddr() {
+		// byte arrays are written via Bytes(), which requires an
+		// addressable value
+		tmp := reflect.New(rv.Type()).Elem()
+		tmp.Set(rv)
+		rv = tmp
+	}
+	plan, err := planFor(rv.Type())
+	if err != nil {
+		return err
+	}
+	return plan.encode(bout, rv)
+}
+
+type (
+	decodeFunc  func(bin Reader, v reflect.Value) error
+	encodeFunc  func(bout Writer, v reflect.Value) error
+	decodeNFunc func(bin Reader, v reflect.Value, n int) error
+)
+
+// structPlan is the cached encoding plan for a struct type.
+type structPlan struct {
+	fields []fieldPlan
+}
+
+type fieldPlan struct {
+	name     string
+	index    int
+	skip     int
+	lenIndex int // index of len= field, or -1
+	blank    bool
+	dec      decodeFunc
+	decN     decodeNFunc // used in place of dec for len= fields
+	enc      encodeFunc
+}
+
+// planResult is stored in planCache so that errors are cached too.
+type planResult struct {
+	plan *structPlan
+	err  error
+}
+
+var planCache sync.Map // reflect.Type → *planResult
+
+// planFor returns the (cached) encoding plan for struct type t.
+func planFor(t reflect.Type) (*structPlan, error) {
+	if res, ok := planCache.Load(t); ok {
+		res := res.(*planResult)
+		return res.plan, res.err
+	}
+	plan, err := buildPlan(t)
+	res, _ := planCache.LoadOrStore(t, &planResult{plan: plan, err: err})
+	return res.(*planResult).plan, res.(*planResult).err
+}
+
+func buildPlan(t reflect.Type) (*structPlan, error) {
+	plan := new(structPlan)
+	indices := make(map[string]int)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("byteio")
+		if tag == "-" {
+			continue
+		}
+		fp := fieldPlan{
+			name:     sf.Name,
+			index:    i,
+			lenIndex: -1,
+			blank:    sf.Name == "_",
+		}
+		if sf.PkgPath != "" && !fp.blank {
+			return nil, fmt.Errorf("byteio: %v.%s: unexported field",
+				t, sf.Name)
+		}
+
+		le := false
+		lenName := ""
+		if tag != "" {
+			for _, opt := range strings.Split(tag, ",") {
+				switch {
+				case opt == "be":
+					le = false
+				case opt == "le":
+					le = true
+				case strings.HasPrefix(opt, "len="):
+					lenName = opt[4:]
+				case strings.HasPrefix(opt, "skip="):
+					n, err := strconv.Atoi(opt[5:])
+					if err != nil || n < 0 {
+						return nil, fmt.Errorf("byteio: "+
+							"%v.%s: invalid option %q",
+							t, sf.Name, opt)
+					}
+					fp.skip = n
+				default:
+					return nil, fmt.Errorf("byteio: %v.%s: "+
+						"unknown option %q", t, sf.Name, opt)
+				}
+			}
+		}
+
+		var err error
+		switch sf.Type.Kind() {
+		case reflect.Slice, reflect.String:
+			if lenName == "" {
+				return nil, fmt.Errorf("byteio: %v.%s: slice or "+
+					"string requires len= option",
+					t, sf.Name)
+			}
+			idx, ok := indices[lenName]
+			if !ok {
+				return nil, fmt.Errorf("byteio: %v.%s: len=%s "+
+					"does not name an earlier field",
+					t, sf.Name, lenName)
+			}
+			switch t.Field(idx).Type.Kind() {
+			case reflect.Uint8, reflect.Uint16, reflect.Uint32,
+				reflect.Uint64, reflect.Int8, reflect.Int16,
+				reflect.Int32, reflect.Int64:
+			default:
+				return nil, fmt.Errorf("byteio: %v.%s: len=%s "+
+					"is not an integer field",
+					t, sf.Name, lenName)
+			}
+			fp.lenIndex = idx
+			fp.decN, fp.enc, err = countedCodecFor(sf.Type, le)
+		default:
+			if lenName != "" {
+				return nil, fmt.Errorf("byteio: %v.%s: len= "+
+					"option requires a slice or string",
+					t, sf.Name)
+			}
+			fp.dec, fp.enc, err = codecFor(sf.Type, le)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		plan.fields = append(plan.fields, fp)
+		if !fp.blank {
+			indices[sf.Name] = i
+		}
+	}
+	return plan, nil
+}
+
+func (plan *structPlan) decode(bin Reader, v reflect.Value) error {
+	started := false
+	for i := range plan.fields {
+		fp := &plan.fields[i]
+		if fp.skip > 0 {
+			if err := Skip(bin, int64(fp.skip)); err != nil {
+				return midRecord(started, err)
+			}
+			started = true
+		}
+
+		fv := v.Field(fp.index)
+		if fp.blank {
+			fv = reflect.New(fv.Type()).Elem()
+		}
+
+		var err error
+		if fp.lenIndex >= 0 {
+			var n int
+			if n, err = lengthOf(v.Field(fp.lenIndex), fp.name); err != nil {
+				return err
+			}
+			err = fp.decN(bin, fv, n)
+		} else {
+			err = fp.dec(bin, fv)
+		}
+		if err != nil {
+			return midRecord(started, err)
+		}
+		started = true
+	}
+	return nil
+}
+
+func (plan *structPlan) encode(bout Writer, v reflect.Value) error {
+	for i := range plan.fields {
+		fp := &plan.fields[i]
+		for j := 0; j < fp.skip; j++ {
+			if err := bout.WriteByte(0); err != nil {
+				return err
+			}
+		}
+
+		fv := v.Field(fp.index)
+		if fp.blank {
+			fv = reflect.New(fv.Type()).Elem()
+		}
+		if fp.lenIndex >= 0 {
+			n, err := lengthOf(v.Field(fp.lenIndex), fp.name)
+			if err != nil {
+				return err
+			}
+			if n != fv.Len() {
+				return fmt.Errorf("byteio: %s: length %d does "+
+					"not match len= field value %d",
+					fp.name, fv.Len(), n)
+			}
+		}
+		if err := fp.enc(bout, fv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// midRecord converts io.EOF into io.ErrUnexpectedEOF, and marks an
+// *AbortError as Partial, if part of a record had already been read.
+func midRecord(started bool, err error) error {
+	if started {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if ae, ok := err.(*AbortError); ok && !ae.Partial {
+			return &AbortError{Err: ae.Err, Partial: true}
+		}
+	}
+	return err
+}
+
+// lengthOf returns the value of a len= field as a slice length.
+func lengthOf(v reflect.Value, name string) (int, error) {
+	var n uint64
+	switch v.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() < 0 {
+			return 0, fmt.Errorf("byteio: %s: negative length %d",
+				name, v.Int())
+		}
+		n = uint64(v.Int())
+	default:
+		n = v.Uint()
+	}
+	if n > math.MaxInt32 {
+		return 0, fmt.Errorf("byteio: %s: length %d too large",
+			name, n)
+	}
+	return int(n), nil
+}
+
+// codecFor returns the decode and encode functions for a fixed-size type.
+func codecFor(t reflect.Type, le bool) (decodeFunc, encodeFunc, error) {
+	r16, r32, r64 := ReadUint16BE, ReadUint32BE, ReadUint64BE
+	w16, w32, w64 := WriteUint16BE, WriteUint32BE, WriteUint64BE
+	if le {
+		r16, r32, r64 = ReadUint16LE, ReadUint32LE, ReadUint64LE
+		w16, w32, w64 = WriteUint16LE, WriteUint32LE, WriteUint64LE
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return func(bin Reader, v reflect.Value) error {
+				b, err := bin.ReadByte()
+				if err == nil {
+					v.SetBool(b != 0)
+				}
+				return err
+			}, func(bout Writer, v reflect.Value) error {
+				if v.Bool() {
+					return bout.WriteByte(1)
+				}
+				return bout.WriteByte(0)
+			}, nil
+
+	case reflect.Uint8:
+		return func(bin Reader, v reflect.Value) error {
+				b, err := bin.ReadByte()
+				if err == nil {
+					v.SetUint(uint64(b))
+				}
+				return err
+			}, func(bout Writer, v reflect.Value) error {
+				return bout.WriteByte(byte(v.Uint()))
+			}, nil
+
+	case reflect.Int8:
+		return func(bin Reader, v reflect.Value) error {
+				b, err := bin.ReadByte()
+				if err == nil {
+					v.SetInt(int64(int8(b)))
+				}
+				return err
+			}, func(bout Writer, v reflect.Value) error {
+				return bout.WriteByte(byte(v.Int()))
+			}, nil
+
+	case reflect.Uint16:
+		return func(bin Reader, v reflect.Value) error {
+				n, err := r16(bin)
+				if err == nil {
+					v.SetUint(uint64(n))
+				}
+				return err
+			}, func(bout Writer, v reflect.Value) error {
+				return w16(bout, uint16(v.Uint()))
+			}, nil
+
+	case reflect.Int16:
+		return func(bin Reader, v reflect.Value) error {
+				n, err := r16(bin)
+				if err == nil {
+					v.SetInt(int64(int16(n)))
+				}
+				return err
+			}, func(bout Writer, v reflect.Value) error {
+				return w16(bout, uint16(v.Int()))
+			}, nil
+
+	case reflect.Uint32:
+		return func(bin Reader, v reflect.Value) error {
+				n, err := r32(bin)
+				if err == nil {
+					v.SetUint(uint64(n))
+				}
+				return err
+			}, func(bout Writer, v reflect.Value) error {
+				return w32(bout, uint32(v.Uint()))
+			}, nil
+
+	case reflect.Int32:
+		return func(bin Reader, v reflect.Value) error {
+				n, err := r32(bin)
+				if err == nil {
+					v.SetInt(int64(int32(n)))
+				}
+				return err
+			}, func(bout Writer, v reflect.Value) error {
+				return w32(bout, uint32(v.Int()))
+			}, nil
+
+	case reflect.Float32:
+		return func(bin Reader, v reflect.Value) error {
+				n, err := r32(bin)
+				if err == nil {
+					v.SetFloat(float64(math.Float32frombits(n)))
+				}
+				return err
+			}, func(bout Writer, v reflect.Value) error {
+				return w32(bout, math.Float32bits(float32(v.Float())))
+			}, nil
+
+	case reflect.Uint64:
+		return func(bin Reader, v reflect.Value) error {
+				n, err := r64(bin)
+				if err == nil {
+					v.SetUint(n)
+				}
+				return err
+			}, func(bout Writer, v reflect.Value) error {
+				return w64(bout, v.Uint())
+			}, nil
+
+	case reflect.Int64:
+		return func(bin Reader, v reflect.Value) error {
+				n, err := r64(bin)
+				if err == nil {
+					v.SetInt(int64(n))
+				}
+				return err
+			}, func(bout Writer, v reflect.Value) error {
+				return w64(bout, uint64(v.Int()))
+			}, nil
+
+	case reflect.Float64:
+		return func(bin Reader, v reflect.Value) error {
+				n, err := r64(bin)
+				if err == nil {
+					v.SetFloat(math.Float64frombits(n))
+				}
+				return err
+			}, func(bout Writer, v reflect.Value) error {
+				return w64(bout, math.Float64bits(v.Float()))
+			}, nil
+
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return func(bin Reader, v reflect.Value) error {
+					return readFull(bin, v.Slice(0, v.Len()).Bytes())
+				}, func(bout Writer, v reflect.Value) error {
+					_, err := bout.Write(v.Slice(0, v.Len()).Bytes())
+					return err
+				}, nil
+		}
+		dec, enc, err := codecFor(t.Elem(), le)
+		if err != nil {
+			return nil, nil, err
+		}
+		return func(bin Reader, v reflect.Value) error {
+				return decodeElems(bin, v, dec)
+			}, func(bout Writer, v reflect.Value) error {
+				return encodeElems(bout, v, enc)
+			}, nil
+
+	case reflect.Struct:
+		// plans are looked up lazily so that self-referential types
+		// (via counted slices) do not recurse at build time
+		return func(bin Reader, v reflect.Value) error {
+				plan, err := planFor(t)
+				if err != nil {
+					return err
+				}
+				return plan.decode(bin, v)
+			}, func(bout Writer, v reflect.Value) error {
+				plan, err := planFor(t)
+				if err != nil {
+					return err
+				}
+				return plan.encode(bout, v)
+			}, nil
+	}
+
+	return nil, nil, &UnsupportedTypeError{Type: t}
+}
+
+// countedCodecFor returns the decode and encode functions for a slice or
+// string whose length is given by a len= field.
+func countedCodecFor(t reflect.Type, le bool) (decodeNFunc, encodeFunc, error) {
+	if t.Kind() == reflect.String {
+		return func(bin Reader, v reflect.Value, n int) error {
+				buf, err := ReadBytes(bin, n)
+				if err == nil {
+					v.SetString(string(buf))
+				}
+				return err
+			}, func(bout Writer, v reflect.Value) error {
+				_, err := io.WriteString(bout, v.String())
+				return err
+			}, nil
+	}
+
+	if t.Elem().Kind() == reflect.Uint8 {
+		return func(bin Reader, v reflect.Value, n int) error {
+				buf, err := ReadBytes(bin, n)
+				if err == nil {
+					v.SetBytes(buf)
+				}
+				return err
+			}, func(bout Writer, v reflect.Value) error {
+				_, err := bout.Write(v.Bytes())
+				return err
+			}, nil
+	}
+
+	dec, enc, err := codecFor(t.Elem(), le)
+	if err != nil {
+		return nil, nil, err
+	}
+	return func(bin Reader, v reflect.Value, n int) error {
+			// grow the slice as elements arrive, so that a hostile
+			// length does not cause a huge up-front allocation
+			s := reflect.MakeSlice(t, 0, minInt(n, 1024))
+			zero := reflect.Zero(t.Elem())
+			for i := 0; i < n; i++ {
+				s = reflect.Append(s, zero)
+				if err := dec(bin, s.Index(i)); err != nil {
+					return midRecord(i > 0, err)
+				}
+			}
+			v.Set(s)
+			return nil
+		}, func(bout Writer, v reflect.Value) error {
+			return encodeElems(bout, v, enc)
+		}, nil
+}
+
+func decodeElems(bin Reader, v reflect.Value, dec decodeFunc) error {
+	for i := 0; i < v.Len(); i++ {
+		if err := dec(bin, v.Index(i)); err != nil {
+			return midRecord(i > 0, err)
+		}
+	}
+	return nil
+}
+
+func encodeElems(bout Writer, v reflect.Value, enc encodeFunc) error {
+	for i := 0; i < v.Len(); i++ {
+		if err := enc(bout, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readFull fills buf from bin, returning io.EOF only if no bytes at all were
+// available.
+func readFull(bin Reader, buf []byte) error {
+	_, err := io.ReadFull(bin, buf)
+	return err
+}
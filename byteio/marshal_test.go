@@ -0,0 +1,234 @@
+package byteio_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+type marshalInner struct {
+	A uint16 `byteio:"le"`
+	B int8
+}
+
+type marshalHeader struct {
+	Magic   [4]byte
+	Version uint16
+	Flags   uint32 `byteio:"le"`
+	Offset  int64  `byteio:"be,skip=2"`
+	Ratio   float32
+	Scale   float64 `byteio:"le"`
+	Valid   bool
+	_       [3]byte
+	Inner   marshalInner
+	Pair    [2]int16 `byteio:"le"`
+	Count   uint8
+	Items   []uint32 `byteio:"le,len=Count"`
+	NameLen uint16
+	Name    string `byteio:"len=NameLen"`
+	DataLen int32  `byteio:"le"`
+	Data    []byte `byteio:"len=DataLen"`
+	Ignored int    `byteio:"-"`
+}
+
+// marshalHeaderBytes returns the expected encoding of the test value, built
+// with encoding/binary.
+func marshalHeaderBytes() []byte {
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString("HDR1")
+	binary.Write(buf, binary.BigEndian, uint16(3))
+	binary.Write(buf, binary.LittleEndian, uint32(0xDEADBEEF))
+	buf.Write([]byte{0, 0})
+	binary.Write(buf, binary.BigEndian, int64(-2))
+	binary.Write(buf, binary.BigEndian, float32(1.5))
+	binary.Write(buf, binary.LittleEndian, float64(-0.25))
+	buf.Write([]byte{1, 0, 0, 0})
+	binary.Write(buf, binary.LittleEndian, uint16(0x1234))
+	buf.WriteByte(0xFF)
+	binary.Write(buf, binary.LittleEndian, []int16{-1, 2})
+	buf.WriteByte(3)
+	binary.Write(buf, binary.LittleEndian, []uint32{1, 2, 3})
+	binary.Write(buf, binary.BigEndian, uint16(5))
+	buf.WriteString("hello")
+	binary.Write(buf, binary.LittleEndian, int32(2))
+	buf.Write([]byte{0xAA, 0xBB})
+	return buf.Bytes()
+}
+
+func marshalHeaderValue() marshalHeader {
+	return marshalHeader{
+		Magic:   [4]byte{'H', 'D', 'R', '1'},
+		Version: 3,
+		Flags:   0xDEADBEEF,
+		Offset:  -2,
+		Ratio:   1.5,
+		Scale:   -0.25,
+		Valid:   true,
+		Inner:   marshalInner{A: 0x1234, B: -1},
+		Pair:    [2]int16{-1, 2},
+		Count:   3,
+		Items:   []uint32{1, 2, 3},
+		NameLen: 5,
+		Name:    "hello",
+		DataLen: 2,
+		Data:    []byte{0xAA, 0xBB},
+	}
+}
+
+// TestUnmarshal decodes a record produced by encoding/binary.
+func TestUnmarshal(t *testing.T) {
+	var act marshalHeader
+	bin := bytes.NewReader(marshalHeaderBytes())
+	if err := byteio.Unmarshal(bin, &act); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exp := marshalHeaderValue(); !reflect.DeepEqual(act, exp) {
+		t.Errorf("act %+v ≠ exp %+v", act, exp)
+	}
+	if bin.Len() != 0 {
+		t.Errorf("%d bytes left unread", bin.Len())
+	}
+
+	// second decode should hit EOF cleanly
+	if err := byteio.Unmarshal(bin, &act); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+// TestMarshal encodes a record and compares it against encoding/binary.
+func TestMarshal(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	if err := byteio.Marshal(buf, marshalHeaderValue()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exp := marshalHeaderBytes(); !bytes.Equal(buf.Bytes(), exp) {
+		t.Errorf("act % X ≠ exp % X", buf.Bytes(), exp)
+	}
+}
+
+// TestUnmarshalShort ensures that a truncated record results in
+// io.ErrUnexpectedEOF at every truncation point.
+func TestUnmarshalShort(t *testing.T) {
+	full := marshalHeaderBytes()
+	for i := 1; i < len(full); i++ {
+		var v marshalHeader
+		err := byteio.Unmarshal(bytes.NewReader(full[:i]), &v)
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("%d bytes: unexpected error %v", i, err)
+		}
+	}
+}
+
+// TestMarshalErr ensures that write errors are propagated at every byte
+// position.
+func TestMarshalErr(t *testing.T) {
+	size := len(marshalHeaderBytes())
+	for i := 0; i < size; i++ {
+		err := byteio.Marshal(&AbortWriter{when: i}, marshalHeaderValue())
+		if err != ErrAbortWriter {
+			t.Errorf("%d bytes: unexpected error %v", i, err)
+		}
+	}
+}
+
+// TestMarshalLenMismatch ensures that a slice whose length differs from its
+// len= field is rejected.
+func TestMarshalLenMismatch(t *testing.T) {
+	v := marshalHeaderValue()
+	v.Count = 2
+	if err := byteio.Marshal(bytes.NewBuffer(nil), &v); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// TestMarshalBadTypes ensures that invalid struct definitions are reported.
+func TestMarshalBadTypes(t *testing.T) {
+	check := func(name string, v interface{}) {
+		if err := byteio.Marshal(bytes.NewBuffer(nil), v); err == nil {
+			t.Errorf("%s: Marshal did not return expected error", name)
+		}
+		ptr := reflect.New(reflect.TypeOf(v))
+		if err := byteio.Unmarshal(bytes.NewReader(make([]byte, 64)), ptr.Interface()); err == nil {
+			t.Errorf("%s: Unmarshal did not return expected error", name)
+		}
+	}
+
+	check("int", struct{ A int }{})
+	check("map", struct{ A map[string]string }{})
+	check("unexported", struct{ a uint8 }{})
+	check("no len", struct{ A []byte }{})
+	check("bad len", struct {
+		A []byte `byteio:"len=B"`
+		B uint8
+	}{})
+	check("float len", struct {
+		N float32
+		A []byte `byteio:"len=N"`
+	}{})
+	check("len on scalar", struct {
+		N uint8
+		A uint8 `byteio:"len=N"`
+	}{})
+	check("bad option", struct {
+		A uint8 `byteio:"middle"`
+	}{})
+	check("bad skip", struct {
+		A uint8 `byteio:"skip=x"`
+	}{})
+
+	if err := byteio.Unmarshal(bytes.NewReader(nil), marshalInner{}); err == nil {
+		t.Error("Unmarshal accepted non-pointer")
+	}
+	if err := byteio.Marshal(bytes.NewBuffer(nil), 42); err == nil {
+		t.Error("Marshal accepted non-struct")
+	}
+}
+
+// TestUnmarshalRecursive checks that a type containing a counted slice of
+// itself can be decoded.
+func TestUnmarshalRecursive(t *testing.T) {
+	type node struct {
+		Val      uint8
+		N        uint8
+		Children []node `byteio:"len=N"`
+	}
+
+	in := []byte{1, 2, 2, 0, 3, 1, 4, 0}
+	var act node
+	if err := byteio.Unmarshal(bytes.NewReader(in), &act); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exp := node{Val: 1, N: 2, Children: []node{
+		{Val: 2, Children: []node{}},
+		{Val: 3, N: 1, Children: []node{{Val: 4, Children: []node{}}}},
+	}}
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("act %+v ≠ exp %+v", act, exp)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := byteio.Marshal(buf, &act); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), in) {
+		t.Errorf("act % X ≠ exp % X", buf.Bytes(), in)
+	}
+}
+
+// BenchmarkUnmarshal measures the cost of decoding a record via the cached
+// plan.
+func BenchmarkUnmarshal(b *testing.B) {
+	in := marshalHeaderBytes()
+	bin := bytes.NewReader(in)
+	var v marshalHeader
+	for i := 0; i < b.N; i++ {
+		bin.Reset(in)
+		if err := byteio.Unmarshal(bin, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
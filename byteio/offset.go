@@ -0,0 +1,126 @@
+package byteio
+
+import (
+	"fmt"
+	"io"
+)
+
+// OffsetError records the stream offset at which a value reader failed. It is
+// returned by the package's value readers when reading from a CountingReader
+// whose WrapErrors field is set; Offset is that of the first byte of the value
+// being read.
+type OffsetError struct {
+	Offset int64
+	Err    error
+}
+
+func (e *OffsetError) Error() string {
+	return fmt.Sprintf("%v at byte 0x%x", e.Err, e.Offset)
+}
+
+// Unwrap returns the underlying error.
+func (e *OffsetError) Unwrap() error {
+	return e.Err
+}
+
+// readErr is called when a value reader fails after consuming n bytes of the
+// value. It applies midRecord if n > 0 (allowing io.EOF to propagate normally
+// before the first read), and wraps the result in an *OffsetError if requested
+// by a CountingReader. A clean io.EOF is never wrapped, so that loops reading
+// until io.EOF continue to work. Errors are also annotated in the output of a
+// TraceReader.
+func readErr(bin Reader, n int, err error) error {
+	err = midRecord(n > 0, err)
+	if err != io.EOF {
+		traceErr(bin, n, err)
+	}
+	if cr, ok := bin.(*CountingReader); ok && cr.WrapErrors && err != io.EOF {
+		return &OffsetError{Offset: cr.off - int64(n), Err: err}
+	}
+	return err
+}
+
+// CountingReader wraps a Reader, keeping track of the number of bytes
+// consumed through it.
+type CountingReader struct {
+	bin Reader
+	off int64
+
+	// WrapErrors, if set, causes the package's value readers to return
+	// errors wrapped in an *OffsetError.
+	WrapErrors bool
+}
+
+// NewCountingReader returns a CountingReader wrapping bin, starting at
+// offset 0.
+func NewCountingReader(bin Reader) *CountingReader {
+	return &CountingReader{bin: bin}
+}
+
+// Offset returns the number of bytes consumed so far.
+func (cr *CountingReader) Offset() int64 {
+	return cr.off
+}
+
+func (cr *CountingReader) Read(buf []byte) (int, error) {
+	n, err := cr.bin.Read(buf)
+	cr.off += int64(n)
+	return n, err
+}
+
+func (cr *CountingReader) ReadByte() (byte, error) {
+	b, err := cr.bin.ReadByte()
+	if err == nil {
+		cr.off++
+	}
+	return b, err
+}
+
+func (cr *CountingReader) ReadRune() (rune, int, error) {
+	r, size, err := cr.bin.ReadRune()
+	cr.off += int64(size)
+	return r, size, err
+}
+
+// CountingWriter wraps a Writer, keeping track of the number of bytes written
+// through it.
+type CountingWriter struct {
+	bout Writer
+	off  int64
+}
+
+// NewCountingWriter returns a CountingWriter wrapping bout, starting at
+// offset 0.
+func NewCountingWriter(bout Writer) *CountingWriter {
+	return &CountingWriter{bout: bout}
+}
+
+// Offset returns the number of bytes written so far.
+func (cw *CountingWriter) Offset() int64 {
+	return cw.off
+}
+
+func (cw *CountingWriter) Write(buf []byte) (int, error) {
+	n, err := cw.bout.Write(buf)
+	cw.off += int64(n)
+	return n, err
+}
+
+func (cw *CountingWriter) WriteByte(b byte) error {
+	err := cw.bout.WriteByte(b)
+	if err == nil {
+		cw.off++
+	}
+	return err
+}
+
+func (cw *CountingWriter) WriteRune(r rune) (int, error) {
+	n, err := cw.bout.WriteRune(r)
+	cw.off += int64(n)
+	return n, err
+}
+
+// Flush flushes the underlying writer, if it requires flushing.
+func (cw *CountingWriter) Flush() error {
+	return FlushIfNecessary(cw.bout)
+}
@@ -0,0 +1,121 @@
+package byteio_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// TestCountingReader checks that every read path advances the offset.
+func TestCountingReader(t *testing.T) {
+	cr := byteio.NewCountingReader(bytes.NewReader([]byte("ab€cdefgh")))
+	check := func(exp int64) {
+		t.Helper()
+		if act := cr.Offset(); act != exp {
+			t.Errorf("offset: act %d ≠ exp %d", act, exp)
+		}
+	}
+
+	check(0)
+	if _, err := cr.ReadByte(); err != nil {
+		t.Fatal(err)
+	}
+	check(1)
+	if _, err := cr.ReadByte(); err != nil {
+		t.Fatal(err)
+	}
+	check(2)
+	if r, _, err := cr.ReadRune(); err != nil || r != '€' {
+		t.Fatalf("ReadRune: %q, %v", r, err)
+	}
+	check(5)
+	if _, err := cr.Read(make([]byte, 4)); err != nil {
+		t.Fatal(err)
+	}
+	check(9)
+	if _, err := byteio.ReadUint16BE(cr); err != nil {
+		t.Fatal(err)
+	}
+	check(11)
+	if _, err := cr.ReadByte(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+	check(11)
+}
+
+// TestCountingReaderWrap ensures that value reader errors are wrapped with the
+// offset of the start of the value, except for a clean io.EOF.
+func TestCountingReaderWrap(t *testing.T) {
+	cr := byteio.NewCountingReader(bytes.NewReader(make([]byte, 7)))
+	cr.WrapErrors = true
+
+	if _, err := byteio.ReadUint32BE(cr); err != nil {
+		t.Fatal(err)
+	}
+	_, err := byteio.ReadUint32BE(cr)
+	var oerr *byteio.OffsetError
+	if !errors.As(err, &oerr) {
+		t.Fatalf("expected *OffsetError, got %v", err)
+	}
+	if oerr.Offset != 4 {
+		t.Errorf("OffsetError.Offset: act %d ≠ exp 4", oerr.Offset)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected wrapped io.ErrUnexpectedEOF, got %v", err)
+	}
+	if exp := "unexpected EOF at byte 0x4"; err.Error() != exp {
+		t.Errorf("Error(): act %q ≠ exp %q", err.Error(), exp)
+	}
+
+	if _, err = byteio.ReadUint64LE(cr); err != io.EOF {
+		t.Errorf("expected unwrapped io.EOF, got %v", err)
+	}
+
+	// without WrapErrors, errors are returned as before
+	cr = byteio.NewCountingReader(bytes.NewReader(make([]byte, 3)))
+	if _, err = byteio.ReadUint32LE(cr); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+// TestCountingWriter checks that every write path advances the offset.
+func TestCountingWriter(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	cw := byteio.NewCountingWriter(buf)
+
+	cw.WriteByte('a')
+	cw.WriteRune('€')
+	cw.Write([]byte("bcd"))
+	byteio.WriteUint64BE(cw, 0)
+	if act, exp := cw.Offset(), int64(buf.Len()); act != exp || exp != 15 {
+		t.Errorf("offset: act %d ≠ exp %d", act, exp)
+	}
+
+	cw = byteio.NewCountingWriter(&AbortWriter{when: 3})
+	if err := byteio.WriteUint32LE(cw, 0); err != ErrAbortWriter {
+		t.Errorf("unexpected error %v", err)
+	}
+	if cw.Offset() != 3 {
+		t.Errorf("offset after error: act %d ≠ exp 3", cw.Offset())
+	}
+}
+
+// TestCountingWriterFlush checks that Flush reaches the underlying writer.
+func TestCountingWriterFlush(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	cw := byteio.NewCountingWriter(bufio.NewWriter(buf))
+	cw.WriteByte(1)
+	if buf.Len() != 0 {
+		t.Fatal("bufio.Writer did not buffer")
+	}
+	if err := byteio.FlushIfNecessary(cw); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() != 1 {
+		t.Error("underlying writer was not flushed")
+	}
+}
@@ -0,0 +1,164 @@
+package byteio
+
+import "strconv"
+
+// Order specifies a byte order, for functions which allow the byte order to
+// be chosen at runtime. This is useful for formats such as TIFF or ELF which
+// declare their byte order in a header: a parser can select the Order once,
+// after reading the magic number, and use its methods thereafter.
+//
+// Only BigEndian and LittleEndian are valid. The methods, and the functions
+// taking an Order, treat any other value as BigEndian; check an Order decoded
+// from untrusted input before using it.
+type Order int
+
+const (
+	// BigEndian is big-endian (network) byte order.
+	BigEndian Order = iota
+
+	// LittleEndian is little-endian byte order.
+	LittleEndian
+)
+
+// Native, the byte order of the machine the program is running on, is
+// declared in order_big.go and order_little.go.
+
+func (o Order) String() string {
+	switch o {
+	case BigEndian:
+		return "BigEndian"
+	case LittleEndian:
+		return "LittleEndian"
+	}
+	return "Order(" + strconv.Itoa(int(o)) + ")"
+}
+
+// ReadUint16 reads an unsigned uint16 in byte order o.
+func (o Order) ReadUint16(bin Reader) (uint16, error) {
+	if o == LittleEndian {
+		return ReadUint16LE(bin)
+	}
+	return ReadUint16BE(bin)
+}
+
+// ReadInt16 reads a signed int16 in byte order o.
+func (o Order) ReadInt16(bin Reader) (int16, error) {
+	if o == LittleEndian {
+		return ReadInt16LE(bin)
+	}
+	return ReadInt16BE(bin)
+}
+
+// ReadUint32 reads an unsigned uint32 in byte order o.
+func (o Order) ReadUint32(bin Reader) (uint32, error) {
+	if o == LittleEndian {
+		return ReadUint32LE(bin)
+	}
+	return ReadUint32BE(bin)
+}
+
+// ReadInt32 reads a signed int32 in byte order o.
+func (o Order) ReadInt32(bin Reader) (int32, error) {
+	if o == LittleEndian {
+		return ReadInt32LE(bin)
+	}
+	return ReadInt32BE(bin)
+}
+
+// ReadUint64 reads an unsigned uint64 in byte order o.
+func (o Order) ReadUint64(bin Reader) (uint64, error) {
+	if o == LittleEndian {
+		return ReadUint64LE(bin)
+	}
+	return ReadUint64BE(bin)
+}
+
+// ReadInt64 reads a signed int64 in byte order o.
+func (o Order) ReadInt64(bin Reader) (int64, error) {
+	if o == LittleEndian {
+		return ReadInt64LE(bin)
+	}
+	return ReadInt64BE(bin)
+}
+
+// ReadFloat32 reads an IEEE-754 32-bit floating point number in byte order o.
+func (o Order) ReadFloat32(bin Reader) (float32, error) {
+	if o == LittleEndian {
+		return ReadFloat32LE(bin)
+	}
+	return ReadFloat32BE(bin)
+}
+
+// ReadFloat64 reads an IEEE-754 64-bit floating point number in byte order o.
+func (o Order) ReadFloat64(bin Reader) (float64, error) {
+	if o == LittleEndian {
+		return ReadFloat64LE(bin)
+	}
+	return ReadFloat64BE(bin)
+}
+
+// WriteUint16 writes an unsigned uint16 in byte order o.
+func (o Order) WriteUint16(bout Writer, n uint16) error {
+	if o == LittleEndian {
+		return WriteUint16LE(bout, n)
+	}
+	return WriteUint16BE(bout, n)
+}
+
+// WriteInt16 writes a signed int16 in byte order o.
+func (o Order) WriteInt16(bout Writer, i int16) error {
+	if o == LittleEndian {
+		return WriteInt16LE(bout, i)
+	}
+	return WriteInt16BE(bout, i)
+}
+
+// WriteUint32 writes an unsigned uint32 in byte order o.
+func (o Order) WriteUint32(bout Writer, n uint32) error {
+	if o == LittleEndian {
+		return WriteUint32LE(bout, n)
+	}
+	return WriteUint32BE(bout, n)
+}
+
+// WriteInt32 writes a signed int32 in byte order o.
+func (o Order) WriteInt32(bout Writer, i int32) error {
+	if o == LittleEndian {
+		return WriteInt32LE(bout, i)
+	}
+	return WriteInt32BE(bout, i)
+}
+
+// WriteUint64 writes an unsigned uint64 in byte order o.
+func (o Order) WriteUint64(bout Writer, n uint64) error {
+	if o == LittleEndian {
+		return WriteUint64LE(bout, n)
+	}
+	return WriteUint64BE(bout, n)
+}
+
+// WriteInt64 writes a signed int64 in byte order o.
+func (o Order) WriteInt64(bout Writer, i int64) error {
+	if o == LittleEndian {
+		return WriteInt64LE(bout, i)
+	}
+	return WriteInt64BE(bout, i)
+}
+
+// WriteFloat32 writes an IEEE-754 32-bit floating point number in byte
+// order o.
+func (o Order) WriteFloat32(bout Writer, f float32) error {
+	if o == LittleEndian {
+		return WriteFloat32LE(bout, f)
+	}
+	return WriteFloat32BE(bout, f)
+}
+
+// WriteFloat64 writes an IEEE-754 64-bit floating point number in byte
+// order o.
+func (o Order) WriteFloat64(bout Writer, f float64) error {
+	if o == LittleEndian {
+		return WriteFloat64LE(bout, f)
+	}
+	return WriteFloat64BE(bout, f)
+}
@@ -0,0 +1,6 @@
+//go:build 386 || amd64 || arm || arm64 || loong64 || mips64le || mips64p32le || mipsle || ppc64le || riscv || riscv64 || wasm
+
+package byteio
+
+// Native is the byte order of the machine the program is running on.
+const Native = LittleEndian
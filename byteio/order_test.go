@@ -0,0 +1,124 @@
+package byteio_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"unsafe"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// TestOrder checks that values written through Order's methods can be read
+// back with encoding/binary, and vice versa.
+func TestOrder(t *testing.T) {
+	checkErr := func(err error) {
+		if err != nil {
+			t.Fatalf("unexpected I/O error: %v", err)
+		}
+	}
+
+	for _, order := range []byteio.Order{byteio.BigEndian, byteio.LittleEndian} {
+		var bo binary.ByteOrder = binary.BigEndian
+		if order == byteio.LittleEndian {
+			bo = binary.LittleEndian
+		}
+
+		buf := bytes.NewBuffer(nil)
+		checkErr(order.WriteUint16(buf, 0x8102))
+		checkErr(order.WriteInt16(buf, -2))
+		checkErr(order.WriteUint32(buf, 0x81020304))
+		checkErr(order.WriteInt32(buf, -3))
+		checkErr(order.WriteUint64(buf, 0x8102030405060708))
+		checkErr(order.WriteInt64(buf, -4))
+		checkErr(order.WriteFloat32(buf, 1.5))
+		checkErr(order.WriteFloat64(buf, -0.25))
+
+		exp := bytes.NewBuffer(nil)
+		binary.Write(exp, bo, uint16(0x8102))
+		binary.Write(exp, bo, int16(-2))
+		binary.Write(exp, bo, uint32(0x81020304))
+		binary.Write(exp, bo, int32(-3))
+		binary.Write(exp, bo, uint64(0x8102030405060708))
+		binary.Write(exp, bo, int64(-4))
+		binary.Write(exp, bo, float32(1.5))
+		binary.Write(exp, bo, float64(-0.25))
+		if !bytes.Equal(buf.Bytes(), exp.Bytes()) {
+			t.Fatalf("%v: act % X ≠ exp % X", order, buf.Bytes(), exp.Bytes())
+		}
+
+		u16, err := order.ReadUint16(buf)
+		checkErr(err)
+		i16, err := order.ReadInt16(buf)
+		checkErr(err)
+		u32, err := order.ReadUint32(buf)
+		checkErr(err)
+		i32, err := order.ReadInt32(buf)
+		checkErr(err)
+		u64, err := order.ReadUint64(buf)
+		checkErr(err)
+		i64, err := order.ReadInt64(buf)
+		checkErr(err)
+		f32, err := order.ReadFloat32(buf)
+		checkErr(err)
+		f64, err := order.ReadFloat64(buf)
+		checkErr(err)
+
+		if u16 != 0x8102 || i16 != -2 || u32 != 0x81020304 || i32 != -3 ||
+			u64 != 0x8102030405060708 || i64 != -4 || f32 != 1.5 || f64 != -0.25 {
+			t.Errorf("%v: read back incorrect values %X %d %X %d %X %d %f %f",
+				order, u16, i16, u32, i32, u64, i64, f32, f64)
+		}
+	}
+}
+
+// TestOrderNative checks that Native matches the machine's byte order.
+func TestOrderNative(t *testing.T) {
+	x := uint32(0x01020304)
+	b := (*[4]byte)(unsafe.Pointer(&x))
+	exp := byteio.BigEndian
+	if b[0] == 0x04 {
+		exp = byteio.LittleEndian
+	}
+	if byteio.Native != exp {
+		t.Errorf("Native: act %v ≠ exp %v", byteio.Native, exp)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	byteio.Native.WriteFloat64(buf, math.Pi)
+	var act float64
+	copy((*[8]byte)(unsafe.Pointer(&act))[:], buf.Bytes())
+	if act != math.Pi {
+		t.Errorf("Native float64: act %f ≠ exp %f", act, math.Pi)
+	}
+}
+
+// TestOrderString checks the String method.
+func TestOrderString(t *testing.T) {
+	for _, c := range []struct {
+		o   byteio.Order
+		exp string
+	}{
+		{byteio.BigEndian, "BigEndian"},
+		{byteio.LittleEndian, "LittleEndian"},
+		{byteio.Order(7), "Order(7)"},
+	} {
+		if act := c.o.String(); act != c.exp {
+			t.Errorf("act %q ≠ exp %q", act, c.exp)
+		}
+	}
+}
+
+// TestOrderInvalid checks that an invalid Order is treated as BigEndian, as
+// documented.
+func TestOrderInvalid(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	byteio.Order(7).WriteUint32(buf, 0x01020304)
+	if exp := []byte{1, 2, 3, 4}; !bytes.Equal(buf.Bytes(), exp) {
+		t.Errorf("act % X ≠ exp % X", buf.Bytes(), exp)
+	}
+	if act, err := byteio.Order(7).ReadUint16(bytes.NewReader([]byte{1, 2})); err != nil || act != 0x0102 {
+		t.Errorf("act %X/%v ≠ exp 0102", act, err)
+	}
+}
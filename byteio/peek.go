@@ -0,0 +1,111 @@
+package byteio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+)
+
+// PeekReader is a Reader which supports lookahead. It is satisfied by
+// bufio.Reader.
+type PeekReader interface {
+	Reader
+
+	// Peek returns the next n bytes without advancing the reader.
+	Peek(n int) ([]byte, error)
+
+	// UnreadByte unreads the last byte read.
+	UnreadByte() error
+}
+
+// NewPeekReader adapts any Reader into a PeekReader, possibly returning a new
+// bufio.Reader. Since a new bufio.Reader may read ahead, bin must not be used
+// directly after calling this function.
+func NewPeekReader(bin Reader) PeekReader {
+	if pr, ok := bin.(PeekReader); ok {
+		return pr
+	}
+	return bufio.NewReader(bin)
+}
+
+// peek returns exactly n bytes of lookahead, returning io.EOF if none are
+// available and io.ErrUnexpectedEOF if only some are.
+func peek(pr PeekReader, n int) ([]byte, error) {
+	b, err := pr.Peek(n)
+	if len(b) < n {
+		if len(b) > 0 && err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+// PeekUint16BE returns the next big-endian (network) byte order uint16 without
+// consuming it.
+func PeekUint16BE(pr PeekReader) (uint16, error) {
+	b, err := peek(pr, 2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(b), nil
+}
+
+// PeekUint16LE returns the next little-endian byte order uint16 without
+// consuming it.
+func PeekUint16LE(pr PeekReader) (uint16, error) {
+	b, err := peek(pr, 2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint16(b), nil
+}
+
+// PeekUint32BE returns the next big-endian (network) byte order uint32 without
+// consuming it.
+func PeekUint32BE(pr PeekReader) (uint32, error) {
+	b, err := peek(pr, 4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
+
+// PeekUint32LE returns the next little-endian byte order uint32 without
+// consuming it.
+func PeekUint32LE(pr PeekReader) (uint32, error) {
+	b, err := peek(pr, 4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+// PeekUint64BE returns the next big-endian (network) byte order uint64 without
+// consuming it.
+func PeekUint64BE(pr PeekReader) (uint64, error) {
+	b, err := peek(pr, 8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// PeekUint64LE returns the next little-endian byte order uint64 without
+// consuming it.
+func PeekUint64LE(pr PeekReader) (uint64, error) {
+	b, err := peek(pr, 8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b), nil
+}
+
+// PeekByte returns the next byte without consuming it.
+func PeekByte(pr PeekReader) (byte, error) {
+	b, err := peek(pr, 1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
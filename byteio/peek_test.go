@@ -0,0 +1,95 @@
+package byteio_test
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+var _ byteio.PeekReader = (*bufio.Reader)(nil)
+
+// TestNewPeekReader checks that a bufio.Reader is returned unchanged and that
+// other readers are wrapped.
+func TestNewPeekReader(t *testing.T) {
+	orig := bufio.NewReader(bytes.NewReader(nil))
+	if pr := byteio.NewPeekReader(orig); pr != orig {
+		t.Errorf("NewPeekReader(%p) returned unexpected %p", orig, pr)
+	}
+
+	pr := byteio.NewPeekReader(bytes.NewReader(nil))
+	if _, ok := pr.(*bufio.Reader); !ok {
+		t.Errorf("NewPeekReader did not wrap to bufio.Reader (got %T)", pr)
+	}
+}
+
+// TestPeek checks that the Peek helpers return the correct value without
+// consuming any input.
+func TestPeek(t *testing.T) {
+	in := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+	pr := byteio.NewPeekReader(bytes.NewReader(in))
+
+	check := func(name string, act, exp interface{}, err error) {
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		} else if act != exp {
+			t.Errorf("%s: act %X ≠ exp %X", name, act, exp)
+		}
+	}
+
+	b, err := byteio.PeekByte(pr)
+	check("PeekByte", b, byte(0x01), err)
+	u16, err := byteio.PeekUint16BE(pr)
+	check("PeekUint16BE", u16, uint16(0x0102), err)
+	u16, err = byteio.PeekUint16LE(pr)
+	check("PeekUint16LE", u16, uint16(0x0201), err)
+	u32, err := byteio.PeekUint32BE(pr)
+	check("PeekUint32BE", u32, uint32(0x01020304), err)
+	u32, err = byteio.PeekUint32LE(pr)
+	check("PeekUint32LE", u32, uint32(0x04030201), err)
+	u64, err := byteio.PeekUint64BE(pr)
+	check("PeekUint64BE", u64, uint64(0x0102030405060708), err)
+	u64, err = byteio.PeekUint64LE(pr)
+	check("PeekUint64LE", u64, uint64(0x0807060504030201), err)
+
+	// nothing should have been consumed
+	u64, err = byteio.ReadUint64BE(pr)
+	check("ReadUint64BE", u64, uint64(0x0102030405060708), err)
+
+	// UnreadByte allows the last byte to be examined again
+	if err = pr.UnreadByte(); err != nil {
+		t.Fatalf("UnreadByte: unexpected error %v", err)
+	}
+	b, err = byteio.PeekByte(pr)
+	check("PeekByte", b, byte(0x08), err)
+}
+
+// TestPeekShort ensures that io.EOF is returned when no data is available and
+// io.ErrUnexpectedEOF when only part of a value is.
+func TestPeekShort(t *testing.T) {
+	pr := byteio.NewPeekReader(bytes.NewReader(nil))
+	if _, err := byteio.PeekUint32BE(pr); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+	if _, err := byteio.PeekByte(pr); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+
+	check := func(fn string, sz int, f func(pr byteio.PeekReader) error) {
+		for i := 1; i < sz; i++ {
+			pr := byteio.NewPeekReader(bytes.NewReader(make([]byte, i)))
+			if err := f(pr); err != io.ErrUnexpectedEOF {
+				t.Errorf("%s/%d: unexpected error %v", fn, i, err)
+			}
+		}
+	}
+
+	check("PeekUint16BE", 2, func(pr byteio.PeekReader) error { _, err := byteio.PeekUint16BE(pr); return err })
+	check("PeekUint16LE", 2, func(pr byteio.PeekReader) error { _, err := byteio.PeekUint16LE(pr); return err })
+	check("PeekUint32BE", 4, func(pr byteio.PeekReader) error { _, err := byteio.PeekUint32BE(pr); return err })
+	check("PeekUint32LE", 4, func(pr byteio.PeekReader) error { _, err := byteio.PeekUint32LE(pr); return err })
+	check("PeekUint64BE", 8, func(pr byteio.PeekReader) error { _, err := byteio.PeekUint64BE(pr); return err })
+	check("PeekUint64LE", 8, func(pr byteio.PeekReader) error { _, err := byteio.PeekUint64LE(pr); return err })
+}
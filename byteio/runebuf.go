@@ -0,0 +1,76 @@
+package byteio
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// runeBuf lets a wrapping Reader implement ReadRune on top of its
+// underlying reader's ReadByte, so that it sees exactly the bytes which make
+// up each rune. Bytes read beyond the end of an invalid UTF-8 sequence are
+// held back and returned by subsequent reads, and an error encountered while
+// reading ahead is deferred until the held-back bytes are consumed.
+type runeBuf struct {
+	buf [utf8.UTFMax]byte
+	n   int
+	err error
+}
+
+// takeErr returns and clears any deferred error.
+func (rb *runeBuf) takeErr() error {
+	err := rb.err
+	rb.err = nil
+	return err
+}
+
+// readByte returns the next held-back byte, or else the next byte from br.
+func (rb *runeBuf) readByte(br io.ByteReader) (byte, error) {
+	if rb.n > 0 {
+		b := rb.buf[0]
+		copy(rb.buf[:], rb.buf[1:rb.n])
+		rb.n--
+		return b, nil
+	}
+	if rb.err != nil {
+		return 0, rb.takeErr()
+	}
+	return br.ReadByte()
+}
+
+// read returns held-back bytes if there are any, or else reads from r.
+func (rb *runeBuf) read(r io.Reader, p []byte) (int, error) {
+	if rb.n > 0 {
+		n := copy(p, rb.buf[:rb.n])
+		copy(rb.buf[:], rb.buf[n:rb.n])
+		rb.n -= n
+		return n, nil
+	}
+	if rb.err != nil {
+		return 0, rb.takeErr()
+	}
+	return r.Read(p)
+}
+
+// readRune decodes the next rune using held-back bytes and bytes from br. The
+// bytes making up the rune are returned in raw[:size].
+func (rb *runeBuf) readRune(br io.ByteReader) (r rune, size int,
+	raw [utf8.UTFMax]byte, err error) {
+	for rb.n < utf8.UTFMax && !utf8.FullRune(rb.buf[:rb.n]) && rb.err == nil {
+		b, err := br.ReadByte()
+		if err != nil {
+			rb.err = err
+			break
+		}
+		rb.buf[rb.n] = b
+		rb.n++
+	}
+	if rb.n == 0 {
+		return 0, 0, raw, rb.takeErr()
+	}
+
+	r, size = utf8.DecodeRune(rb.buf[:rb.n])
+	copy(raw[:], rb.buf[:size])
+	copy(rb.buf[:], rb.buf[size:rb.n])
+	rb.n -= size
+	return r, size, raw, nil
+}
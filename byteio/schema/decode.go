@@ -0,0 +1,266 @@
+package schema
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// DecodeError records the field whose decoding failed.
+type DecodeError struct {
+	Field string // path of the field, such as "points[2].x"
+	Err   error
+}
+
+func (e *DecodeError) Error() string {
+	return "schema: decoding " + e.Field + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Decode reads one record from bin. If the stream ends before the first byte
+// of the record, io.EOF is returned; any other error is returned as a
+// *DecodeError, with the stream ending part way through the record reported
+// as io.ErrUnexpectedEOF. If bin is a *byteio.TraceReader, each value in the
+// trace is labelled with its field's name.
+func (s *Schema) Decode(bin byteio.Reader) (map[string]interface{}, error) {
+	d := decoder{bin: bin}
+	d.tr, _ = bin.(*byteio.TraceReader)
+	return d.fields(s.fields, nil)
+}
+
+// scopeVal is the value of an integer field, as used by a len, count or if.
+type scopeVal struct {
+	v      uint64
+	signed bool
+}
+
+// scope holds the integer fields decoded so far in a struct.
+type scope struct {
+	parent *scope
+	vals   map[string]scopeVal
+}
+
+func (sc *scope) lookup(name string) (scopeVal, error) {
+	for ; sc != nil; sc = sc.parent {
+		if sv, ok := sc.vals[name]; ok {
+			return sv, nil
+		}
+	}
+	return scopeVal{}, fmt.Errorf("field %q is not present", name)
+}
+
+// eval returns the length or count given by sz.
+func (sz *size) eval(sc *scope) (int, error) {
+	if sz.ref == "" {
+		return sz.n, nil
+	}
+	sv, err := sc.lookup(sz.ref)
+	if err != nil {
+		return 0, err
+	}
+	if (sv.signed && int64(sv.v) < 0) || sv.v > uint64(int(^uint(0)>>1)) {
+		return 0, fmt.Errorf("field %q has invalid length %d",
+			sz.ref, int64(sv.v))
+	}
+	return int(sv.v), nil
+}
+
+// eval reports whether a conditional field is present.
+func (c *cond) eval(sc *scope) (bool, error) {
+	sv, err := sc.lookup(c.ref)
+	if err != nil {
+		return false, err
+	}
+	if c.mask != 0 {
+		return sv.v&c.mask != 0, nil
+	}
+	for _, v := range c.in {
+		if sv.v == v {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// decoder holds the state of a single call to Decode.
+type decoder struct {
+	bin     byteio.Reader
+	tr      *byteio.TraceReader
+	started bool // whether any bytes of the record have been read
+}
+
+// fields decodes the fields of a struct.
+func (d *decoder) fields(fields []*field,
+	parent *scope) (map[string]interface{}, error) {
+	sc := &scope{parent: parent, vals: make(map[string]scopeVal)}
+	out := make(map[string]interface{}, len(fields))
+	for _, f := range fields {
+		if f.cond != nil {
+			ok, err := f.cond.eval(sc)
+			if err != nil {
+				return nil, d.wrap(err, f.name)
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		if f.count == nil {
+			v, sv, err := d.value(f, sc)
+			if err != nil {
+				return nil, d.wrap(err, f.name)
+			}
+			if f.integer() {
+				sc.vals[f.name] = sv
+			}
+			if f.kind != kindPad {
+				out[f.name] = v
+			}
+			continue
+		}
+
+		n, err := f.count.eval(sc)
+		if err != nil {
+			return nil, d.wrap(err, f.name)
+		}
+		// grow as values arrive rather than trusting a count from the stream
+		arr := make([]interface{}, 0, minInt(n, 64))
+		for i := 0; i < n; i++ {
+			v, _, err := d.value(f, sc)
+			if err != nil {
+				return nil, d.wrap(err, f.name+"["+strconv.Itoa(i)+"]")
+			}
+			arr = append(arr, v)
+		}
+		out[f.name] = arr
+	}
+	return out, nil
+}
+
+// value decodes a single value of field f. For integer fields, the raw value
+// is also returned for use by later fields.
+func (d *decoder) value(f *field, sc *scope) (interface{}, scopeVal, error) {
+	if d.tr != nil && f.kind <= kindVarint {
+		d.tr.Label(f.name)
+	}
+
+	var (
+		v   interface{}
+		sv  scopeVal
+		n   int
+		err error
+	)
+	switch f.kind {
+	case kindUint, kindUvarint:
+		if f.kind == kindUint {
+			sv.v, err = byteio.ReadUintN(d.bin, f.width, f.order)
+		} else {
+			sv.v, err = byteio.ReadUvarint(d.bin)
+		}
+		v = sv.v
+
+	case kindInt, kindVarint:
+		var i int64
+		if f.kind == kindInt {
+			i, err = byteio.ReadIntN(d.bin, f.width, f.order)
+		} else {
+			i, err = byteio.ReadVarint(d.bin)
+		}
+		v, sv = i, scopeVal{v: uint64(i), signed: true}
+
+	case kindFloat:
+		v, err = d.float(f)
+
+	case kindBytes, kindString:
+		if n, err = f.len.eval(sc); err != nil {
+			return nil, sv, err
+		}
+		var buf []byte
+		if buf, err = byteio.ReadBytes(d.bin, n); f.kind == kindString {
+			v = string(buf)
+		} else {
+			v = buf
+		}
+
+	case kindCString:
+		v, err = byteio.ReadCString(d.bin, f.max)
+
+	case kindStruct:
+		v, err = d.fields(f.fields, sc)
+		return v, sv, err
+
+	case kindPad:
+		if n, err = f.len.eval(sc); err != nil {
+			return nil, sv, err
+		}
+		err = byteio.Skip(d.bin, int64(n))
+	}
+	if err != nil {
+		return nil, sv, err
+	}
+
+	if f.enum != nil {
+		if name, ok := f.enum[sv.v]; ok {
+			v = name
+		}
+	}
+	switch f.kind {
+	case kindBytes, kindString, kindPad:
+		d.started = d.started || n > 0
+	default:
+		d.started = true
+	}
+	return v, sv, nil
+}
+
+// float decodes a floating point value of field f.
+func (d *decoder) float(f *field) (float64, error) {
+	switch f.width {
+	case 2:
+		var (
+			h   float32
+			err error
+		)
+		if f.order == byteio.LittleEndian {
+			h, err = byteio.ReadFloat16LE(d.bin)
+		} else {
+			h, err = byteio.ReadFloat16BE(d.bin)
+		}
+		return float64(h), err
+	case 4:
+		s, err := f.order.ReadFloat32(d.bin)
+		return float64(s), err
+	}
+	return f.order.ReadFloat64(d.bin)
+}
+
+// wrap annotates an error with the name of the field being decoded. A clean
+// io.EOF before the start of the record is returned unchanged, while one part
+// way through becomes io.ErrUnexpectedEOF. Errors from nested structs already
+// carry the rest of the path.
+func (d *decoder) wrap(err error, name string) error {
+	if err == io.EOF {
+		if !d.started {
+			return err
+		}
+		err = io.ErrUnexpectedEOF
+	}
+	if de, ok := err.(*DecodeError); ok {
+		de.Field = name + "." + de.Field
+		return de
+	}
+	return &DecodeError{Field: name, Err: err}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,233 @@
+package schema_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+	"github.com/lwithers/pkg/byteio/schema"
+)
+
+const packetSchema = `{
+	"order": "be",
+	"enums": {
+		"kind": {"hello": 1, "data": 2, "bye": 3}
+	},
+	"fields": [
+		{"name": "magic", "type": "uint32"},
+		{"name": "kind", "type": "uint8", "enum": "kind"},
+		{"name": "flags", "type": "uint8"},
+		{"name": "seq", "type": "uint16", "order": "le"},
+		{"name": "ts", "type": "uint48", "if": {"field": "flags", "mask": 1}},
+		{"type": "pad", "len": 2},
+		{"name": "n", "type": "uint8"},
+		{"name": "points", "type": "struct", "count": "n", "fields": [
+			{"name": "x", "type": "int16"},
+			{"name": "y", "type": "int16"}
+		]},
+		{"name": "body", "type": "bytes", "len": "n",
+			"if": {"field": "kind", "in": [2]}},
+		{"name": "host", "type": "cstring", "max": 255}
+	]
+}`
+
+// mustParse compiles a schema, failing the test on error.
+func mustParse(t *testing.T, js string) *schema.Schema {
+	t.Helper()
+	s, err := schema.Parse([]byte(js))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// TestDecodePacket decodes the example from the package documentation, once
+// with all its optional fields and once without.
+func TestDecodePacket(t *testing.T) {
+	s := mustParse(t, packetSchema)
+	in := []byte{
+		0xCA, 0xFE, 0xBA, 0xBE, // magic
+		0x02,       // kind
+		0x01,       // flags
+		0x34, 0x12, // seq
+		0x00, 0x00, 0x01, 0x02, 0x03, 0x04, // ts
+		0xFF, 0xFF, // pad
+		0x02,                   // n
+		0x00, 0x01, 0xFF, 0xFE, // points[0]
+		0x00, 0x03, 0x00, 0x04, // points[1]
+		'h', 'i', // body
+		'a', 'b', 0, // host
+		// second packet
+		0xCA, 0xFE, 0xBA, 0xBE, 0x07, 0x00, 0x00, 0x00, 0xFF, 0xFF, 0x00, 0,
+	}
+	bin := bytes.NewReader(in)
+
+	act, err := s.Decode(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string]interface{}{
+		"magic": uint64(0xCAFEBABE),
+		"kind":  "data",
+		"flags": uint64(1),
+		"seq":   uint64(0x1234),
+		"ts":    uint64(0x01020304),
+		"n":     uint64(2),
+		"points": []interface{}{
+			map[string]interface{}{"x": int64(1), "y": int64(-2)},
+			map[string]interface{}{"x": int64(3), "y": int64(4)},
+		},
+		"body": []byte("hi"),
+		"host": "ab",
+	}
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("act %#v ≠ exp %#v", act, exp)
+	}
+
+	act, err = s.Decode(bin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp = map[string]interface{}{
+		"magic":  uint64(0xCAFEBABE),
+		"kind":   uint64(7),
+		"flags":  uint64(0),
+		"seq":    uint64(0),
+		"n":      uint64(0),
+		"points": []interface{}{},
+		"host":   "",
+	}
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("act %#v ≠ exp %#v", act, exp)
+	}
+
+	if _, err = s.Decode(bin); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+// TestDecodeTypes checks the remaining field types, arrays of plain values,
+// and lengths taken from signed fields of an enclosing struct.
+func TestDecodeTypes(t *testing.T) {
+	s := mustParse(t, `{"order": "le", "fields": [
+		{"name": "u24", "type": "uint24"},
+		{"name": "i48", "type": "int48", "order": "be"},
+		{"name": "h", "type": "float16"},
+		{"name": "f", "type": "float32", "order": "be"},
+		{"name": "d", "type": "float64"},
+		{"name": "len", "type": "varint"},
+		{"name": "inner", "type": "struct", "order": "be", "fields": [
+			{"name": "s", "type": "string", "len": "len"},
+			{"name": "v", "type": "uint16", "count": 2}
+		]},
+		{"name": "uv", "type": "uvarint"}
+	]}`)
+	in := []byte{
+		0x01, 0x02, 0x03, // u24
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFE, // i48
+		0x00, 0x3C, // h
+		0x3F, 0xC0, 0x00, 0x00, // f
+		0, 0, 0, 0, 0, 0, 0x00, 0xC0, // d
+		0x06,          // len
+		'a', 'b', 'c', // s
+		0x00, 0x01, 0x00, 0x02, // v
+		0xAC, 0x02, // uv
+	}
+	act, err := s.Decode(bytes.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string]interface{}{
+		"u24": uint64(0x030201),
+		"i48": int64(-2),
+		"h":   float64(1),
+		"f":   float64(1.5),
+		"d":   float64(-2),
+		"len": int64(3),
+		"inner": map[string]interface{}{
+			"s": "abc",
+			"v": []interface{}{uint64(1), uint64(2)},
+		},
+		"uv": uint64(300),
+	}
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("act %#v ≠ exp %#v", act, exp)
+	}
+}
+
+// TestDecodeErrors checks that errors carry the path of the failing field,
+// and that the stream ending part way through a record is distinguished from
+// it ending cleanly.
+func TestDecodeErrors(t *testing.T) {
+	s := mustParse(t, packetSchema)
+	for _, tc := range []struct {
+		in    []byte
+		field string
+		err   error
+	}{
+		{[]byte{0xCA, 0xFE}, "magic", io.ErrUnexpectedEOF},
+		{[]byte{0xCA, 0xFE, 0xBA, 0xBE}, "kind", io.ErrUnexpectedEOF},
+		{[]byte{0xCA, 0xFE, 0xBA, 0xBE, 2, 0, 0, 0, 0, 0, 2, 0, 1, 0},
+			"points[0].y", io.ErrUnexpectedEOF},
+		{[]byte{0xCA, 0xFE, 0xBA, 0xBE, 2, 0, 0, 0, 0, 0, 0, 'x'},
+			"host", io.ErrUnexpectedEOF},
+	} {
+		_, err := s.Decode(bytes.NewReader(tc.in))
+		var de *schema.DecodeError
+		if !errors.As(err, &de) || de.Field != tc.field || !errors.Is(err, tc.err) {
+			t.Errorf("% X: act %v ≠ exp %s: %v", tc.in, err, tc.field, tc.err)
+		}
+	}
+
+	s = mustParse(t, `{"fields": [
+		{"name": "n", "type": "int8"},
+		{"name": "b", "type": "bytes", "len": "n"}
+	]}`)
+	_, err := s.Decode(bytes.NewReader([]byte{0xFF}))
+	if err == nil || !strings.Contains(err.Error(), `decoding b: field "n" has invalid length -1`) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	s = mustParse(t, `{"fields": [
+		{"name": "f", "type": "uint8"},
+		{"name": "n", "type": "uint8", "if": {"field": "f", "mask": 1}},
+		{"name": "b", "type": "bytes", "len": "n"}
+	]}`)
+	_, err = s.Decode(bytes.NewReader([]byte{0x00}))
+	if err == nil || !strings.Contains(err.Error(), `decoding b: field "n" is not present`) {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// a record beginning with an empty field has not started
+	s = mustParse(t, `{"fields": [
+		{"type": "pad", "len": 0},
+		{"name": "v", "type": "uint8"}
+	]}`)
+	if _, err = s.Decode(bytes.NewReader(nil)); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+// TestDecodeTrace checks that values in a trace are labelled with their
+// field's name.
+func TestDecodeTrace(t *testing.T) {
+	s := mustParse(t, `{"fields": [
+		{"name": "length", "type": "uint16"},
+		{"name": "count", "type": "uvarint"}
+	]}`)
+	out := bytes.NewBuffer(nil)
+	tr := byteio.NewTraceReader(bytes.NewReader([]byte{0x00, 0x1A, 0x05}), out)
+	if _, err := s.Decode(tr); err != nil {
+		t.Fatal(err)
+	}
+	tr.Flush()
+	for _, exp := range []string{"uintN length = 0x1a", "uvarint count = 0x5"} {
+		if !strings.Contains(out.String(), exp) {
+			t.Errorf("trace missing %q:\n%s", exp, out.String())
+		}
+	}
+}
@@ -0,0 +1,408 @@
+/*
+Package schema decodes binary records whose layout is described by a small
+declarative schema loaded at runtime, rather than by Go struct tags. This
+allows new record formats to be supported without recompiling.
+
+A schema is a JSON document:
+
+	{
+		"order": "be",
+		"enums": {
+			"kind": {"hello": 1, "data": 2, "bye": 3}
+		},
+		"fields": [
+			{"name": "magic", "type": "uint32"},
+			{"name": "kind", "type": "uint8", "enum": "kind"},
+			{"name": "flags", "type": "uint8"},
+			{"name": "seq", "type": "uint16", "order": "le"},
+			{"name": "ts", "type": "uint48", "if": {"field": "flags", "mask": 1}},
+			{"type": "pad", "len": 2},
+			{"name": "n", "type": "uint8"},
+			{"name": "points", "type": "struct", "count": "n", "fields": [
+				{"name": "x", "type": "int16"},
+				{"name": "y", "type": "int16"}
+			]},
+			{"name": "body", "type": "bytes", "len": "n",
+				"if": {"field": "kind", "in": [2]}},
+			{"name": "host", "type": "cstring", "max": 255}
+		]
+	}
+
+The field types are:
+
+	uint8 uint16 uint24 uint32 uint48 uint64    unsigned integers
+	int8 int16 int24 int32 int48 int64          signed integers
+	float16 float32 float64                     IEEE 754 floats
+	uvarint varint                              as ReadUvarint, ReadVarint
+	bytes string                                "len" bytes
+	cstring                                     NUL-terminated, at most "max"
+	struct                                      nested "fields"
+	pad                                         "len" bytes, discarded
+
+Fixed-width values use the field's "order" ("be" or "le"), or else that of
+the innermost enclosing struct which sets one, or else the schema's, or else
+big-endian. "len" and "count" are either a number or the name of an integer
+field decoded earlier in the same struct or an enclosing one. "count" may be
+given on any field to decode an array of that many values. "if" makes a field
+conditional on an earlier integer field, either having any of the bits of
+"mask" set or being equal to one of the values listed in "in"; a field whose
+condition is false is absent from the result. "enum" names one of the
+schema's enums, mapping integer values to names.
+
+Decoding produces a map[string]interface{}: unsigned integers are uint64,
+signed integers int64, floats float64, bytes []byte, strings string, structs
+map[string]interface{} and arrays []interface{}. Integer fields with an enum
+decode to the name of their value, or the integer itself if it has no name.
+Pad fields are not included.
+
+Decoding into Go structs is not provided here; for that, describe the record
+with struct tags and use byteio.Unmarshal.
+*/
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// defaultMax is the maximum length of a cstring field which does not specify
+// one.
+const defaultMax = 4096
+
+// Schema is a compiled record layout. It is safe for concurrent use.
+type Schema struct {
+	fields []*field
+}
+
+// kind identifies how a field is decoded. The kinds of numeric values come
+// first.
+type kind int
+
+const (
+	kindUint kind = iota
+	kindInt
+	kindFloat
+	kindUvarint
+	kindVarint
+	kindBytes
+	kindString
+	kindCString
+	kindStruct
+	kindPad
+)
+
+// fieldTypes maps the type names accepted in a schema to their kind and, for
+// fixed-width values, width in bytes.
+var fieldTypes = map[string]struct {
+	kind  kind
+	width int
+}{
+	"uint8":   {kindUint, 1},
+	"uint16":  {kindUint, 2},
+	"uint24":  {kindUint, 3},
+	"uint32":  {kindUint, 4},
+	"uint48":  {kindUint, 6},
+	"uint64":  {kindUint, 8},
+	"int8":    {kindInt, 1},
+	"int16":   {kindInt, 2},
+	"int24":   {kindInt, 3},
+	"int32":   {kindInt, 4},
+	"int48":   {kindInt, 6},
+	"int64":   {kindInt, 8},
+	"float16": {kindFloat, 2},
+	"float32": {kindFloat, 4},
+	"float64": {kindFloat, 8},
+	"uvarint": {kindUvarint, 0},
+	"varint":  {kindVarint, 0},
+	"bytes":   {kindBytes, 0},
+	"string":  {kindString, 0},
+	"cstring": {kindCString, 0},
+	"struct":  {kindStruct, 0},
+	"pad":     {kindPad, 0},
+}
+
+// field is a compiled field description.
+type field struct {
+	name   string
+	kind   kind
+	width  int
+	order  byteio.Order
+	len    *size // bytes, string and pad
+	count  *size // non-nil for arrays
+	max    int   // cstring
+	enum   map[uint64]string
+	cond   *cond
+	fields []*field // struct
+}
+
+// integer reports whether the field's value may be referred to by a len,
+// count or if.
+func (f *field) integer() bool {
+	return f.count == nil && (f.kind == kindUint || f.kind == kindInt ||
+		f.kind == kindUvarint || f.kind == kindVarint)
+}
+
+// size is a length or count, either fixed or taken from an earlier field.
+type size struct {
+	ref string
+	n   int
+}
+
+// cond is the condition under which a field is present.
+type cond struct {
+	ref  string
+	mask uint64
+	in   []uint64
+}
+
+// The JSON representation of a schema.
+type (
+	schemaJSON struct {
+		Order  string                      `json:"order"`
+		Enums  map[string]map[string]int64 `json:"enums"`
+		Fields []fieldJSON                 `json:"fields"`
+	}
+
+	fieldJSON struct {
+		Name   string          `json:"name"`
+		Type   string          `json:"type"`
+		Order  string          `json:"order"`
+		Len    json.RawMessage `json:"len"`
+		Count  json.RawMessage `json:"count"`
+		Max    *int            `json:"max"`
+		Enum   string          `json:"enum"`
+		If     *condJSON       `json:"if"`
+		Fields []fieldJSON     `json:"fields"`
+	}
+
+	condJSON struct {
+		Field string  `json:"field"`
+		Mask  uint64  `json:"mask"`
+		In    []int64 `json:"in"`
+	}
+)
+
+// Parse compiles a schema from its JSON representation.
+func Parse(data []byte) (*Schema, error) {
+	return Load(bytes.NewReader(data))
+}
+
+// Load reads and compiles a schema from its JSON representation.
+func Load(r io.Reader) (*Schema, error) {
+	var sj schemaJSON
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&sj); err != nil {
+		return nil, fmt.Errorf("schema: %v", err)
+	}
+
+	order, err := parseOrder(sj.Order, byteio.BigEndian)
+	if err != nil {
+		return nil, fmt.Errorf("schema: %v", err)
+	}
+	c := compiler{enums: make(map[string]map[uint64]string, len(sj.Enums))}
+	for name, values := range sj.Enums {
+		e := make(map[uint64]string, len(values))
+		for vname, v := range values {
+			if prev, ok := e[uint64(v)]; ok {
+				if prev > vname {
+					prev, vname = vname, prev
+				}
+				return nil, fmt.Errorf("schema: enum %s: value %d "+
+					"is used by both %s and %s", name, v, prev, vname)
+			}
+			e[uint64(v)] = vname
+		}
+		c.enums[name] = e
+	}
+
+	fields, err := c.fields(sj.Fields, order, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{fields: fields}, nil
+}
+
+// compiler holds the state needed while compiling a schema.
+type compiler struct {
+	enums map[string]map[uint64]string
+}
+
+// scopeNames records the integer fields which may be referred to at a point
+// in the schema.
+type scopeNames struct {
+	parent *scopeNames
+	names  map[string]bool
+}
+
+func (sn *scopeNames) has(name string) bool {
+	for ; sn != nil; sn = sn.parent {
+		if sn.names[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// fields compiles the fields of a struct whose path is prefix.
+func (c *compiler) fields(fjs []fieldJSON, order byteio.Order, prefix string,
+	parent *scopeNames) ([]*field, error) {
+	sn := &scopeNames{parent: parent, names: make(map[string]bool)}
+	seen := make(map[string]bool, len(fjs))
+	fields := make([]*field, 0, len(fjs))
+	for i := range fjs {
+		fj := &fjs[i]
+		path := prefix + fj.Name
+		if fj.Name == "" {
+			path = prefix + "#" + strconv.Itoa(i)
+		}
+		f, err := c.field(fj, order, sn)
+		if err != nil {
+			return nil, fmt.Errorf("schema: field %s: %v", path, err)
+		}
+		if f.kind == kindStruct {
+			f.fields, err = c.fields(fj.Fields, f.order, path+".", sn)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if f.kind != kindPad {
+			if seen[f.name] {
+				return nil, fmt.Errorf("schema: field %s: duplicate name", path)
+			}
+			seen[f.name] = true
+		}
+		if f.integer() {
+			sn.names[f.name] = true
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// field compiles a single field, other than the fields of a struct, given
+// the referable fields so far.
+func (c *compiler) field(fj *fieldJSON, order byteio.Order,
+	sn *scopeNames) (*field, error) {
+	ft, ok := fieldTypes[fj.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q", fj.Type)
+	}
+	f := &field{name: fj.Name, kind: ft.kind, width: ft.width}
+	if f.name == "" && f.kind != kindPad {
+		return nil, fmt.Errorf("missing name")
+	}
+
+	var err error
+	if f.order, err = parseOrder(fj.Order, order); err != nil {
+		return nil, err
+	}
+
+	switch f.kind {
+	case kindBytes, kindString, kindPad:
+		if fj.Len == nil {
+			return nil, fmt.Errorf("%s requires len", fj.Type)
+		}
+		if f.len, err = parseSize(fj.Len, sn); err != nil {
+			return nil, fmt.Errorf("len: %v", err)
+		}
+	default:
+		if fj.Len != nil {
+			return nil, fmt.Errorf("%s does not take len", fj.Type)
+		}
+	}
+
+	if fj.Count != nil {
+		if f.kind == kindPad {
+			return nil, fmt.Errorf("pad does not take count")
+		}
+		if f.count, err = parseSize(fj.Count, sn); err != nil {
+			return nil, fmt.Errorf("count: %v", err)
+		}
+	}
+
+	if fj.Max != nil {
+		if f.kind != kindCString {
+			return nil, fmt.Errorf("%s does not take max", fj.Type)
+		}
+		if *fj.Max < 0 {
+			return nil, fmt.Errorf("negative max")
+		}
+		f.max = *fj.Max
+	} else {
+		f.max = defaultMax
+	}
+
+	if fj.Enum != "" {
+		if f.kind != kindUint && f.kind != kindInt &&
+			f.kind != kindUvarint && f.kind != kindVarint {
+			return nil, fmt.Errorf("enum on non-integer type %s", fj.Type)
+		}
+		if f.enum, ok = c.enums[fj.Enum]; !ok {
+			return nil, fmt.Errorf("unknown enum %q", fj.Enum)
+		}
+	}
+
+	if fj.If != nil {
+		if f.cond, err = parseCond(fj.If, sn); err != nil {
+			return nil, fmt.Errorf("if: %v", err)
+		}
+	}
+
+	if f.kind == kindStruct && len(fj.Fields) == 0 {
+		return nil, fmt.Errorf("struct requires fields")
+	} else if f.kind != kindStruct && fj.Fields != nil {
+		return nil, fmt.Errorf("%s does not take fields", fj.Type)
+	}
+	return f, nil
+}
+
+// parseOrder parses a byte order, returning def if s is empty.
+func parseOrder(s string, def byteio.Order) (byteio.Order, error) {
+	switch s {
+	case "":
+		return def, nil
+	case "be":
+		return byteio.BigEndian, nil
+	case "le":
+		return byteio.LittleEndian, nil
+	}
+	return def, fmt.Errorf("unknown byte order %q", s)
+}
+
+// parseSize parses a len or count, which is either a non-negative number or
+// the name of an integer field in scope.
+func parseSize(raw json.RawMessage, sn *scopeNames) (*size, error) {
+	var ref string
+	if err := json.Unmarshal(raw, &ref); err == nil {
+		if !sn.has(ref) {
+			return nil, fmt.Errorf("no integer field %q before this one", ref)
+		}
+		return &size{ref: ref}, nil
+	}
+	var n int
+	if err := json.Unmarshal(raw, &n); err != nil || n < 0 {
+		return nil, fmt.Errorf("expected field name or non-negative integer, got %s", raw)
+	}
+	return &size{n: n}, nil
+}
+
+// parseCond parses the condition of a conditional field.
+func parseCond(cj *condJSON, sn *scopeNames) (*cond, error) {
+	if !sn.has(cj.Field) {
+		return nil, fmt.Errorf("no integer field %q before this one", cj.Field)
+	}
+	if (cj.Mask == 0) == (cj.In == nil) {
+		return nil, fmt.Errorf("exactly one of mask or in is required")
+	}
+	c := &cond{ref: cj.Field, mask: cj.Mask}
+	for _, v := range cj.In {
+		c.in = append(c.in, uint64(v))
+	}
+	return c, nil
+}
@@ -0,0 +1,74 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio/schema"
+)
+
+// TestParseErrors checks that invalid schemas are rejected with an error
+// naming the offending field.
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		json, exp string
+	}{
+		{`{"fields": [{"name": "a", "type": "uint12"}]}`,
+			`field a: unknown type "uint12"`},
+		{`{"fields": [{"type": "uint8"}]}`,
+			`field #0: missing name`},
+		{`{"fields": [{"name": "a", "type": "uint8"}, {"name": "a", "type": "int8"}]}`,
+			`field a: duplicate name`},
+		{`{"order": "middle", "fields": []}`,
+			`unknown byte order "middle"`},
+		{`{"fields": [{"name": "a", "type": "bytes"}]}`,
+			`field a: bytes requires len`},
+		{`{"fields": [{"name": "a", "type": "uint8", "len": 2}]}`,
+			`field a: uint8 does not take len`},
+		{`{"fields": [{"name": "a", "type": "bytes", "len": "n"}, {"name": "n", "type": "uint8"}]}`,
+			`field a: len: no integer field "n" before this one`},
+		{`{"fields": [{"name": "n", "type": "float32"}, {"name": "a", "type": "bytes", "len": "n"}]}`,
+			`field a: len: no integer field "n" before this one`},
+		{`{"fields": [{"name": "a", "type": "string", "len": -1}]}`,
+			`field a: len: expected field name or non-negative integer, got -1`},
+		{`{"fields": [{"type": "pad", "len": 1, "count": 2}]}`,
+			`field #0: pad does not take count`},
+		{`{"fields": [{"name": "a", "type": "uint8", "max": 2}]}`,
+			`field a: uint8 does not take max`},
+		{`{"fields": [{"name": "a", "type": "string", "len": 1, "enum": "e"}]}`,
+			`field a: enum on non-integer type string`},
+		{`{"fields": [{"name": "a", "type": "uint8", "enum": "e"}]}`,
+			`field a: unknown enum "e"`},
+		{`{"enums": {"e": {"x": 1, "y": 2, "z": 1}}, "fields": []}`,
+			`enum e: value 1 is used by both x and z`},
+		{`{"fields": [{"name": "f", "type": "uint8"}, {"name": "a", "type": "uint8", "if": {"field": "f"}}]}`,
+			`field a: if: exactly one of mask or in is required`},
+		{`{"fields": [{"name": "s", "type": "struct"}]}`,
+			`field s: struct requires fields`},
+		{`{"fields": [{"name": "s", "type": "struct", "fields": [{"name": "x", "type": "int7"}]}]}`,
+			`field s.x: unknown type "int7"`},
+		{`{"fields": [], "extra": 1}`,
+			`unknown field "extra"`},
+	} {
+		_, err := schema.Parse([]byte(tc.json))
+		if err == nil || !strings.Contains(err.Error(), tc.exp) {
+			t.Errorf("%s: act %v ≠ exp %q", tc.json, err, tc.exp)
+		}
+	}
+}
+
+// TestParseScope checks that len, count and if may refer to fields of an
+// enclosing struct.
+func TestParseScope(t *testing.T) {
+	_, err := schema.Parse([]byte(`{"fields": [
+		{"name": "n", "type": "uvarint"},
+		{"name": "s", "type": "struct", "fields": [
+			{"name": "a", "type": "bytes", "len": "n"},
+			{"name": "b", "type": "int8", "count": "n",
+				"if": {"field": "n", "in": [1, 2]}}
+		]}
+	]}`))
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,72 @@
+package byteio
+
+import (
+	"bufio"
+	"io"
+)
+
+// SeekReader is a Reader which can also seek. It is satisfied by bytes.Reader
+// and, via NewSeekReader, by any io.ReadSeeker such as os.File.
+type SeekReader interface {
+	Reader
+	io.Seeker
+}
+
+// NewSeekReader adapts any io.ReadSeeker into a SeekReader. If rs does not
+// already implement the interface, it is wrapped in a bufio.Reader whose
+// buffer is accounted for when seeking relative to the current position and
+// discarded on every other seek. As with NewReader, rs must not be used
+// directly after calling this function.
+func NewSeekReader(rs io.ReadSeeker) SeekReader {
+	if sr, ok := rs.(SeekReader); ok {
+		return sr
+	}
+	return &seekReader{Reader: bufio.NewReader(rs), rs: rs}
+}
+
+// seekReader buffers an io.ReadSeeker.
+type seekReader struct {
+	*bufio.Reader
+	rs io.ReadSeeker
+}
+
+func (s *seekReader) Seek(offset int64, whence int) (int64, error) {
+	if whence == io.SeekCurrent {
+		buffered := int64(s.Buffered())
+		if offset >= 0 && offset <= buffered {
+			// satisfy the seek (including the common offset=0 query
+			// of the current position) from the buffer
+			pos, err := s.rs.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return 0, err
+			}
+			s.Discard(int(offset))
+			return pos - buffered + offset, nil
+		}
+		offset -= buffered
+	}
+
+	pos, err := s.rs.Seek(offset, whence)
+	s.Reset(s.rs)
+	return pos, err
+}
+
+// ReadAt seeks sr to offset (relative to the start), calls fn to decode data
+// there, and then seeks back to the original position, even if fn fails. This
+// is convenient for following an offset read from a table. The first error
+// encountered is returned.
+func ReadAt(sr SeekReader, offset int64, fn func() error) error {
+	pos, err := sr.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err = sr.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	err = fn()
+	if _, serr := sr.Seek(pos, io.SeekStart); err == nil {
+		err = serr
+	}
+	return err
+}
@@ -0,0 +1,109 @@
+package byteio_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+var _ byteio.SeekReader = (*bytes.Reader)(nil)
+
+// plainSeeker hides all but the io.ReadSeeker methods, so that
+// NewSeekReader must wrap it.
+type plainSeeker struct {
+	io.ReadSeeker
+}
+
+// TestNewSeekReader checks that a bytes.Reader is returned unchanged and that
+// other io.ReadSeekers are wrapped.
+func TestNewSeekReader(t *testing.T) {
+	orig := bytes.NewReader(nil)
+	if sr := byteio.NewSeekReader(orig); sr != orig {
+		t.Errorf("NewSeekReader(%p) returned unexpected %p", orig, sr)
+	}
+	if sr := byteio.NewSeekReader(plainSeeker{orig}); sr == nil {
+		t.Error("NewSeekReader returned nil")
+	}
+}
+
+// TestSeekReader checks that seeking discards or accounts for buffered data
+// correctly for each whence value.
+func TestSeekReader(t *testing.T) {
+	in := make([]byte, 256)
+	for i := range in {
+		in[i] = byte(i)
+	}
+	sr := byteio.NewSeekReader(plainSeeker{bytes.NewReader(in)})
+
+	check := func(name string, expPos int64) {
+		pos, err := sr.Seek(0, io.SeekCurrent)
+		if err != nil || pos != expPos {
+			t.Fatalf("%s: position act %d/%v ≠ exp %d", name, pos, err, expPos)
+		}
+		if b, err := sr.ReadByte(); err != nil || int64(b) != expPos {
+			t.Fatalf("%s: byte act %X/%v ≠ exp %X", name, b, err, expPos)
+		}
+	}
+
+	sr.ReadByte() // fills the buffer
+	check("initial", 1)
+	sr.Seek(10, io.SeekCurrent) // within buffer
+	check("SeekCurrent forward", 12)
+	sr.Seek(-5, io.SeekCurrent)
+	check("SeekCurrent backward", 8)
+	sr.Seek(100, io.SeekStart)
+	check("SeekStart", 100)
+	sr.Seek(-16, io.SeekEnd)
+	check("SeekEnd", 240)
+
+	if _, err := sr.Seek(-1, io.SeekStart); err == nil {
+		t.Error("expected error seeking before start")
+	}
+}
+
+// TestReadAt follows an offset table and checks that the original position is
+// restored.
+func TestReadAt(t *testing.T) {
+	in := []byte{
+		0x00, 0x00, 0x00, 0x0A, // offset of first entry
+		0x00, 0x00, 0x00, 0x0C, // offset of second entry
+		0xFF, 0xFF,
+		0x12, 0x34,
+		0x56, 0x78,
+	}
+	sr := byteio.NewSeekReader(plainSeeker{bytes.NewReader(in)})
+
+	var vals []uint16
+	for i := 0; i < 2; i++ {
+		off, err := byteio.ReadUint32BE(sr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = byteio.ReadAt(sr, int64(off), func() error {
+			v, err := byteio.ReadUint16BE(sr)
+			vals = append(vals, v)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(vals) != 2 || vals[0] != 0x1234 || vals[1] != 0x5678 {
+		t.Errorf("act %X ≠ exp [1234 5678]", vals)
+	}
+	if b, err := sr.ReadByte(); err != nil || b != 0xFF {
+		t.Errorf("position not restored: act %X/%v ≠ exp FF", b, err)
+	}
+
+	// errors from fn are returned and the position is still restored
+	errFn := errors.New("fn failed")
+	if err := byteio.ReadAt(sr, 0, func() error { return errFn }); err != errFn {
+		t.Errorf("unexpected error %v", err)
+	}
+	if b, err := sr.ReadByte(); err != nil || b != 0xFF {
+		t.Errorf("position not restored: act %X/%v ≠ exp FF", b, err)
+	}
+}
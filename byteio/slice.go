@@ -0,0 +1,1043 @@
+package byteio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"unicode/utf8"
+)
+
+var errNegativeCount = errors.New("byteio: negative count")
+
+// SliceReader reads values directly from a byte slice. Since it is a concrete
+// type, its methods avoid the per-byte interface calls made by the
+// package-level functions, making it suitable for hot-path decoding of data
+// already held in memory. It has methods mirroring the package-level readers
+// of fixed-width numbers and slices of them, varints, length-prefixed bytes
+// and strings, delimited strings, and UTF-16 and UTF-32 strings; it also
+// implements Reader, so it may be passed to any function in this package.
+//
+// If a method returns an error, the position is left unchanged. As with the
+// package-level functions, io.EOF is returned if no data remains and
+// io.ErrUnexpectedEOF if only part of a value does. Methods returning []byte
+// return sub-slices of the underlying buffer rather than copies.
+type SliceReader struct {
+	buf []byte
+	off int
+}
+
+// NewSliceReader returns a SliceReader which reads from buf.
+func NewSliceReader(buf []byte) *SliceReader {
+	return &SliceReader{buf: buf}
+}
+
+// Reset causes the SliceReader to read from buf, from the start.
+func (r *SliceReader) Reset(buf []byte) {
+	r.buf = buf
+	r.off = 0
+}
+
+// Len returns the number of unread bytes.
+func (r *SliceReader) Len() int {
+	return len(r.buf) - r.off
+}
+
+// Offset returns the number of bytes read so far.
+func (r *SliceReader) Offset() int {
+	return r.off
+}
+
+// next consumes and returns the next n bytes.
+func (r *SliceReader) next(n int) ([]byte, error) {
+	if len(r.buf)-r.off < n {
+		if r.off == len(r.buf) {
+			return nil, io.EOF
+		}
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := r.buf[r.off : r.off+n : r.off+n]
+	r.off += n
+	return b, nil
+}
+
+// Bytes returns the next n bytes. The returned slice refers to the underlying
+// buffer rather than being a copy.
+func (r *SliceReader) Bytes(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, errNegativeCount
+	}
+	return r.next(n)
+}
+
+// Skip discards the next n bytes.
+func (r *SliceReader) Skip(n int) error {
+	_, err := r.Bytes(n)
+	return err
+}
+
+func (r *SliceReader) Read(buf []byte) (int, error) {
+	if r.off == len(r.buf) {
+		if len(buf) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	n := copy(buf, r.buf[r.off:])
+	r.off += n
+	return n, nil
+}
+
+func (r *SliceReader) ReadByte() (byte, error) {
+	if r.off == len(r.buf) {
+		return 0, io.EOF
+	}
+	b := r.buf[r.off]
+	r.off++
+	return b, nil
+}
+
+func (r *SliceReader) ReadRune() (rune, int, error) {
+	if r.off == len(r.buf) {
+		return 0, 0, io.EOF
+	}
+	ch, size := utf8.DecodeRune(r.buf[r.off:])
+	r.off += size
+	return ch, size, nil
+}
+
+// Uint8 reads a single byte.
+func (r *SliceReader) Uint8() (uint8, error) {
+	return r.ReadByte()
+}
+
+// Int8 reads a single byte as a signed integer.
+func (r *SliceReader) Int8() (int8, error) {
+	b, err := r.ReadByte()
+	return int8(b), err
+}
+
+// Uint16BE reads a uint16 in big-endian (network) byte order.
+func (r *SliceReader) Uint16BE() (uint16, error) {
+	b, err := r.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(b), nil
+}
+
+// Int16BE reads an int16 in big-endian (network) byte order.
+func (r *SliceReader) Int16BE() (int16, error) {
+	b, err := r.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return int16(binary.BigEndian.Uint16(b)), nil
+}
+
+// Uint24BE reads an unsigned 24-bit integer in big-endian (network) byte order.
+func (r *SliceReader) Uint24BE() (uint32, error) {
+	b, err := r.next(3)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2]), nil
+}
+
+// Int24BE reads a signed 24-bit integer in big-endian (network) byte order.
+func (r *SliceReader) Int24BE() (int32, error) {
+	n, err := r.Uint24BE()
+	return int32(n<<8) >> 8, err
+}
+
+// Uint32BE reads a uint32 in big-endian (network) byte order.
+func (r *SliceReader) Uint32BE() (uint32, error) {
+	b, err := r.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
+
+// Int32BE reads an int32 in big-endian (network) byte order.
+func (r *SliceReader) Int32BE() (int32, error) {
+	b, err := r.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.BigEndian.Uint32(b)), nil
+}
+
+// Float32BE reads a float32 in big-endian (network) byte order.
+func (r *SliceReader) Float32BE() (float32, error) {
+	b, err := r.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float32frombits(binary.BigEndian.Uint32(b)), nil
+}
+
+// Uint48BE reads an unsigned 48-bit integer in big-endian (network) byte order.
+func (r *SliceReader) Uint48BE() (uint64, error) {
+	b, err := r.next(6)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(b[0])<<40 | uint64(b[1])<<32 | uint64(b[2])<<24 |
+		uint64(b[3])<<16 | uint64(b[4])<<8 | uint64(b[5]), nil
+}
+
+// Int48BE reads a signed 48-bit integer in big-endian (network) byte order.
+func (r *SliceReader) Int48BE() (int64, error) {
+	n, err := r.Uint48BE()
+	return int64(n<<16) >> 16, err
+}
+
+// Uint64BE reads a uint64 in big-endian (network) byte order.
+func (r *SliceReader) Uint64BE() (uint64, error) {
+	b, err := r.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// Int64BE reads an int64 in big-endian (network) byte order.
+func (r *SliceReader) Int64BE() (int64, error) {
+	b, err := r.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+// Float64BE reads a float64 in big-endian (network) byte order.
+func (r *SliceReader) Float64BE() (float64, error) {
+	b, err := r.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+}
+
+// Float16BE reads an IEEE 754 binary16 value in big-endian (network) byte
+// order.
+func (r *SliceReader) Float16BE() (float32, error) {
+	b, err := r.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return Float16frombits(binary.BigEndian.Uint16(b)), nil
+}
+
+// BFloat16BE reads a bfloat16 value in big-endian (network) byte order.
+func (r *SliceReader) BFloat16BE() (float32, error) {
+	b, err := r.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return BFloat16frombits(binary.BigEndian.Uint16(b)), nil
+}
+
+// Uint16LE reads a uint16 in little-endian byte order.
+func (r *SliceReader) Uint16LE() (uint16, error) {
+	b, err := r.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint16(b), nil
+}
+
+// Int16LE reads an int16 in little-endian byte order.
+func (r *SliceReader) Int16LE() (int16, error) {
+	b, err := r.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return int16(binary.LittleEndian.Uint16(b)), nil
+}
+
+// Uint24LE reads an unsigned 24-bit integer in little-endian byte order.
+func (r *SliceReader) Uint24LE() (uint32, error) {
+	b, err := r.next(3)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(b[2])<<16 | uint32(b[1])<<8 | uint32(b[0]), nil
+}
+
+// Int24LE reads a signed 24-bit integer in little-endian byte order.
+func (r *SliceReader) Int24LE() (int32, error) {
+	n, err := r.Uint24LE()
+	return int32(n<<8) >> 8, err
+}
+
+// Uint32LE reads a uint32 in little-endian byte order.
+func (r *SliceReader) Uint32LE() (uint32, error) {
+	b, err := r.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+// Int32LE reads an int32 in little-endian byte order.
+func (r *SliceReader) Int32LE() (int32, error) {
+	b, err := r.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return int32(binary.LittleEndian.Uint32(b)), nil
+}
+
+// Float32LE reads a float32 in little-endian byte order.
+func (r *SliceReader) Float32LE() (float32, error) {
+	b, err := r.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float32frombits(binary.LittleEndian.Uint32(b)), nil
+}
+
+// Uint48LE reads an unsigned 48-bit integer in little-endian byte order.
+func (r *SliceReader) Uint48LE() (uint64, error) {
+	b, err := r.next(6)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(b[5])<<40 | uint64(b[4])<<32 | uint64(b[3])<<24 |
+		uint64(b[2])<<16 | uint64(b[1])<<8 | uint64(b[0]), nil
+}
+
+// Int48LE reads a signed 48-bit integer in little-endian byte order.
+func (r *SliceReader) Int48LE() (int64, error) {
+	n, err := r.Uint48LE()
+	return int64(n<<16) >> 16, err
+}
+
+// Uint64LE reads a uint64 in little-endian byte order.
+func (r *SliceReader) Uint64LE() (uint64, error) {
+	b, err := r.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b), nil
+}
+
+// Int64LE reads an int64 in little-endian byte order.
+func (r *SliceReader) Int64LE() (int64, error) {
+	b, err := r.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.LittleEndian.Uint64(b)), nil
+}
+
+// Float64LE reads a float64 in little-endian byte order.
+func (r *SliceReader) Float64LE() (float64, error) {
+	b, err := r.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+}
+
+// Float16LE reads an IEEE 754 binary16 value in little-endian byte order.
+func (r *SliceReader) Float16LE() (float32, error) {
+	b, err := r.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return Float16frombits(binary.LittleEndian.Uint16(b)), nil
+}
+
+// BFloat16LE reads a bfloat16 value in little-endian byte order.
+func (r *SliceReader) BFloat16LE() (float32, error) {
+	b, err := r.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return BFloat16frombits(binary.LittleEndian.Uint16(b)), nil
+}
+
+// UintN reads an unsigned integer of width bytes (1 ≤ width ≤ 8) in the given
+// byte order.
+func (r *SliceReader) UintN(width int, order Order) (uint64, error) {
+	off := r.off
+	n, err := ReadUintN(r, width, order)
+	if err != nil {
+		r.off = off
+	}
+	return n, err
+}
+
+// IntN reads a signed integer of width bytes (1 ≤ width ≤ 8) in the given byte
+// order.
+func (r *SliceReader) IntN(width int, order Order) (int64, error) {
+	off := r.off
+	i, err := ReadIntN(r, width, order)
+	if err != nil {
+		r.off = off
+	}
+	return i, err
+}
+
+// Uvarint reads an unsigned LEB128 varint, as for ReadUvarint.
+func (r *SliceReader) Uvarint() (uint64, error) {
+	off := r.off
+	n, err := ReadUvarint(r)
+	if err != nil {
+		r.off = off
+	}
+	return n, err
+}
+
+// Varint reads a zigzag varint, as for ReadVarint.
+func (r *SliceReader) Varint() (int64, error) {
+	off := r.off
+	i, err := ReadVarint(r)
+	if err != nil {
+		r.off = off
+	}
+	return i, err
+}
+
+// SLEB128 reads a signed LEB128 varint, as for ReadSLEB128.
+func (r *SliceReader) SLEB128() (int64, error) {
+	off := r.off
+	i, err := ReadSLEB128(r)
+	if err != nil {
+		r.off = off
+	}
+	return i, err
+}
+
+// Until returns the bytes before the next occurrence of delim, consuming the
+// delimiter as well, as for ReadUntil.
+func (r *SliceReader) Until(delim byte, max int) ([]byte, error) {
+	max = maxInt(max, 0)
+	rest := r.buf[r.off:]
+	i := bytes.IndexByte(rest, delim)
+	switch {
+	case i >= 0 && i <= max:
+		r.off += i + 1
+		return rest[:i:i], nil
+	case len(rest) > max:
+		return nil, &LengthError{Length: uint64(max) + 1, Max: uint64(max)}
+	case len(rest) == 0:
+		return nil, io.EOF
+	}
+	return nil, io.ErrUnexpectedEOF
+}
+
+// CString reads a NUL-terminated string, as for ReadCString.
+func (r *SliceReader) CString(max int) (string, error) {
+	b, err := r.Until(0, max)
+	return string(b), err
+}
+
+// prefixed completes a length-prefixed read once the prefix n has been read
+// (with error err), restoring the position off on failure.
+func (r *SliceReader) prefixed(off int, n uint64, err error,
+	max int) ([]byte, error) {
+	var b []byte
+	if err == nil {
+		max = maxInt(max, 0)
+		if n > uint64(max) {
+			err = &LengthError{Length: n, Max: uint64(max)}
+		} else if b, err = r.next(int(n)); err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}
+	if err != nil {
+		r.off = off
+		return nil, err
+	}
+	return b, nil
+}
+
+// BytesU8 reads a byte slice prefixed by its length as a uint8, as for
+// ReadBytesU8.
+func (r *SliceReader) BytesU8(max int) ([]byte, error) {
+	off := r.off
+	n, err := r.ReadByte()
+	return r.prefixed(off, uint64(n), err, max)
+}
+
+// BytesU16BE reads a byte slice prefixed by its length as a big-endian
+// uint16, as for ReadBytesU16BE.
+func (r *SliceReader) BytesU16BE(max int) ([]byte, error) {
+	off := r.off
+	n, err := r.Uint16BE()
+	return r.prefixed(off, uint64(n), err, max)
+}
+
+// BytesU16LE reads a byte slice prefixed by its length as a little-endian
+// uint16, as for ReadBytesU16LE.
+func (r *SliceReader) BytesU16LE(max int) ([]byte, error) {
+	off := r.off
+	n, err := r.Uint16LE()
+	return r.prefixed(off, uint64(n), err, max)
+}
+
+// BytesU32BE reads a byte slice prefixed by its length as a big-endian
+// uint32, as for ReadBytesU32BE.
+func (r *SliceReader) BytesU32BE(max int) ([]byte, error) {
+	off := r.off
+	n, err := r.Uint32BE()
+	return r.prefixed(off, uint64(n), err, max)
+}
+
+// BytesU32LE reads a byte slice prefixed by its length as a little-endian
+// uint32, as for ReadBytesU32LE.
+func (r *SliceReader) BytesU32LE(max int) ([]byte, error) {
+	off := r.off
+	n, err := r.Uint32LE()
+	return r.prefixed(off, uint64(n), err, max)
+}
+
+// StringU8 reads a string prefixed by its length as a uint8, as for
+// ReadStringU8.
+func (r *SliceReader) StringU8(max int) (string, error) {
+	b, err := r.BytesU8(max)
+	return string(b), err
+}
+
+// StringU16BE reads a string prefixed by its length as a big-endian uint16,
+// as for ReadStringU16BE.
+func (r *SliceReader) StringU16BE(max int) (string, error) {
+	b, err := r.BytesU16BE(max)
+	return string(b), err
+}
+
+// StringU16LE reads a string prefixed by its length as a little-endian
+// uint16, as for ReadStringU16LE.
+func (r *SliceReader) StringU16LE(max int) (string, error) {
+	b, err := r.BytesU16LE(max)
+	return string(b), err
+}
+
+// StringU32BE reads a string prefixed by its length as a big-endian uint32,
+// as for ReadStringU32BE.
+func (r *SliceReader) StringU32BE(max int) (string, error) {
+	b, err := r.BytesU32BE(max)
+	return string(b), err
+}
+
+// StringU32LE reads a string prefixed by its length as a little-endian
+// uint32, as for ReadStringU32LE.
+func (r *SliceReader) StringU32LE(max int) (string, error) {
+	b, err := r.BytesU32LE(max)
+	return string(b), err
+}
+
+// UTF16String reads a UTF-16 string of nUnits code units in the given byte
+// order, as for ReadUTF16String.
+func (r *SliceReader) UTF16String(order Order, nUnits int) (string, error) {
+	off := r.off
+	str, err := ReadUTF16String(r, order, nUnits)
+	if err != nil {
+		r.off = off
+	}
+	return str, err
+}
+
+// UTF16ZString reads a NUL-terminated UTF-16 string in the given byte order,
+// as for ReadUTF16ZString.
+func (r *SliceReader) UTF16ZString(order Order, max int) (string, error) {
+	off := r.off
+	str, err := ReadUTF16ZString(r, order, max)
+	if err != nil {
+		r.off = off
+	}
+	return str, err
+}
+
+// UTF32String reads a UTF-32 string of n code points in the given byte order,
+// as for ReadUTF32String.
+func (r *SliceReader) UTF32String(order Order, n int) (string, error) {
+	off := r.off
+	str, err := ReadUTF32String(r, order, n)
+	if err != nil {
+		r.off = off
+	}
+	return str, err
+}
+
+// UTF32ZString reads a NUL-terminated UTF-32 string in the given byte order,
+// as for ReadUTF32ZString.
+func (r *SliceReader) UTF32ZString(order Order, max int) (string, error) {
+	off := r.off
+	str, err := ReadUTF32ZString(r, order, max)
+	if err != nil {
+		r.off = off
+	}
+	return str, err
+}
+
+// Uint16sBE fills dst with unsigned uint16s in big-endian (network) byte
+// order, as for ReadUint16sBE.
+func (r *SliceReader) Uint16sBE(dst []uint16) error {
+	b, err := r.next(2 * len(dst))
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = binary.BigEndian.Uint16(b[2*i:])
+	}
+	return nil
+}
+
+// Int16sBE fills dst with signed int16s in big-endian (network) byte order, as
+// for ReadInt16sBE.
+func (r *SliceReader) Int16sBE(dst []int16) error {
+	b, err := r.next(2 * len(dst))
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = int16(binary.BigEndian.Uint16(b[2*i:]))
+	}
+	return nil
+}
+
+// Uint32sBE fills dst with unsigned uint32s in big-endian (network) byte
+// order, as for ReadUint32sBE.
+func (r *SliceReader) Uint32sBE(dst []uint32) error {
+	b, err := r.next(4 * len(dst))
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = binary.BigEndian.Uint32(b[4*i:])
+	}
+	return nil
+}
+
+// Int32sBE fills dst with signed int32s in big-endian (network) byte order, as
+// for ReadInt32sBE.
+func (r *SliceReader) Int32sBE(dst []int32) error {
+	b, err := r.next(4 * len(dst))
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = int32(binary.BigEndian.Uint32(b[4*i:]))
+	}
+	return nil
+}
+
+// Float32sBE fills dst with IEEE-754 32-bit floating point numbers in
+// big-endian (network) byte order, as for ReadFloat32sBE.
+func (r *SliceReader) Float32sBE(dst []float32) error {
+	b, err := r.next(4 * len(dst))
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = math.Float32frombits(binary.BigEndian.Uint32(b[4*i:]))
+	}
+	return nil
+}
+
+// Uint64sBE fills dst with unsigned uint64s in big-endian (network) byte
+// order, as for ReadUint64sBE.
+func (r *SliceReader) Uint64sBE(dst []uint64) error {
+	b, err := r.next(8 * len(dst))
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = binary.BigEndian.Uint64(b[8*i:])
+	}
+	return nil
+}
+
+// Int64sBE fills dst with signed int64s in big-endian (network) byte order, as
+// for ReadInt64sBE.
+func (r *SliceReader) Int64sBE(dst []int64) error {
+	b, err := r.next(8 * len(dst))
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = int64(binary.BigEndian.Uint64(b[8*i:]))
+	}
+	return nil
+}
+
+// Float64sBE fills dst with IEEE-754 64-bit floating point numbers in
+// big-endian (network) byte order, as for ReadFloat64sBE.
+func (r *SliceReader) Float64sBE(dst []float64) error {
+	b, err := r.next(8 * len(dst))
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = math.Float64frombits(binary.BigEndian.Uint64(b[8*i:]))
+	}
+	return nil
+}
+
+// Uint16sLE fills dst with unsigned uint16s in little-endian byte order, as
+// for ReadUint16sLE.
+func (r *SliceReader) Uint16sLE(dst []uint16) error {
+	b, err := r.next(2 * len(dst))
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return nil
+}
+
+// Int16sLE fills dst with signed int16s in little-endian byte order, as for
+// ReadInt16sLE.
+func (r *SliceReader) Int16sLE(dst []int16) error {
+	b, err := r.next(2 * len(dst))
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = int16(binary.LittleEndian.Uint16(b[2*i:]))
+	}
+	return nil
+}
+
+// Uint32sLE fills dst with unsigned uint32s in little-endian byte order, as
+// for ReadUint32sLE.
+func (r *SliceReader) Uint32sLE(dst []uint32) error {
+	b, err := r.next(4 * len(dst))
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = binary.LittleEndian.Uint32(b[4*i:])
+	}
+	return nil
+}
+
+// Int32sLE fills dst with signed int32s in little-endian byte order, as for
+// ReadInt32sLE.
+func (r *SliceReader) Int32sLE(dst []int32) error {
+	b, err := r.next(4 * len(dst))
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = int32(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return nil
+}
+
+// Float32sLE fills dst with IEEE-754 32-bit floating point numbers in
+// little-endian byte order, as for ReadFloat32sLE.
+func (r *SliceReader) Float32sLE(dst []float32) error {
+	b, err := r.next(4 * len(dst))
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return nil
+}
+
+// Uint64sLE fills dst with unsigned uint64s in little-endian byte order, as
+// for ReadUint64sLE.
+func (r *SliceReader) Uint64sLE(dst []uint64) error {
+	b, err := r.next(8 * len(dst))
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = binary.LittleEndian.Uint64(b[8*i:])
+	}
+	return nil
+}
+
+// Int64sLE fills dst with signed int64s in little-endian byte order, as for
+// ReadInt64sLE.
+func (r *SliceReader) Int64sLE(dst []int64) error {
+	b, err := r.next(8 * len(dst))
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = int64(binary.LittleEndian.Uint64(b[8*i:]))
+	}
+	return nil
+}
+
+// Float64sLE fills dst with IEEE-754 64-bit floating point numbers in
+// little-endian byte order, as for ReadFloat64sLE.
+func (r *SliceReader) Float64sLE(dst []float64) error {
+	b, err := r.next(8 * len(dst))
+	if err != nil {
+		return err
+	}
+	for i := range dst {
+		dst[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[8*i:]))
+	}
+	return nil
+}
+
+// SliceWriter appends values to a growable byte slice. Like SliceReader, it is
+// a concrete type intended for hot-path encoding, and it also implements
+// Writer. Since appending cannot fail, its methods do not return errors.
+type SliceWriter struct {
+	buf []byte
+}
+
+// NewSliceWriter returns a SliceWriter which appends to buf. Pass buf[:0] to
+// reuse an existing buffer's storage.
+func NewSliceWriter(buf []byte) *SliceWriter {
+	return &SliceWriter{buf: buf}
+}
+
+// Bytes returns the accumulated data. The slice is valid only until the next
+// write or Reset.
+func (w *SliceWriter) Bytes() []byte {
+	return w.buf
+}
+
+// Len returns the number of bytes accumulated.
+func (w *SliceWriter) Len() int {
+	return len(w.buf)
+}
+
+// Reset discards the accumulated data, retaining the underlying storage.
+func (w *SliceWriter) Reset() {
+	w.buf = w.buf[:0]
+}
+
+func (w *SliceWriter) Write(buf []byte) (int, error) {
+	w.buf = append(w.buf, buf...)
+	return len(buf), nil
+}
+
+func (w *SliceWriter) WriteByte(b byte) error {
+	w.buf = append(w.buf, b)
+	return nil
+}
+
+func (w *SliceWriter) WriteRune(r rune) (int, error) {
+	n := len(w.buf)
+	w.buf = append(w.buf, string(r)...)
+	return len(w.buf) - n, nil
+}
+
+// PutUint8 appends a single byte.
+func (w *SliceWriter) PutUint8(n uint8) {
+	w.buf = append(w.buf, n)
+}
+
+// PutInt8 appends a signed integer as a single byte.
+func (w *SliceWriter) PutInt8(i int8) {
+	w.buf = append(w.buf, byte(i))
+}
+
+// PutBytes appends buf.
+func (w *SliceWriter) PutBytes(buf []byte) {
+	w.buf = append(w.buf, buf...)
+}
+
+// PutUint16BE appends a uint16 in big-endian (network) byte order.
+func (w *SliceWriter) PutUint16BE(n uint16) {
+	w.buf = append(w.buf, byte(n>>8), byte(n))
+}
+
+// PutInt16BE appends an int16 in big-endian (network) byte order.
+func (w *SliceWriter) PutInt16BE(i int16) {
+	n := uint16(i)
+	w.buf = append(w.buf, byte(n>>8), byte(n))
+}
+
+// PutUint24BE appends the low 24 bits of n in big-endian (network) byte order.
+func (w *SliceWriter) PutUint24BE(n uint32) {
+	w.buf = append(w.buf, byte(n>>16), byte(n>>8), byte(n))
+}
+
+// PutInt24BE appends the low 24 bits of i in big-endian (network) byte order.
+func (w *SliceWriter) PutInt24BE(i int32) {
+	n := uint32(i)
+	w.buf = append(w.buf, byte(n>>16), byte(n>>8), byte(n))
+}
+
+// PutUint32BE appends a uint32 in big-endian (network) byte order.
+func (w *SliceWriter) PutUint32BE(n uint32) {
+	w.buf = append(w.buf, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+// PutInt32BE appends an int32 in big-endian (network) byte order.
+func (w *SliceWriter) PutInt32BE(i int32) {
+	n := uint32(i)
+	w.buf = append(w.buf, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+// PutFloat32BE appends a float32 in big-endian (network) byte order.
+func (w *SliceWriter) PutFloat32BE(f float32) {
+	n := math.Float32bits(f)
+	w.buf = append(w.buf, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+// PutUint48BE appends the low 48 bits of n in big-endian (network) byte order.
+func (w *SliceWriter) PutUint48BE(n uint64) {
+	w.buf = append(w.buf, byte(n>>40), byte(n>>32), byte(n>>24),
+		byte(n>>16), byte(n>>8), byte(n))
+}
+
+// PutInt48BE appends the low 48 bits of i in big-endian (network) byte order.
+func (w *SliceWriter) PutInt48BE(i int64) {
+	n := uint64(i)
+	w.buf = append(w.buf, byte(n>>40), byte(n>>32), byte(n>>24),
+		byte(n>>16), byte(n>>8), byte(n))
+}
+
+// PutUint64BE appends a uint64 in big-endian (network) byte order.
+func (w *SliceWriter) PutUint64BE(n uint64) {
+	w.buf = append(w.buf, byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32),
+		byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+// PutInt64BE appends an int64 in big-endian (network) byte order.
+func (w *SliceWriter) PutInt64BE(i int64) {
+	n := uint64(i)
+	w.buf = append(w.buf, byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32),
+		byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+// PutFloat64BE appends a float64 in big-endian (network) byte order.
+func (w *SliceWriter) PutFloat64BE(f float64) {
+	n := math.Float64bits(f)
+	w.buf = append(w.buf, byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32),
+		byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+}
+
+// PutFloat16BE appends f as an IEEE 754 binary16 value in big-endian (network)
+// byte order.
+func (w *SliceWriter) PutFloat16BE(f float32) {
+	n := Float16bits(f)
+	w.buf = append(w.buf, byte(n>>8), byte(n))
+}
+
+// PutBFloat16BE appends f as a bfloat16 value in big-endian (network) byte
+// order.
+func (w *SliceWriter) PutBFloat16BE(f float32) {
+	n := BFloat16bits(f)
+	w.buf = append(w.buf, byte(n>>8), byte(n))
+}
+
+// PutUint16LE appends a uint16 in little-endian byte order.
+func (w *SliceWriter) PutUint16LE(n uint16) {
+	w.buf = append(w.buf, byte(n), byte(n>>8))
+}
+
+// PutInt16LE appends an int16 in little-endian byte order.
+func (w *SliceWriter) PutInt16LE(i int16) {
+	n := uint16(i)
+	w.buf = append(w.buf, byte(n), byte(n>>8))
+}
+
+// PutUint24LE appends the low 24 bits of n in little-endian byte order.
+func (w *SliceWriter) PutUint24LE(n uint32) {
+	w.buf = append(w.buf, byte(n), byte(n>>8), byte(n>>16))
+}
+
+// PutInt24LE appends the low 24 bits of i in little-endian byte order.
+func (w *SliceWriter) PutInt24LE(i int32) {
+	n := uint32(i)
+	w.buf = append(w.buf, byte(n), byte(n>>8), byte(n>>16))
+}
+
+// PutUint32LE appends a uint32 in little-endian byte order.
+func (w *SliceWriter) PutUint32LE(n uint32) {
+	w.buf = append(w.buf, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+}
+
+// PutInt32LE appends an int32 in little-endian byte order.
+func (w *SliceWriter) PutInt32LE(i int32) {
+	n := uint32(i)
+	w.buf = append(w.buf, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+}
+
+// PutFloat32LE appends a float32 in little-endian byte order.
+func (w *SliceWriter) PutFloat32LE(f float32) {
+	n := math.Float32bits(f)
+	w.buf = append(w.buf, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+}
+
+// PutUint48LE appends the low 48 bits of n in little-endian byte order.
+func (w *SliceWriter) PutUint48LE(n uint64) {
+	w.buf = append(w.buf, byte(n), byte(n>>8), byte(n>>16),
+		byte(n>>24), byte(n>>32), byte(n>>40))
+}
+
+// PutInt48LE appends the low 48 bits of i in little-endian byte order.
+func (w *SliceWriter) PutInt48LE(i int64) {
+	n := uint64(i)
+	w.buf = append(w.buf, byte(n), byte(n>>8), byte(n>>16),
+		byte(n>>24), byte(n>>32), byte(n>>40))
+}
+
+// PutUint64LE appends a uint64 in little-endian byte order.
+func (w *SliceWriter) PutUint64LE(n uint64) {
+	w.buf = append(w.buf, byte(n), byte(n>>8), byte(n>>16), byte(n>>24),
+		byte(n>>32), byte(n>>40), byte(n>>48), byte(n>>56))
+}
+
+// PutInt64LE appends an int64 in little-endian byte order.
+func (w *SliceWriter) PutInt64LE(i int64) {
+	n := uint64(i)
+	w.buf = append(w.buf, byte(n), byte(n>>8), byte(n>>16), byte(n>>24),
+		byte(n>>32), byte(n>>40), byte(n>>48), byte(n>>56))
+}
+
+// PutFloat64LE appends a float64 in little-endian byte order.
+func (w *SliceWriter) PutFloat64LE(f float64) {
+	n := math.Float64bits(f)
+	w.buf = append(w.buf, byte(n), byte(n>>8), byte(n>>16), byte(n>>24),
+		byte(n>>32), byte(n>>40), byte(n>>48), byte(n>>56))
+}
+
+// PutFloat16LE appends f as an IEEE 754 binary16 value in little-endian byte
+// order.
+func (w *SliceWriter) PutFloat16LE(f float32) {
+	n := Float16bits(f)
+	w.buf = append(w.buf, byte(n), byte(n>>8))
+}
+
+// PutBFloat16LE appends f as a bfloat16 value in little-endian byte order.
+func (w *SliceWriter) PutBFloat16LE(f float32) {
+	n := BFloat16bits(f)
+	w.buf = append(w.buf, byte(n), byte(n>>8))
+}
+
+// PutUintN appends the low width bytes (1 ≤ width ≤ 8) of n in the given byte
+// order. An error is returned only if width is invalid.
+func (w *SliceWriter) PutUintN(width int, order Order, n uint64) error {
+	return WriteUintN(w, width, order, n)
+}
+
+// PutIntN appends the low width bytes (1 ≤ width ≤ 8) of i in the given byte
+// order. An error is returned only if width is invalid.
+func (w *SliceWriter) PutIntN(width int, order Order, i int64) error {
+	return WriteIntN(w, width, order, i)
+}
+
+// PutUvarint appends n as an unsigned LEB128 varint.
+func (w *SliceWriter) PutUvarint(n uint64) {
+	WriteUvarint(w, n)
+}
+
+// PutVarint appends i as a zigzag varint.
+func (w *SliceWriter) PutVarint(i int64) {
+	WriteVarint(w, i)
+}
+
+// PutSLEB128 appends i as a signed LEB128 varint.
+func (w *SliceWriter) PutSLEB128(i int64) {
+	WriteSLEB128(w, i)
+}
@@ -0,0 +1,379 @@
+package byteio_test
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+var (
+	_ byteio.Reader = (*byteio.SliceReader)(nil)
+	_ byteio.Writer = (*byteio.SliceWriter)(nil)
+)
+
+// TestSliceRoundTrip writes values with SliceWriter and checks that both
+// SliceReader and the package-level functions read them back.
+func TestSliceRoundTrip(t *testing.T) {
+	w := byteio.NewSliceWriter(nil)
+	w.PutUint8(0x01)
+	w.PutInt8(-2)
+	w.PutUint16BE(0x0304)
+	w.PutInt16LE(-0x0506)
+	w.PutUint24BE(0x070809)
+	w.PutInt24LE(-0x0A0B0C)
+	w.PutUint32LE(0x0D0E0F10)
+	w.PutInt32BE(-0x11121314)
+	w.PutFloat32BE(1.5)
+	w.PutUint48LE(0x151617181920)
+	w.PutInt48BE(-0x212223242526)
+	w.PutUint64BE(0x2728293031323334)
+	w.PutInt64LE(-0x3536373839404142)
+	w.PutFloat64LE(-2.25)
+	w.PutFloat16LE(0.5)
+	w.PutBFloat16BE(3)
+	w.PutUvarint(300)
+	w.PutVarint(-65)
+	w.PutSLEB128(-129)
+	if err := w.PutUintN(5, byteio.LittleEndian, 0x4344454647); err != nil {
+		t.Fatal(err)
+	}
+	w.PutBytes([]byte("xyz"))
+
+	// the slice writer's output must match the package-level writers
+	buf := bytes.NewBuffer(nil)
+	buf.WriteByte(0x01)
+	buf.WriteByte(0xFE)
+	byteio.WriteUint16BE(buf, 0x0304)
+	byteio.WriteInt16LE(buf, -0x0506)
+	byteio.WriteUint24BE(buf, 0x070809)
+	byteio.WriteInt24LE(buf, -0x0A0B0C)
+	byteio.WriteUint32LE(buf, 0x0D0E0F10)
+	byteio.WriteInt32BE(buf, -0x11121314)
+	byteio.WriteFloat32BE(buf, 1.5)
+	byteio.WriteUint48LE(buf, 0x151617181920)
+	byteio.WriteInt48BE(buf, -0x212223242526)
+	byteio.WriteUint64BE(buf, 0x2728293031323334)
+	byteio.WriteInt64LE(buf, -0x3536373839404142)
+	byteio.WriteFloat64LE(buf, -2.25)
+	byteio.WriteFloat16LE(buf, 0.5)
+	byteio.WriteBFloat16BE(buf, 3)
+	byteio.WriteUvarint(buf, 300)
+	byteio.WriteVarint(buf, -65)
+	byteio.WriteSLEB128(buf, -129)
+	byteio.WriteUintN(buf, 5, byteio.LittleEndian, 0x4344454647)
+	buf.WriteString("xyz")
+	if !bytes.Equal(w.Bytes(), buf.Bytes()) {
+		t.Fatalf("act % X ≠ exp % X", w.Bytes(), buf.Bytes())
+	}
+
+	r := byteio.NewSliceReader(w.Bytes())
+	check := func(name string, act, exp interface{}, err error) {
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		} else if act != exp {
+			t.Errorf("%s: act %X ≠ exp %X", name, act, exp)
+		}
+	}
+	u8, err := r.Uint8()
+	check("Uint8", u8, uint8(0x01), err)
+	i8, err := r.Int8()
+	check("Int8", i8, int8(-2), err)
+	u16, err := r.Uint16BE()
+	check("Uint16BE", u16, uint16(0x0304), err)
+	i16, err := r.Int16LE()
+	check("Int16LE", i16, int16(-0x0506), err)
+	u32, err := r.Uint24BE()
+	check("Uint24BE", u32, uint32(0x070809), err)
+	i32, err := r.Int24LE()
+	check("Int24LE", i32, int32(-0x0A0B0C), err)
+	u32, err = r.Uint32LE()
+	check("Uint32LE", u32, uint32(0x0D0E0F10), err)
+	i32, err = r.Int32BE()
+	check("Int32BE", i32, int32(-0x11121314), err)
+	f32, err := r.Float32BE()
+	check("Float32BE", f32, float32(1.5), err)
+	u64, err := r.Uint48LE()
+	check("Uint48LE", u64, uint64(0x151617181920), err)
+	i64, err := r.Int48BE()
+	check("Int48BE", i64, int64(-0x212223242526), err)
+	u64, err = r.Uint64BE()
+	check("Uint64BE", u64, uint64(0x2728293031323334), err)
+	i64, err = r.Int64LE()
+	check("Int64LE", i64, int64(-0x3536373839404142), err)
+	f64, err := r.Float64LE()
+	check("Float64LE", f64, float64(-2.25), err)
+	f32, err = r.Float16LE()
+	check("Float16LE", f32, float32(0.5), err)
+	f32, err = r.BFloat16BE()
+	check("BFloat16BE", f32, float32(3), err)
+	u64, err = r.Uvarint()
+	check("Uvarint", u64, uint64(300), err)
+	i64, err = r.Varint()
+	check("Varint", i64, int64(-65), err)
+	i64, err = r.SLEB128()
+	check("SLEB128", i64, int64(-129), err)
+	u64, err = r.UintN(5, byteio.LittleEndian)
+	check("UintN", u64, uint64(0x4344454647), err)
+
+	off := r.Offset()
+	b, err := r.Bytes(3)
+	if err != nil || string(b) != "xyz" {
+		t.Errorf("Bytes: act %q/%v ≠ exp \"xyz\"", b, err)
+	} else if &b[0] != &w.Bytes()[off] {
+		t.Error("Bytes: returned a copy")
+	}
+	if r.Len() != 0 {
+		t.Errorf("Len: act %d ≠ exp 0", r.Len())
+	}
+	if _, err = r.Uint8(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+// TestSliceReaderShort checks that a short read returns
+// io.ErrUnexpectedEOF and leaves the position unchanged.
+func TestSliceReaderShort(t *testing.T) {
+	r := byteio.NewSliceReader([]byte{0x01, 0x02, 0x03, 0x80})
+	if _, err := r.Uint64BE(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Uint64BE: unexpected error %v", err)
+	}
+	if _, err := r.Bytes(5); err != io.ErrUnexpectedEOF {
+		t.Errorf("Bytes: unexpected error %v", err)
+	}
+	if _, err := r.Bytes(-1); err == nil {
+		t.Error("Bytes: expected error for negative count")
+	}
+	if r.Offset() != 0 {
+		t.Errorf("Offset: act %d ≠ exp 0", r.Offset())
+	}
+
+	r.Skip(3)
+	if _, err := r.Uvarint(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Uvarint: unexpected error %v", err)
+	}
+	if r.Offset() != 3 {
+		t.Errorf("Offset: act %d ≠ exp 3", r.Offset())
+	}
+	if b, err := r.ReadByte(); err != nil || b != 0x80 {
+		t.Errorf("ReadByte: act %X/%v ≠ exp 80", b, err)
+	}
+}
+
+// TestSliceReaderIO checks the Reader methods.
+func TestSliceReaderIO(t *testing.T) {
+	r := byteio.NewSliceReader([]byte("a€bcd"))
+	if ch, size, err := r.ReadRune(); err != nil || ch != 'a' || size != 1 {
+		t.Errorf("ReadRune: act %q/%d/%v", ch, size, err)
+	}
+	if ch, size, err := r.ReadRune(); err != nil || ch != '€' || size != 3 {
+		t.Errorf("ReadRune: act %q/%d/%v", ch, size, err)
+	}
+	buf := make([]byte, 8)
+	if n, err := r.Read(buf); err != nil || string(buf[:n]) != "bcd" {
+		t.Errorf("Read: act %q/%v", buf[:n], err)
+	}
+	if _, err := r.Read(buf); err != io.EOF {
+		t.Errorf("Read: unexpected error %v", err)
+	}
+
+	r.Reset([]byte{0x42})
+	if b, err := r.ReadByte(); err != nil || b != 0x42 {
+		t.Errorf("ReadByte after Reset: act %X/%v", b, err)
+	}
+}
+
+// TestSliceReaderStrings checks the delimited and length-prefixed readers
+// against their package-level equivalents, including that a failed read
+// leaves the position unchanged.
+func TestSliceReaderStrings(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   []byte
+		max  int
+		pkg  func(byteio.Reader, int) ([]byte, error)
+		meth func(*byteio.SliceReader, int) ([]byte, error)
+	}{
+		{"Until", []byte("ab;c"), 2,
+			func(bin byteio.Reader, max int) ([]byte, error) { return byteio.ReadUntil(bin, ';', max) },
+			func(r *byteio.SliceReader, max int) ([]byte, error) { return r.Until(';', max) }},
+		{"Until long", []byte("abc;"), 2,
+			func(bin byteio.Reader, max int) ([]byte, error) { return byteio.ReadUntil(bin, ';', max) },
+			func(r *byteio.SliceReader, max int) ([]byte, error) { return r.Until(';', max) }},
+		{"Until short", []byte("ab"), 2,
+			func(bin byteio.Reader, max int) ([]byte, error) { return byteio.ReadUntil(bin, ';', max) },
+			func(r *byteio.SliceReader, max int) ([]byte, error) { return r.Until(';', max) }},
+		{"Until empty", nil, 2,
+			func(bin byteio.Reader, max int) ([]byte, error) { return byteio.ReadUntil(bin, ';', max) },
+			func(r *byteio.SliceReader, max int) ([]byte, error) { return r.Until(';', max) }},
+		{"BytesU8", []byte{2, 'a', 'b', 'c'}, 2, byteio.ReadBytesU8, (*byteio.SliceReader).BytesU8},
+		{"BytesU8 long", []byte{3, 'a', 'b', 'c'}, 2, byteio.ReadBytesU8, (*byteio.SliceReader).BytesU8},
+		{"BytesU8 short", []byte{3, 'a'}, 4, byteio.ReadBytesU8, (*byteio.SliceReader).BytesU8},
+		{"BytesU8 empty", nil, 4, byteio.ReadBytesU8, (*byteio.SliceReader).BytesU8},
+		{"BytesU16BE", []byte{0, 1, 'a'}, 1, byteio.ReadBytesU16BE, (*byteio.SliceReader).BytesU16BE},
+		{"BytesU16LE", []byte{1, 0, 'a'}, 1, byteio.ReadBytesU16LE, (*byteio.SliceReader).BytesU16LE},
+		{"BytesU32BE", []byte{0, 0, 0, 1, 'a'}, 1, byteio.ReadBytesU32BE, (*byteio.SliceReader).BytesU32BE},
+		{"BytesU32LE", []byte{1, 0, 0, 0, 'a'}, 1, byteio.ReadBytesU32LE, (*byteio.SliceReader).BytesU32LE},
+		{"BytesU32LE prefix short", []byte{1, 0}, 1, byteio.ReadBytesU32LE, (*byteio.SliceReader).BytesU32LE},
+	} {
+		exp, expErr := tc.pkg(bytes.NewReader(tc.in), tc.max)
+		r := byteio.NewSliceReader(tc.in)
+		act, err := tc.meth(r, tc.max)
+		if !bytes.Equal(act, exp) || (err == nil) != (expErr == nil) ||
+			(err != nil && err.Error() != expErr.Error()) {
+			t.Errorf("%s: act %q/%v ≠ exp %q/%v", tc.name, act, err, exp, expErr)
+		}
+		if err != nil && r.Offset() != 0 {
+			t.Errorf("%s: Offset: act %d ≠ exp 0", tc.name, r.Offset())
+		}
+	}
+
+	r := byteio.NewSliceReader([]byte{'h', 'i', 0, 2, 'o', 'k', 2, 0, 'L', 'E'})
+	if s, err := r.CString(4); err != nil || s != "hi" {
+		t.Errorf("CString: act %q/%v ≠ exp \"hi\"", s, err)
+	}
+	if s, err := r.StringU8(4); err != nil || s != "ok" {
+		t.Errorf("StringU8: act %q/%v ≠ exp \"ok\"", s, err)
+	}
+	if s, err := r.StringU16LE(4); err != nil || s != "LE" {
+		t.Errorf("StringU16LE: act %q/%v ≠ exp \"LE\"", s, err)
+	}
+}
+
+// TestSliceReaderUTF checks the UTF-16 and UTF-32 string methods against the
+// package-level functions, including errors.
+func TestSliceReaderUTF(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   []byte
+		n    int
+		pkg  func(byteio.Reader, byteio.Order, int) (string, error)
+		meth func(*byteio.SliceReader, byteio.Order, int) (string, error)
+	}{
+		{"UTF16String", []byte{'h', 0, 'i', 0}, 2,
+			byteio.ReadUTF16String, (*byteio.SliceReader).UTF16String},
+		{"UTF16String short", []byte{'h', 0, 'i'}, 2,
+			byteio.ReadUTF16String, (*byteio.SliceReader).UTF16String},
+		{"UTF16ZString", []byte{'h', 0, 0, 0, 'x'}, 4,
+			byteio.ReadUTF16ZString, (*byteio.SliceReader).UTF16ZString},
+		{"UTF16ZString unterminated", []byte{'h', 0, 'i', 0}, 4,
+			byteio.ReadUTF16ZString, (*byteio.SliceReader).UTF16ZString},
+		{"UTF32String", []byte{'h', 0, 0, 0}, 1,
+			byteio.ReadUTF32String, (*byteio.SliceReader).UTF32String},
+		{"UTF32String invalid", []byte{0, 0, 0x11, 0}, 1,
+			byteio.ReadUTF32String, (*byteio.SliceReader).UTF32String},
+		{"UTF32ZString", []byte{'h', 0, 0, 0, 0, 0, 0, 0}, 4,
+			byteio.ReadUTF32ZString, (*byteio.SliceReader).UTF32ZString},
+		{"UTF32ZString empty", nil, 4,
+			byteio.ReadUTF32ZString, (*byteio.SliceReader).UTF32ZString},
+	} {
+		exp, expErr := tc.pkg(bytes.NewReader(tc.in), byteio.LittleEndian, tc.n)
+		r := byteio.NewSliceReader(tc.in)
+		act, err := tc.meth(r, byteio.LittleEndian, tc.n)
+		if act != exp || (err == nil) != (expErr == nil) ||
+			(err != nil && err.Error() != expErr.Error()) {
+			t.Errorf("%s: act %q/%v ≠ exp %q/%v", tc.name, act, err, exp, expErr)
+		}
+		if err != nil && r.Offset() != 0 {
+			t.Errorf("%s: Offset: act %d ≠ exp 0", tc.name, r.Offset())
+		}
+	}
+}
+
+// TestSliceReaderBulk checks each bulk slice method, found by the name of its
+// package-level counterpart, against that function.
+func TestSliceReaderBulk(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, bc := range bulkCases {
+		for _, n := range bulkSizes {
+			buf := bytes.NewBuffer(nil)
+			bc.write(buf, bc.gen(rng, n))
+			full := buf.Bytes()
+			for _, in := range [][]byte{full, full[:len(full)/2]} {
+				exp, expErr := bc.read(bytes.NewReader(in), n)
+				r := byteio.NewSliceReader(in)
+				act := reflect.MakeSlice(reflect.TypeOf(exp), n, n)
+				res := reflect.ValueOf(r).MethodByName(bc.name).
+					Call([]reflect.Value{act})
+				err, _ := res[0].Interface().(error)
+				if err != expErr {
+					t.Errorf("%s/%d(%d bytes): act %v ≠ exp %v",
+						bc.name, n, len(in), err, expErr)
+				} else if err != nil && r.Offset() != 0 {
+					t.Errorf("%s/%d: Offset: act %d ≠ exp 0",
+						bc.name, n, r.Offset())
+				} else if err == nil && !reflect.DeepEqual(act.Interface(), exp) {
+					t.Errorf("%s/%d: values differ", bc.name, n)
+				}
+			}
+		}
+	}
+}
+
+// TestSliceWriterReset checks that Reset retains storage.
+func TestSliceWriterReset(t *testing.T) {
+	w := byteio.NewSliceWriter(make([]byte, 0, 16))
+	w.PutUint32BE(1)
+	first := &w.Bytes()[0]
+	w.Reset()
+	w.WriteRune('€')
+	if w.Len() != 3 || &w.Bytes()[0] != first {
+		t.Errorf("unexpected state after Reset: % X", w.Bytes())
+	}
+	if err := w.PutUintN(9, byteio.BigEndian, 0); err == nil {
+		t.Error("PutUintN: expected error for invalid width")
+	}
+}
+
+func BenchmarkSliceReaderUint64BE(b *testing.B) {
+	buf := make([]byte, 8*1024)
+	r := byteio.NewSliceReader(buf)
+	b.SetBytes(int64(len(buf)))
+	for i := 0; i < b.N; i++ {
+		r.Reset(buf)
+		for j := 0; j < 1024; j++ {
+			if _, err := r.Uint64BE(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkBytesReaderUint64BE(b *testing.B) {
+	buf := make([]byte, 8*1024)
+	r := bytes.NewReader(buf)
+	b.SetBytes(int64(len(buf)))
+	for i := 0; i < b.N; i++ {
+		r.Reset(buf)
+		for j := 0; j < 1024; j++ {
+			if _, err := byteio.ReadUint64BE(r); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkSliceWriterUint64BE(b *testing.B) {
+	w := byteio.NewSliceWriter(make([]byte, 0, 8*1024))
+	b.SetBytes(8 * 1024)
+	for i := 0; i < b.N; i++ {
+		w.Reset()
+		for j := 0; j < 1024; j++ {
+			w.PutUint64BE(uint64(j))
+		}
+	}
+}
+
+func BenchmarkBytesBufferUint64BE(b *testing.B) {
+	buf := bytes.NewBuffer(make([]byte, 0, 8*1024))
+	b.SetBytes(8 * 1024)
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		for j := 0; j < 1024; j++ {
+			byteio.WriteUint64BE(buf, uint64(j))
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package byteio
+
+import (
+	"bytes"
+	"errors"
+	"math"
+)
+
+var (
+	errTLVType  = errors.New("byteio: TLV type too large for type field")
+	errTLVState = errors.New("byteio: TLVWriter used outside Begin/End")
+)
+
+// maxUintN returns the largest value representable in width bytes.
+func maxUintN(width int) uint64 {
+	if width >= 8 {
+		return math.MaxUint64
+	}
+	return 1<<uint(8*width) - 1
+}
+
+// TLVReader iterates over a stream of type-length-value records. The type and
+// length fields are unsigned integers of configurable width and byte order,
+// and the length counts the bytes of the value which follows.
+type TLVReader struct {
+	bin                 Reader
+	typeWidth, lenWidth int
+	order               Order
+	val                 *LimitedReader
+}
+
+// NewTLVReader returns a TLVReader which reads records from bin. The type and
+// length fields are typeWidth and lenWidth bytes wide respectively (each
+// between 1 and 8).
+func NewTLVReader(bin Reader, typeWidth, lenWidth int,
+	order Order) *TLVReader {
+	return &TLVReader{
+		bin:       bin,
+		typeWidth: typeWidth,
+		lenWidth:  lenWidth,
+		order:     order,
+	}
+}
+
+// Next advances to the next record, returning its type and a reader bounded
+// to its value. Any part of the previous record's value which was not read is
+// skipped. io.EOF is returned at the end of the stream, and
+// io.ErrUnexpectedEOF if the stream ends part way through a record.
+func (tr *TLVReader) Next() (typ uint64, val *LimitedReader, err error) {
+	if tr.val != nil {
+		err = tr.val.Discard()
+		tr.val = nil
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if typ, err = ReadUintN(tr.bin, tr.typeWidth, tr.order); err != nil {
+		return 0, nil, err
+	}
+	n, err := ReadUintN(tr.bin, tr.lenWidth, tr.order)
+	if err != nil {
+		return 0, nil, midRecord(true, err)
+	}
+	if n > math.MaxInt64 {
+		return 0, nil, &LengthError{Length: n, Max: math.MaxInt64}
+	}
+
+	tr.val = LimitReader(tr.bin, int64(n))
+	return typ, tr.val, nil
+}
+
+// TLVWriter writes a stream of type-length-value records in the same format
+// read by TLVReader. Since the length must precede the value, each value is
+// buffered: call Begin, write the value to the TLVWriter (which is itself a
+// Writer, so records may be nested), then call End to emit the record.
+type TLVWriter struct {
+	bout                Writer
+	typeWidth, lenWidth int
+	order               Order
+	typ                 uint64
+	open                bool
+	buf                 bytes.Buffer
+}
+
+// NewTLVWriter returns a TLVWriter which writes records to bout. The type and
+// length fields are typeWidth and lenWidth bytes wide respectively (each
+// between 1 and 8).
+func NewTLVWriter(bout Writer, typeWidth, lenWidth int,
+	order Order) *TLVWriter {
+	return &TLVWriter{
+		bout:      bout,
+		typeWidth: typeWidth,
+		lenWidth:  lenWidth,
+		order:     order,
+	}
+}
+
+// Begin starts a new record of the given type, discarding any value buffered
+// for a record which was not ended.
+func (tw *TLVWriter) Begin(typ uint64) {
+	tw.typ = typ
+	tw.open = true
+	tw.buf.Reset()
+}
+
+// End writes the record started by Begin, prefixed by its type and length. If
+// the value is too long for the length field, a *LengthError is returned and
+// nothing is written.
+func (tw *TLVWriter) End() error {
+	if !tw.open {
+		return errTLVState
+	}
+	tw.open = false
+	return tw.WriteRecord(tw.typ, tw.buf.Bytes())
+}
+
+// WriteRecord writes a complete record with the given type and value. It must
+// not be called between Begin and End.
+func (tw *TLVWriter) WriteRecord(typ uint64, val []byte) error {
+	if tw.open {
+		return errTLVState
+	}
+	if typ > maxUintN(tw.typeWidth) {
+		return errTLVType
+	}
+	if err := checkLength(len(val), maxUintN(tw.lenWidth)); err != nil {
+		return err
+	}
+	if err := WriteUintN(tw.bout, tw.typeWidth, tw.order, typ); err != nil {
+		return err
+	}
+	err := WriteUintN(tw.bout, tw.lenWidth, tw.order, uint64(len(val)))
+	if err != nil {
+		return err
+	}
+	_, err = tw.bout.Write(val)
+	return err
+}
+
+func (tw *TLVWriter) Write(buf []byte) (int, error) {
+	if !tw.open {
+		return 0, errTLVState
+	}
+	return tw.buf.Write(buf)
+}
+
+func (tw *TLVWriter) WriteByte(b byte) error {
+	if !tw.open {
+		return errTLVState
+	}
+	return tw.buf.WriteByte(b)
+}
+
+func (tw *TLVWriter) WriteRune(r rune) (int, error) {
+	if !tw.open {
+		return 0, errTLVState
+	}
+	return tw.buf.WriteRune(r)
+}
+
+// Flush flushes the underlying writer, if it requires flushing.
+func (tw *TLVWriter) Flush() error {
+	return FlushIfNecessary(tw.bout)
+}
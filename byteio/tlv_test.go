@@ -0,0 +1,124 @@
+package byteio_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+var _ byteio.Writer = (*byteio.TLVWriter)(nil)
+
+// TestTLVReader checks iteration over records, including skipping values
+// which are not fully read.
+func TestTLVReader(t *testing.T) {
+	in := []byte{
+		0x01, 0x03, 0x00, 'a', 'b', 'c',
+		0x02, 0x00, 0x00,
+		0xFF, 0x01, 0x00, 'z',
+	}
+	tr := byteio.NewTLVReader(bytes.NewReader(in), 1, 2, byteio.LittleEndian)
+
+	exp := []struct {
+		typ uint64
+		val string
+		n   int // bytes to read before moving on
+	}{
+		{0x01, "a", 1},
+		{0x02, "", 0},
+		{0xFF, "z", 1},
+	}
+	for _, e := range exp {
+		typ, val, err := tr.Next()
+		if err != nil {
+			t.Fatalf("Next: unexpected error %v", err)
+		}
+		if typ != e.typ {
+			t.Errorf("type: act %X ≠ exp %X", typ, e.typ)
+		}
+		buf := make([]byte, e.n)
+		if _, err := io.ReadFull(val, buf); err != nil || string(buf) != e.val {
+			t.Errorf("value: act %q/%v ≠ exp %q", buf, err, e.val)
+		}
+	}
+	if _, _, err := tr.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+// TestTLVReaderShort checks that truncated records are reported with
+// io.ErrUnexpectedEOF.
+func TestTLVReaderShort(t *testing.T) {
+	for _, in := range [][]byte{
+		{0x00, 0x01},             // partial type
+		{0x00, 0x01, 0x00},       // missing length
+		{0x00, 0x01, 0x00, 0x02}, // missing value
+	} {
+		tr := byteio.NewTLVReader(bytes.NewReader(in), 2, 2, byteio.BigEndian)
+		_, _, err := tr.Next()
+		if err == nil {
+			_, _, err = tr.Next()
+		}
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("% X: unexpected error %v", in, err)
+		}
+	}
+}
+
+// TestTLVWriter checks that records written with Begin/End and WriteRecord,
+// including nested records, are framed correctly.
+func TestTLVWriter(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	tw := byteio.NewTLVWriter(buf, 2, 1, byteio.BigEndian)
+
+	tw.Begin(0x0102)
+	inner := byteio.NewTLVWriter(tw, 1, 1, byteio.BigEndian)
+	if err := inner.WriteRecord(0x03, []byte("xy")); err != nil {
+		t.Fatal(err)
+	}
+	tw.WriteByte('!')
+	if err := tw.End(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.WriteRecord(0x0004, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []byte{
+		0x01, 0x02, 0x05, 0x03, 0x02, 'x', 'y', '!',
+		0x00, 0x04, 0x00,
+	}
+	if !bytes.Equal(buf.Bytes(), exp) {
+		t.Errorf("act % X ≠ exp % X", buf.Bytes(), exp)
+	}
+}
+
+// TestTLVWriterErr checks the errors returned for out-of-range fields and
+// misuse.
+func TestTLVWriterErr(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	tw := byteio.NewTLVWriter(buf, 1, 1, byteio.LittleEndian)
+
+	err := tw.WriteRecord(0x01, make([]byte, 256))
+	if le, ok := err.(*byteio.LengthError); !ok || le.Length != 256 || le.Max != 255 {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err = tw.WriteRecord(0x100, nil); err == nil {
+		t.Error("expected error for oversized type")
+	}
+	if err = tw.End(); err == nil {
+		t.Error("expected error for End without Begin")
+	}
+	if err = tw.WriteByte(0); err == nil {
+		t.Error("expected error for write outside record")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("unexpected output % X", buf.Bytes())
+	}
+
+	tw = byteio.NewTLVWriter(&AbortWriter{when: 1}, 1, 1, byteio.LittleEndian)
+	if err = tw.WriteRecord(0x01, []byte{0}); err != ErrAbortWriter {
+		t.Errorf("unexpected error %v", err)
+	}
+}
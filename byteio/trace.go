@@ -0,0 +1,208 @@
+package byteio
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// traceWidth is the number of bytes shown on each line of a trace.
+const traceWidth = 16
+
+// TraceReader wraps a Reader, writing an annotated hexdump of every byte
+// consumed through it to an io.Writer. It is intended for debugging decoders.
+//
+// When a TraceReader is passed directly to one of the package's fixed-width
+// integer or floating point readers, or its varint readers, the bytes of the
+// value are shown on their own line followed by an annotation such as
+// "uint32BE length = 0x1a" or "float32BE = 1.5". Unsigned values are shown
+// in hex and signed values in decimal. Failed reads are annotated with the
+// error. Other bytes are dumped 16 to a line.
+//
+// Output is produced as values complete, so Flush must be called once decoding
+// has finished (or failed) to dump any remaining bytes.
+type TraceReader struct {
+	bin     Reader
+	out     io.Writer
+	rb      runeBuf
+	pend    []byte // consumed bytes not yet dumped
+	pendOff int64  // offset of pend[0]
+	label   string
+	err     error
+}
+
+// NewTraceReader returns a TraceReader which reads from bin and writes its
+// trace to out.
+func NewTraceReader(bin Reader, out io.Writer) *TraceReader {
+	return &TraceReader{bin: bin, out: out}
+}
+
+// Label sets a name to include in the annotation of the next value read, for
+// example the name of the field being decoded.
+func (tr *TraceReader) Label(name string) {
+	tr.label = name
+}
+
+// Offset returns the number of bytes consumed so far.
+func (tr *TraceReader) Offset() int64 {
+	return tr.pendOff + int64(len(tr.pend))
+}
+
+// Flush dumps any consumed bytes not yet written to the trace. It returns the
+// first error encountered writing the trace, if any.
+func (tr *TraceReader) Flush() error {
+	tr.dump(len(tr.pend))
+	return tr.err
+}
+
+func (tr *TraceReader) Read(buf []byte) (int, error) {
+	n, err := tr.rb.read(tr.bin, buf)
+	tr.consume(buf[:n])
+	return n, err
+}
+
+func (tr *TraceReader) ReadByte() (byte, error) {
+	b, err := tr.rb.readByte(tr.bin)
+	if err == nil {
+		tr.pend = append(tr.pend, b)
+		tr.trim()
+	}
+	return b, err
+}
+
+func (tr *TraceReader) ReadRune() (rune, int, error) {
+	r, size, raw, err := tr.rb.readRune(tr.bin)
+	tr.consume(raw[:size])
+	return r, size, err
+}
+
+// consume records bytes which have been read.
+func (tr *TraceReader) consume(b []byte) {
+	tr.pend = append(tr.pend, b...)
+	tr.trim()
+}
+
+// trim dumps complete lines of unannotated bytes, always retaining enough
+// that the value currently being read can be shown on a single line.
+func (tr *TraceReader) trim() {
+	if n := len(tr.pend) - traceWidth; n >= traceWidth {
+		tr.dump(n - n%traceWidth)
+	}
+}
+
+// dump writes the first n pending bytes as unannotated lines.
+func (tr *TraceReader) dump(n int) {
+	for n > 0 {
+		m := minInt(n, traceWidth)
+		tr.line(m, "")
+		n -= m
+	}
+}
+
+// annotate dumps any pending bytes, showing the final n (or as many of them
+// as fit) on a line of their own followed by note.
+func (tr *TraceReader) annotate(n int, note string) {
+	n = minInt(n, minInt(len(tr.pend), traceWidth))
+	tr.dump(len(tr.pend) - n)
+	tr.line(n, note)
+	tr.label = ""
+}
+
+// line writes the first n pending bytes as a single line of the trace and
+// removes them from the pending buffer.
+func (tr *TraceReader) line(n int, note string) {
+	b := tr.pend[:n]
+	if tr.err == nil {
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "%08x  ", tr.pendOff)
+		for i := 0; i < traceWidth; i++ {
+			if i < len(b) {
+				fmt.Fprintf(&sb, "%02x ", b[i])
+			} else {
+				sb.WriteString("   ")
+			}
+			if i == traceWidth/2-1 {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(" |")
+		for i := 0; i < traceWidth; i++ {
+			switch {
+			case i >= len(b):
+				sb.WriteByte(' ')
+			case b[i] < 0x20 || b[i] > 0x7E:
+				sb.WriteByte('.')
+			default:
+				sb.WriteByte(b[i])
+			}
+		}
+		sb.WriteByte('|')
+		if note != "" {
+			sb.WriteString("  ")
+			sb.WriteString(note)
+		}
+		sb.WriteByte('\n')
+		_, tr.err = io.WriteString(tr.out, sb.String())
+	}
+
+	tr.pendOff += int64(n)
+	tr.pend = tr.pend[:copy(tr.pend, tr.pend[n:])]
+}
+
+// traceValue annotates the n bytes of a value just read from bin, if bin is a
+// TraceReader. v holds the bits of the value, which is shown in the form
+// implied by kind.
+func traceValue(bin Reader, n int, kind string, v uint64) {
+	if tr, ok := bin.(*TraceReader); ok {
+		tr.annotate(n, tr.note(kind, "= "+formatValue(n, kind, v)))
+	}
+}
+
+// formatValue formats the n-byte value with bits v: in decimal for the signed
+// integer kinds, as a number for the floating point kinds, and otherwise in
+// hex.
+func formatValue(n int, kind string, v uint64) string {
+	switch {
+	case strings.HasPrefix(kind, "int"):
+		shift := uint(64 - 8*n)
+		return strconv.FormatInt(int64(v<<shift)>>shift, 10)
+	case strings.HasPrefix(kind, "float16"):
+		return strconv.FormatFloat(float64(Float16frombits(uint16(v))), 'g', -1, 32)
+	case strings.HasPrefix(kind, "bfloat16"):
+		return strconv.FormatFloat(float64(BFloat16frombits(uint16(v))), 'g', -1, 32)
+	case strings.HasPrefix(kind, "float32"):
+		return strconv.FormatFloat(float64(math.Float32frombits(uint32(v))), 'g', -1, 32)
+	case strings.HasPrefix(kind, "float64"):
+		return strconv.FormatFloat(math.Float64frombits(v), 'g', -1, 64)
+	}
+	return fmt.Sprintf("%#x", v)
+}
+
+// traceInt annotates the n bytes of a varint just read from bin, if bin is a
+// TraceReader. Unlike traceValue, it is given the decoded value.
+func traceInt(bin Reader, n int, kind string, v int64) {
+	if tr, ok := bin.(*TraceReader); ok {
+		tr.annotate(n, tr.note(kind, "= "+strconv.FormatInt(v, 10)))
+	}
+}
+
+// traceErr annotates the n bytes of a value whose read failed, if bin is a
+// TraceReader.
+func traceErr(bin Reader, n int, err error) {
+	if tr, ok := bin.(*TraceReader); ok {
+		tr.annotate(n, tr.note("", "error: "+err.Error()))
+	}
+}
+
+// note builds an annotation, including the label if one is set.
+func (tr *TraceReader) note(kind, desc string) string {
+	parts := make([]string, 0, 3)
+	for _, s := range []string{kind, tr.label, desc} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
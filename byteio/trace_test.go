@@ -0,0 +1,130 @@
+package byteio_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// TestTraceReader checks the annotated output for values, labels, raw reads
+// and a failed read.
+func TestTraceReader(t *testing.T) {
+	in := []byte{
+		0x00, 0x00, 0x00, 0x1A, // length
+		0xAC, 0x02, // uvarint 300
+		'h', 'e', 'l', 'l', 'o', // raw
+		0x01, // truncated uint16
+	}
+	out := bytes.NewBuffer(nil)
+	tr := byteio.NewTraceReader(bytes.NewReader(in), out)
+
+	tr.Label("length")
+	if v, err := byteio.ReadUint32BE(tr); err != nil || v != 0x1A {
+		t.Fatalf("ReadUint32BE: act %X/%v", v, err)
+	}
+	if v, err := byteio.ReadUvarint(tr); err != nil || v != 300 {
+		t.Fatalf("ReadUvarint: act %d/%v", v, err)
+	}
+	io.ReadFull(tr, make([]byte, 5))
+	if _, err := byteio.ReadUint16LE(tr); err != io.ErrUnexpectedEOF {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := tr.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := strings.Join([]string{
+		"00000000  00 00 00 1a                                       |....            |  uint32BE length = 0x1a",
+		"00000004  ac 02                                             |..              |  uvarint = 0x12c",
+		"00000006  68 65 6c 6c 6f                                    |hello           |",
+		"0000000b  01                                                |.               |  error: unexpected EOF",
+		"",
+	}, "\n")
+	if act := out.String(); act != exp {
+		t.Errorf("act:\n%s\nexp:\n%s", act, exp)
+	}
+	if act := tr.Offset(); act != int64(len(in)) {
+		t.Errorf("Offset: act %d ≠ exp %d", act, len(in))
+	}
+}
+
+// TestTraceReaderLong checks that long runs of unannotated bytes are split
+// into lines, and that a value following them is still shown on its own line.
+func TestTraceReaderLong(t *testing.T) {
+	in := make([]byte, 40)
+	for i := range in {
+		in[i] = byte('A' + i%26)
+	}
+	out := bytes.NewBuffer(nil)
+	tr := byteio.NewTraceReader(bytes.NewReader(in), out)
+
+	io.ReadFull(tr, make([]byte, 34))
+	tr.ReadRune()
+	byteio.ReadUint32LE(tr)
+	tr.Flush()
+
+	exp := strings.Join([]string{
+		"00000000  41 42 43 44 45 46 47 48  49 4a 4b 4c 4d 4e 4f 50  |ABCDEFGHIJKLMNOP|",
+		"00000010  51 52 53 54 55 56 57 58  59 5a 41 42 43 44 45 46  |QRSTUVWXYZABCDEF|",
+		"00000020  47 48 49                                          |GHI             |",
+		"00000023  4a 4b 4c 4d                                       |JKLM            |  uint32LE = 0x4d4c4b4a",
+		"",
+	}, "\n")
+	if act := out.String(); act != exp {
+		t.Errorf("act:\n%s\nexp:\n%s", act, exp)
+	}
+}
+
+// TestTraceReaderPassThrough checks that the data read through a TraceReader
+// is unaltered.
+func TestTraceReaderPassThrough(t *testing.T) {
+	in := []byte("a€\xC3Z")
+	tr := byteio.NewTraceReader(bytes.NewReader(in), io.Discard)
+	var got []byte
+	for {
+		r, _, err := tr.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		got = append(got, string(r)...)
+	}
+	if exp := "a€�Z"; string(got) != exp {
+		t.Errorf("act %q ≠ exp %q", got, exp)
+	}
+}
+
+// TestTraceKinds checks that signed and floating point values are shown with
+// their own type and decoded value.
+func TestTraceKinds(t *testing.T) {
+	in := []byte{
+		0xFF, 0xFF, 0xFF, 0xFE, // int32BE -2
+		0x00, 0x00, 0xC0, 0x3F, // float32LE 1.5
+		0x00, 0x3C, // float16LE 1
+		0x03,       // zigzag varint -2
+		0x7E,       // sleb128 -2
+		0xFF, 0xFD, // int16 width 2 -3
+	}
+	out := bytes.NewBuffer(nil)
+	tr := byteio.NewTraceReader(bytes.NewReader(in), out)
+	byteio.ReadInt32BE(tr)
+	byteio.ReadFloat32LE(tr)
+	byteio.ReadFloat16LE(tr)
+	byteio.ReadVarint(tr)
+	byteio.ReadSLEB128(tr)
+	byteio.ReadIntN(tr, 2, byteio.BigEndian)
+	if err := tr.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, exp := range []string{
+		"|  int32BE = -2\n", "|  float32LE = 1.5\n", "|  float16LE = 1\n",
+		"|  varint = -2\n", "|  sleb128 = -2\n", "|  intN = -3\n",
+	} {
+		if !strings.Contains(out.String(), exp) {
+			t.Errorf("trace missing %q:\n%s", exp, out.String())
+		}
+	}
+}
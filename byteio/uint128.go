@@ -0,0 +1,120 @@
+package byteio
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// Uint128 is an unsigned 128-bit integer, as found in IPv6 addresses and
+// 128-bit hashes.
+type Uint128 struct {
+	Hi, Lo uint64
+}
+
+func (u Uint128) String() string {
+	return fmt.Sprintf("%016x%016x", u.Hi, u.Lo)
+}
+
+// ReadUint128BE reads an unsigned 128-bit integer in big-endian (network) byte
+// order.
+func ReadUint128BE(bin Reader) (Uint128, error) {
+	var u Uint128
+	var err error
+	if u.Hi, err = ReadUint64BE(bin); err != nil {
+		return Uint128{}, err
+	}
+	if u.Lo, err = ReadUint64BE(bin); err != nil {
+		return Uint128{}, midRecord(true, err)
+	}
+	return u, nil
+}
+
+// ReadUint128LE reads an unsigned 128-bit integer in little-endian byte order.
+func ReadUint128LE(bin Reader) (Uint128, error) {
+	var u Uint128
+	var err error
+	if u.Lo, err = ReadUint64LE(bin); err != nil {
+		return Uint128{}, err
+	}
+	if u.Hi, err = ReadUint64LE(bin); err != nil {
+		return Uint128{}, midRecord(true, err)
+	}
+	return u, nil
+}
+
+// WriteUint128BE writes an unsigned 128-bit integer in big-endian (network)
+// byte order.
+func WriteUint128BE(bout Writer, u Uint128) error {
+	if err := WriteUint64BE(bout, u.Hi); err != nil {
+		return err
+	}
+	return WriteUint64BE(bout, u.Lo)
+}
+
+// WriteUint128LE writes an unsigned 128-bit integer in little-endian byte
+// order.
+func WriteUint128LE(bout Writer, u Uint128) error {
+	if err := WriteUint64LE(bout, u.Lo); err != nil {
+		return err
+	}
+	return WriteUint64LE(bout, u.Hi)
+}
+
+// UUID is a universally unique identifier, held in the RFC 4122 byte order.
+type UUID [16]byte
+
+// String returns the canonical textual form of u, for example
+// "00112233-4455-6677-8899-aabbccddeeff".
+func (u UUID) String() string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// ReadUUID reads a UUID stored in RFC 4122 (big-endian) byte order.
+func ReadUUID(bin Reader) (UUID, error) {
+	var u UUID
+	if n, err := io.ReadFull(bin, u[:]); err != nil {
+		return UUID{}, readErr(bin, n, err)
+	}
+	return u, nil
+}
+
+// WriteUUID writes a UUID in RFC 4122 (big-endian) byte order.
+func WriteUUID(bout Writer, u UUID) error {
+	_, err := bout.Write(u[:])
+	return err
+}
+
+// ReadGUIDMixedEndian reads a UUID stored in the Microsoft GUID layout, in
+// which the first three fields (of 4, 2 and 2 bytes) are little-endian and
+// the remaining 8 bytes are stored as-is. The result is converted to RFC 4122
+// byte order, so its String matches the GUID's usual textual form.
+func ReadGUIDMixedEndian(bin Reader) (UUID, error) {
+	u, err := ReadUUID(bin)
+	swapGUID(&u)
+	return u, err
+}
+
+// WriteGUIDMixedEndian writes a UUID in the Microsoft GUID layout, as read by
+// ReadGUIDMixedEndian.
+func WriteGUIDMixedEndian(bout Writer, u UUID) error {
+	swapGUID(&u)
+	return WriteUUID(bout, u)
+}
+
+// swapGUID converts between RFC 4122 and Microsoft GUID byte order.
+func swapGUID(u *UUID) {
+	u[0], u[1], u[2], u[3] = u[3], u[2], u[1], u[0]
+	u[4], u[5] = u[5], u[4]
+	u[6], u[7] = u[7], u[6]
+}
@@ -0,0 +1,93 @@
+package byteio_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// TestUint128 checks both byte orders against a known pattern.
+func TestUint128(t *testing.T) {
+	in := []byte{
+		0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07,
+		0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F,
+	}
+	be := byteio.Uint128{Hi: 0x0001020304050607, Lo: 0x08090A0B0C0D0E0F}
+	le := byteio.Uint128{Hi: 0x0F0E0D0C0B0A0908, Lo: 0x0706050403020100}
+
+	if act, err := byteio.ReadUint128BE(bytes.NewReader(in)); err != nil || act != be {
+		t.Errorf("ReadUint128BE: act %v/%v ≠ exp %v", act, err, be)
+	}
+	if act, err := byteio.ReadUint128LE(bytes.NewReader(in)); err != nil || act != le {
+		t.Errorf("ReadUint128LE: act %v/%v ≠ exp %v", act, err, le)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err := byteio.WriteUint128BE(buf, be); err != nil || !bytes.Equal(buf.Bytes(), in) {
+		t.Errorf("WriteUint128BE: act % X/%v ≠ exp % X", buf.Bytes(), err, in)
+	}
+	buf.Reset()
+	if err := byteio.WriteUint128LE(buf, le); err != nil || !bytes.Equal(buf.Bytes(), in) {
+		t.Errorf("WriteUint128LE: act % X/%v ≠ exp % X", buf.Bytes(), err, in)
+	}
+
+	if act := be.String(); act != "000102030405060708090a0b0c0d0e0f" {
+		t.Errorf("String: act %s", act)
+	}
+
+	for i, exp := range map[int]error{0: io.EOF, 1: io.ErrUnexpectedEOF, 8: io.ErrUnexpectedEOF, 15: io.ErrUnexpectedEOF} {
+		if _, err := byteio.ReadUint128BE(bytes.NewReader(in[:i])); err != exp {
+			t.Errorf("ReadUint128BE/%d: act %v ≠ exp %v", i, err, exp)
+		}
+		if _, err := byteio.ReadUint128LE(bytes.NewReader(in[:i])); err != exp {
+			t.Errorf("ReadUint128LE/%d: act %v ≠ exp %v", i, err, exp)
+		}
+	}
+}
+
+// TestUUID checks the RFC 4122 and Microsoft GUID layouts.
+func TestUUID(t *testing.T) {
+	// the GUID {00112233-4455-6677-8899-AABBCCDDEEFF} as stored on disk
+	guid := []byte{
+		0x33, 0x22, 0x11, 0x00, 0x55, 0x44, 0x77, 0x66,
+		0x88, 0x99, 0xAA, 0xBB, 0xCC, 0xDD, 0xEE, 0xFF,
+	}
+	const exp = "00112233-4455-6677-8899-aabbccddeeff"
+
+	u, err := byteio.ReadGUIDMixedEndian(bytes.NewReader(guid))
+	if err != nil || u.String() != exp {
+		t.Errorf("ReadGUIDMixedEndian: act %v/%v ≠ exp %s", u, err, exp)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err = byteio.WriteGUIDMixedEndian(buf, u); err != nil || !bytes.Equal(buf.Bytes(), guid) {
+		t.Errorf("WriteGUIDMixedEndian: act % X/%v ≠ exp % X", buf.Bytes(), err, guid)
+	}
+
+	buf.Reset()
+	if err = byteio.WriteUUID(buf, u); err != nil {
+		t.Fatal(err)
+	}
+	if act := buf.Bytes(); act[0] != 0x00 || act[3] != 0x33 || act[15] != 0xFF {
+		t.Errorf("WriteUUID: act % X", act)
+	}
+	if act, err := byteio.ReadUUID(buf); err != nil || act != u {
+		t.Errorf("ReadUUID: act %v/%v ≠ exp %v", act, err, u)
+	}
+
+	if _, err = byteio.ReadUUID(bytes.NewReader(guid[:5])); err != io.ErrUnexpectedEOF {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// a partial read is reported at the offset of the start of the UUID
+	cr := byteio.NewCountingReader(bytes.NewReader(guid[:7]))
+	cr.WrapErrors = true
+	cr.ReadByte()
+	_, err = byteio.ReadUUID(cr)
+	if oerr, ok := err.(*byteio.OffsetError); !ok || oerr.Offset != 1 ||
+		oerr.Err != io.ErrUnexpectedEOF {
+		t.Errorf("WrapErrors: unexpected error %v", err)
+	}
+}
@@ -0,0 +1,287 @@
+package byteio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var errEmbeddedNUL = errors.New("byteio: NUL-terminated string contains NUL")
+
+// binaryOrder returns the encoding/binary equivalent of o.
+func binaryOrder(o Order) binary.ByteOrder {
+	if o == LittleEndian {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
+
+// SurrogatePolicy selects how the UTF-16 and UTF-32 readers treat invalid code
+// units: unpaired surrogates in UTF-16, and surrogates or values beyond
+// U+10FFFF in UTF-32. The package-level readers use ReplaceSurrogates; the
+// methods of a SurrogatePolicy behave identically apart from that choice.
+type SurrogatePolicy int
+
+const (
+	// ReplaceSurrogates replaces each invalid code unit with U+FFFD.
+	ReplaceSurrogates SurrogatePolicy = iota
+
+	// RejectSurrogates returns an *InvalidUnitError for the first invalid
+	// code unit. The whole string has been consumed.
+	RejectSurrogates
+)
+
+// InvalidUnitError is returned by the readers of a RejectSurrogates policy
+// when a string contains an invalid code unit.
+type InvalidUnitError struct {
+	Index int    // index of the offending code unit within the string
+	Unit  uint32 // value of the offending code unit
+}
+
+func (e *InvalidUnitError) Error() string {
+	return fmt.Sprintf("byteio: invalid code unit 0x%X at index %d",
+		e.Unit, e.Index)
+}
+
+// ReadUTF16String reads a string of exactly nUnits UTF-16 code units (not
+// characters) in the given byte order and returns it as UTF-8. Surrogate pairs
+// are combined, and unpaired surrogates are replaced with U+FFFD.
+func ReadUTF16String(bin Reader, order Order, nUnits int) (string, error) {
+	return ReplaceSurrogates.ReadUTF16String(bin, order, nUnits)
+}
+
+// ReadUTF16String is like the package-level function ReadUTF16String, but
+// treats unpaired surrogates according to p.
+func (p SurrogatePolicy) ReadUTF16String(bin Reader, order Order,
+	nUnits int) (string, error) {
+	if nUnits <= 0 {
+		return "", nil
+	}
+	if nUnits > maxIntValue/2 {
+		return "", &LengthError{Length: uint64(nUnits),
+			Max: uint64(maxIntValue / 2)}
+	}
+	buf, err := ReadBytes(bin, 2*nUnits)
+	if err != nil {
+		return "", err
+	}
+	bo := binaryOrder(order)
+	units := make([]uint16, len(buf)/2)
+	for i := range units {
+		units[i] = bo.Uint16(buf[2*i:])
+	}
+	return p.decodeUTF16(units)
+}
+
+// ReadUTF16ZString reads a NUL-terminated UTF-16 string in the given byte
+// order, as for ReadUTF16String. The terminator is consumed but not returned.
+// If no terminator is found within max code units, a *LengthError is
+// returned.
+func ReadUTF16ZString(bin Reader, order Order, max int) (string, error) {
+	return ReplaceSurrogates.ReadUTF16ZString(bin, order, max)
+}
+
+// ReadUTF16ZString is like the package-level function ReadUTF16ZString, but
+// treats unpaired surrogates according to p.
+func (p SurrogatePolicy) ReadUTF16ZString(bin Reader, order Order,
+	max int) (string, error) {
+	var units []uint16
+	for {
+		u, err := order.ReadUint16(bin)
+		if err != nil {
+			return "", midRecord(units != nil, err)
+		}
+		if u == 0 {
+			return p.decodeUTF16(units)
+		}
+		if len(units) >= max {
+			return "", &LengthError{Length: uint64(len(units)) + 1,
+				Max: uint64(maxInt(max, 0))}
+		}
+		units = append(units, u)
+	}
+}
+
+// decodeUTF16 converts UTF-16 code units to UTF-8, applying policy p to
+// unpaired surrogates.
+func (p SurrogatePolicy) decodeUTF16(units []uint16) (string, error) {
+	var sb strings.Builder
+	sb.Grow(len(units))
+	for i := 0; i < len(units); i++ {
+		r := rune(units[i])
+		if utf16.IsSurrogate(r) {
+			if i+1 < len(units) {
+				if pr := utf16.DecodeRune(r, rune(units[i+1])); pr != utf8.RuneError {
+					sb.WriteRune(pr)
+					i++
+					continue
+				}
+			}
+			if p == RejectSurrogates {
+				return "", &InvalidUnitError{Index: i, Unit: uint32(r)}
+			}
+			r = utf8.RuneError
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String(), nil
+}
+
+// UTF16Len returns the number of UTF-16 code units needed to encode s, as
+// written by WriteUTF16String.
+func UTF16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteUTF16String writes s as UTF-16 in the given byte order, without any
+// length prefix or terminator. Characters outside the Basic Multilingual
+// Plane are written as surrogate pairs, and invalid UTF-8 is written as
+// U+FFFD.
+func WriteUTF16String(bout Writer, order Order, s string) error {
+	for _, r := range s {
+		if r >= 0x10000 {
+			r1, r2 := utf16.EncodeRune(r)
+			if err := order.WriteUint16(bout, uint16(r1)); err != nil {
+				return err
+			}
+			r = r2
+		}
+		if err := order.WriteUint16(bout, uint16(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteUTF16ZString writes s as UTF-16 in the given byte order, followed by a
+// NUL terminator. An error is returned if s itself contains NUL.
+func WriteUTF16ZString(bout Writer, order Order, s string) error {
+	if strings.IndexByte(s, 0) >= 0 {
+		return errEmbeddedNUL
+	}
+	if err := WriteUTF16String(bout, order, s); err != nil {
+		return err
+	}
+	return order.WriteUint16(bout, 0)
+}
+
+// ReadUTF32String reads a string of exactly n UTF-32 code points in the given
+// byte order and returns it as UTF-8. Invalid code points (surrogates and
+// values beyond U+10FFFF) are replaced with U+FFFD.
+func ReadUTF32String(bin Reader, order Order, n int) (string, error) {
+	return ReplaceSurrogates.ReadUTF32String(bin, order, n)
+}
+
+// ReadUTF32String is like the package-level function ReadUTF32String, but
+// treats surrogates and values beyond U+10FFFF according to p.
+func (p SurrogatePolicy) ReadUTF32String(bin Reader, order Order,
+	n int) (string, error) {
+	if n <= 0 {
+		return "", nil
+	}
+	if n > maxIntValue/4 {
+		return "", &LengthError{Length: uint64(n),
+			Max: uint64(maxIntValue / 4)}
+	}
+	buf, err := ReadBytes(bin, 4*n)
+	if err != nil {
+		return "", err
+	}
+	bo := binaryOrder(order)
+	var sb strings.Builder
+	sb.Grow(len(buf) / 4)
+	for i := 0; i < len(buf)/4; i++ {
+		r, err := p.utf32Rune(bo.Uint32(buf[4*i:]), i)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String(), nil
+}
+
+// ReadUTF32ZString reads a NUL-terminated UTF-32 string in the given byte
+// order, as for ReadUTF32String. The terminator is consumed but not returned.
+// If no terminator is found within max code points, a *LengthError is
+// returned.
+func ReadUTF32ZString(bin Reader, order Order, max int) (string, error) {
+	return ReplaceSurrogates.ReadUTF32ZString(bin, order, max)
+}
+
+// ReadUTF32ZString is like the package-level function ReadUTF32ZString, but
+// treats surrogates and values beyond U+10FFFF according to p.
+func (p SurrogatePolicy) ReadUTF32ZString(bin Reader, order Order,
+	max int) (string, error) {
+	var (
+		runes []rune
+		bad   error
+	)
+	for {
+		u, err := order.ReadUint32(bin)
+		if err != nil {
+			return "", midRecord(runes != nil, err)
+		}
+		if u == 0 {
+			if bad != nil {
+				return "", bad
+			}
+			return string(runes), nil
+		}
+		if len(runes) >= max {
+			return "", &LengthError{Length: uint64(len(runes)) + 1,
+				Max: uint64(maxInt(max, 0))}
+		}
+		r, err := p.utf32Rune(u, len(runes))
+		if err != nil && bad == nil {
+			// consume the rest of the string before reporting
+			bad = err
+		}
+		runes = append(runes, r)
+	}
+}
+
+// utf32Rune converts the UTF-32 code point u at index i, applying policy p to
+// invalid values.
+func (p SurrogatePolicy) utf32Rune(u uint32, i int) (rune, error) {
+	if u > utf8.MaxRune || !utf8.ValidRune(rune(u)) {
+		if p == RejectSurrogates {
+			return 0, &InvalidUnitError{Index: i, Unit: u}
+		}
+		return utf8.RuneError, nil
+	}
+	return rune(u), nil
+}
+
+// WriteUTF32String writes s as UTF-32 in the given byte order, without any
+// length prefix or terminator. Invalid UTF-8 is written as U+FFFD.
+func WriteUTF32String(bout Writer, order Order, s string) error {
+	for _, r := range s {
+		if err := order.WriteUint32(bout, uint32(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteUTF32ZString writes s as UTF-32 in the given byte order, followed by a
+// NUL terminator. An error is returned if s itself contains NUL.
+func WriteUTF32ZString(bout Writer, order Order, s string) error {
+	if strings.IndexByte(s, 0) >= 0 {
+		return errEmbeddedNUL
+	}
+	if err := WriteUTF32String(bout, order, s); err != nil {
+		return err
+	}
+	return order.WriteUint32(bout, 0)
+}
@@ -0,0 +1,178 @@
+package byteio_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// TestReadUTF16String checks decoding of BMP characters, surrogate pairs and
+// unpaired surrogates in both byte orders.
+func TestReadUTF16String(t *testing.T) {
+	units := []uint16{'a', 0x20AC, 0xD83D, 0xDE00, 0xDC00, 'b', 0xD800}
+	exp := "a€😀�b�"
+
+	for _, order := range []byteio.Order{byteio.BigEndian, byteio.LittleEndian} {
+		buf := bytes.NewBuffer(nil)
+		for _, u := range units {
+			order.WriteUint16(buf, u)
+		}
+		act, err := byteio.ReadUTF16String(buf, order, len(units))
+		if err != nil {
+			t.Errorf("%v: unexpected error %v", order, err)
+		} else if act != exp {
+			t.Errorf("%v: act %q ≠ exp %q", order, act, exp)
+		}
+	}
+}
+
+// TestReadUTF16ZString checks NUL-terminated decoding, the length limit and
+// truncation errors.
+func TestReadUTF16ZString(t *testing.T) {
+	in := []byte{'h', 0, 'i', 0, 0, 0, 'x', 0}
+	bin := bytes.NewReader(in)
+	if act, err := byteio.ReadUTF16ZString(bin, byteio.LittleEndian, 2); err != nil || act != "hi" {
+		t.Errorf("act %q/%v ≠ exp \"hi\"", act, err)
+	}
+	if b, _ := bin.ReadByte(); b != 'x' {
+		t.Errorf("terminator not consumed (next byte %X)", b)
+	}
+
+	_, err := byteio.ReadUTF16ZString(bytes.NewReader(in), byteio.LittleEndian, 1)
+	if le, ok := err.(*byteio.LengthError); !ok || le.Max != 1 {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	for i, exp := range []error{io.EOF, io.ErrUnexpectedEOF, io.ErrUnexpectedEOF, io.ErrUnexpectedEOF} {
+		_, err := byteio.ReadUTF16ZString(bytes.NewReader(in[:i]), byteio.LittleEndian, 10)
+		if err != exp {
+			t.Errorf("%d bytes: act %v ≠ exp %v", i, err, exp)
+		}
+	}
+}
+
+// TestWriteUTF16String checks encoding, including surrogate pairs and
+// invalid UTF-8.
+func TestWriteUTF16String(t *testing.T) {
+	in := "a€😀\xFF"
+	exp := []byte{0x00, 'a', 0x20, 0xAC, 0xD8, 0x3D, 0xDE, 0x00, 0xFF, 0xFD}
+
+	buf := bytes.NewBuffer(nil)
+	if err := byteio.WriteUTF16String(buf, byteio.BigEndian, in); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), exp) {
+		t.Errorf("act % X ≠ exp % X", buf.Bytes(), exp)
+	}
+	if act := byteio.UTF16Len(in); act != len(exp)/2 {
+		t.Errorf("UTF16Len: act %d ≠ exp %d", act, len(exp)/2)
+	}
+
+	buf.Reset()
+	if err := byteio.WriteUTF16ZString(buf, byteio.LittleEndian, "ok"); err != nil {
+		t.Fatal(err)
+	}
+	if exp := []byte{'o', 0, 'k', 0, 0, 0}; !bytes.Equal(buf.Bytes(), exp) {
+		t.Errorf("act % X ≠ exp % X", buf.Bytes(), exp)
+	}
+	if err := byteio.WriteUTF16ZString(buf, byteio.LittleEndian, "a\x00b"); err == nil {
+		t.Error("expected error for embedded NUL")
+	}
+	if err := byteio.WriteUTF16String(&AbortWriter{when: 1}, byteio.LittleEndian, "ab"); err != ErrAbortWriter {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+// TestUTF32 checks round-tripping of UTF-32 strings and replacement of
+// invalid code points.
+func TestUTF32(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	if err := byteio.WriteUTF32ZString(buf, byteio.LittleEndian, "a😀"); err != nil {
+		t.Fatal(err)
+	}
+	exp := []byte{'a', 0, 0, 0, 0x00, 0xF6, 0x01, 0x00, 0, 0, 0, 0}
+	if !bytes.Equal(buf.Bytes(), exp) {
+		t.Errorf("act % X ≠ exp % X", buf.Bytes(), exp)
+	}
+	if act, err := byteio.ReadUTF32ZString(buf, byteio.LittleEndian, 8); err != nil || act != "a😀" {
+		t.Errorf("act %q/%v", act, err)
+	}
+
+	for _, u := range []uint32{0xD800, 0x110000, 0xFFFFFFFF} {
+		buf.Reset()
+		byteio.WriteUint32BE(buf, u)
+		act, err := byteio.ReadUTF32String(buf, byteio.BigEndian, 1)
+		if err != nil || act != "�" {
+			t.Errorf("%X: act %q/%v ≠ exp U+FFFD", u, act, err)
+		}
+	}
+
+	if _, err := byteio.ReadUTF32String(bytes.NewReader(make([]byte, 6)), byteio.BigEndian, 2); err != io.ErrUnexpectedEOF {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := byteio.ReadUTF32ZString(bytes.NewReader([]byte{1, 0, 0, 0}), byteio.LittleEndian, 0); err == nil {
+		t.Error("expected *LengthError")
+	}
+}
+
+// TestSurrogatePolicy checks that RejectSurrogates reports the first invalid
+// code unit after consuming the whole string.
+func TestSurrogatePolicy(t *testing.T) {
+	p := byteio.RejectSurrogates
+	for _, tc := range []struct {
+		units []uint16
+		index int
+		unit  uint32
+	}{
+		{[]uint16{'a', 0xDC00, 'b'}, 1, 0xDC00},
+		{[]uint16{0xD83D, 0xDE00, 0xD800}, 2, 0xD800},
+		{[]uint16{0xD800, 'x'}, 0, 0xD800},
+	} {
+		buf := bytes.NewBuffer(nil)
+		for _, u := range tc.units {
+			byteio.WriteUint16LE(buf, u)
+		}
+		buf.Write([]byte{0, 0, 0xAA})
+		_, err := p.ReadUTF16ZString(buf, byteio.LittleEndian, 10)
+		ie, ok := err.(*byteio.InvalidUnitError)
+		if !ok || ie.Index != tc.index || ie.Unit != tc.unit {
+			t.Errorf("%X: unexpected error %v", tc.units, err)
+		}
+		if b, _ := buf.ReadByte(); b != 0xAA {
+			t.Errorf("%X: string not consumed (next byte %X)", tc.units, b)
+		}
+	}
+
+	in := []byte{0xD8, 0x3D, 0xDE, 0x00}
+	if act, err := p.ReadUTF16String(bytes.NewReader(in), byteio.BigEndian, 2); err != nil || act != "😀" {
+		t.Errorf("act %q/%v ≠ exp \"😀\"", act, err)
+	}
+
+	in = []byte{'a', 0, 0, 0, 0, 0, 0x11, 0, 0, 0, 0, 0, 0xAA}
+	bin := bytes.NewReader(in)
+	_, err := p.ReadUTF32ZString(bin, byteio.LittleEndian, 10)
+	if ie, ok := err.(*byteio.InvalidUnitError); !ok || ie.Index != 1 || ie.Unit != 0x110000 {
+		t.Errorf("unexpected error %v", err)
+	}
+	if b, _ := bin.ReadByte(); b != 0xAA {
+		t.Errorf("string not consumed (next byte %X)", b)
+	}
+	if _, err = p.ReadUTF32String(bytes.NewReader(in), byteio.LittleEndian, 2); err == nil {
+		t.Error("expected *InvalidUnitError")
+	}
+}
+
+// TestUTFHugeCount checks that a count too large to express in bytes is
+// rejected rather than overflowing.
+func TestUTFHugeCount(t *testing.T) {
+	const maxInt = int(^uint(0) >> 1)
+	bin := bytes.NewReader([]byte{1, 2, 3, 4})
+	if _, err := byteio.ReadUTF16String(bin, byteio.BigEndian, maxInt/2+1); err == nil {
+		t.Error("UTF-16: expected *LengthError")
+	}
+	if _, err := byteio.ReadUTF32String(bin, byteio.BigEndian, maxInt/4+1); err == nil {
+		t.Error("UTF-32: expected *LengthError")
+	}
+}
@@ -0,0 +1,18 @@
+package byteio
+
+// maxIntValue is the largest value of type int.
+const maxIntValue = int(^uint(0) >> 1)
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -1,271 +1,427 @@
 package byteio
 
 import (
-	"io"
+	"errors"
 	"math"
 )
 
 // ReadUint16BE reads an unsigned uint16 in big-endian (network) byte order.
 func ReadUint16BE(bin Reader) (uint16, error) {
+	return readUint16BE(bin, "uint16BE")
+}
+
+// readUint16BE is ReadUint16BE, with the value traced as kind.
+func readUint16BE(bin Reader, kind string) (uint16, error) {
 	var b0, b1 byte
 	var err error
 	if b0, err = bin.ReadByte(); err != nil {
-		// allow io.EOF to propagate normally before first read
-		return 0, err
+		return 0, readErr(bin, 0, err)
 	}
 	if b1, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 1, err)
 	}
-	return uint16(b0)<<8 | uint16(b1), nil
+	n := uint16(b0)<<8 | uint16(b1)
+	traceValue(bin, 2, kind, uint64(n))
+	return n, nil
 }
 
 // ReadInt16BE reads a signed int16 in big-endian (network) byte order.
 func ReadInt16BE(bin Reader) (int16, error) {
-	n, err := ReadUint16BE(bin)
+	n, err := readUint16BE(bin, "int16BE")
 	return int16(n), err
 }
 
+// ReadUint24BE reads an unsigned 24-bit integer in big-endian (network) byte
+// order.
+func ReadUint24BE(bin Reader) (uint32, error) {
+	return readUint24BE(bin, "uint24BE")
+}
+
+// readUint24BE is ReadUint24BE, with the value traced as kind.
+func readUint24BE(bin Reader, kind string) (uint32, error) {
+	var b0, b1, b2 byte
+	var err error
+	if b0, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 0, err)
+	}
+	if b1, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 1, err)
+	}
+	if b2, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 2, err)
+	}
+	n := uint32(b0)<<16 | uint32(b1)<<8 | uint32(b2)
+	traceValue(bin, 3, kind, uint64(n))
+	return n, nil
+}
+
+// ReadInt24BE reads a signed 24-bit integer in big-endian (network) byte order,
+// sign-extending it to an int32.
+func ReadInt24BE(bin Reader) (int32, error) {
+	n, err := readUint24BE(bin, "int24BE")
+	return int32(n<<8) >> 8, err
+}
+
 // ReadUint32BE reads an unsigned uint32 in big-endian (network) byte order.
 func ReadUint32BE(bin Reader) (uint32, error) {
+	return readUint32BE(bin, "uint32BE")
+}
+
+// readUint32BE is ReadUint32BE, with the value traced as kind.
+func readUint32BE(bin Reader, kind string) (uint32, error) {
 	var b0, b1, b2, b3 byte
 	var err error
 	if b0, err = bin.ReadByte(); err != nil {
-		return 0, err
+		return 0, readErr(bin, 0, err)
 	}
 	if b1, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 1, err)
 	}
 	if b2, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 2, err)
 	}
 	if b3, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 3, err)
 	}
-	return uint32(b0)<<24 | uint32(b1)<<16 |
-		uint32(b2)<<8 | uint32(b3), nil
+	n := uint32(b0)<<24 | uint32(b1)<<16 |
+		uint32(b2)<<8 | uint32(b3)
+	traceValue(bin, 4, kind, uint64(n))
+	return n, nil
 }
 
 // ReadInt32BE reads a signed int32 in big-endian (network) byte order.
 func ReadInt32BE(bin Reader) (int32, error) {
-	n, err := ReadUint32BE(bin)
+	n, err := readUint32BE(bin, "int32BE")
 	return int32(n), err
 }
 
 // ReadFloat32BE reads an IEEE-754 32-bit floating point number in big-endian
 // (network) byte order.
 func ReadFloat32BE(bin Reader) (float32, error) {
-	n, err := ReadUint32BE(bin)
+	n, err := readUint32BE(bin, "float32BE")
 	return math.Float32frombits(n), err
 }
 
+// ReadUint48BE reads an unsigned 48-bit integer in big-endian (network) byte
+// order.
+func ReadUint48BE(bin Reader) (uint64, error) {
+	return readUint48BE(bin, "uint48BE")
+}
+
+// readUint48BE is ReadUint48BE, with the value traced as kind.
+func readUint48BE(bin Reader, kind string) (uint64, error) {
+	var b0, b1, b2, b3, b4, b5 byte
+	var err error
+	if b0, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 0, err)
+	}
+	if b1, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 1, err)
+	}
+	if b2, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 2, err)
+	}
+	if b3, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 3, err)
+	}
+	if b4, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 4, err)
+	}
+	if b5, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 5, err)
+	}
+	n := uint64(b0)<<40 | uint64(b1)<<32 |
+		uint64(b2)<<24 | uint64(b3)<<16 |
+		uint64(b4)<<8 | uint64(b5)
+	traceValue(bin, 6, kind, n)
+	return n, nil
+}
+
+// ReadInt48BE reads a signed 48-bit integer in big-endian (network) byte order,
+// sign-extending it to an int64.
+func ReadInt48BE(bin Reader) (int64, error) {
+	n, err := readUint48BE(bin, "int48BE")
+	return int64(n<<16) >> 16, err
+}
+
 // ReadUint64BE reads an unsigned uint64 in big-endian (network) byte order.
 func ReadUint64BE(bin Reader) (uint64, error) {
+	return readUint64BE(bin, "uint64BE")
+}
+
+// readUint64BE is ReadUint64BE, with the value traced as kind.
+func readUint64BE(bin Reader, kind string) (uint64, error) {
 	var b0, b1, b2, b3, b4, b5, b6, b7 byte
 	var err error
 	if b0, err = bin.ReadByte(); err != nil {
-		return 0, err
+		return 0, readErr(bin, 0, err)
 	}
 	if b1, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 1, err)
 	}
 	if b2, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 2, err)
 	}
 	if b3, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 3, err)
 	}
 	if b4, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 4, err)
 	}
 	if b5, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 5, err)
 	}
 	if b6, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 6, err)
 	}
 	if b7, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 7, err)
 	}
-	return uint64(b0)<<56 | uint64(b1)<<48 |
+	n := uint64(b0)<<56 | uint64(b1)<<48 |
 		uint64(b2)<<40 | uint64(b3)<<32 |
 		uint64(b4)<<24 | uint64(b5)<<16 |
-		uint64(b6)<<8 | uint64(b7), nil
+		uint64(b6)<<8 | uint64(b7)
+	traceValue(bin, 8, kind, n)
+	return n, nil
 }
 
 // ReadInt64BE reads a signed int64 in big-endian (network) byte order.
 func ReadInt64BE(bin Reader) (int64, error) {
-	n, err := ReadUint64BE(bin)
+	n, err := readUint64BE(bin, "int64BE")
 	return int64(n), err
 }
 
 // ReadFloat64BE reads an IEEE-754 64-bit floating point number in big-endian
 // (network) byte order.
 func ReadFloat64BE(bin Reader) (float64, error) {
-	n, err := ReadUint64BE(bin)
+	n, err := readUint64BE(bin, "float64BE")
 	return math.Float64frombits(n), err
 }
 
 // ReadUint16LE reads an unsigned uint16 in little-endian byte order.
 func ReadUint16LE(bin Reader) (uint16, error) {
+	return readUint16LE(bin, "uint16LE")
+}
+
+// readUint16LE is ReadUint16LE, with the value traced as kind.
+func readUint16LE(bin Reader, kind string) (uint16, error) {
 	var b0, b1 byte
 	var err error
 	if b1, err = bin.ReadByte(); err != nil {
-		return 0, err
+		return 0, readErr(bin, 0, err)
 	}
 	if b0, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 1, err)
 	}
-	return uint16(b0)<<8 | uint16(b1), nil
+	n := uint16(b0)<<8 | uint16(b1)
+	traceValue(bin, 2, kind, uint64(n))
+	return n, nil
 }
 
-// ReadUint16LE reads a signed int16 in little-endian byte order.
+// ReadInt16LE reads a signed int16 in little-endian byte order.
 func ReadInt16LE(bin Reader) (int16, error) {
-	n, err := ReadUint16LE(bin)
+	n, err := readUint16LE(bin, "int16LE")
 	return int16(n), err
 }
 
+// ReadUint24LE reads an unsigned 24-bit integer in little-endian byte order.
+func ReadUint24LE(bin Reader) (uint32, error) {
+	return readUint24LE(bin, "uint24LE")
+}
+
+// readUint24LE is ReadUint24LE, with the value traced as kind.
+func readUint24LE(bin Reader, kind string) (uint32, error) {
+	var b0, b1, b2 byte
+	var err error
+	if b2, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 0, err)
+	}
+	if b1, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 1, err)
+	}
+	if b0, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 2, err)
+	}
+	n := uint32(b0)<<16 | uint32(b1)<<8 | uint32(b2)
+	traceValue(bin, 3, kind, uint64(n))
+	return n, nil
+}
+
+// ReadInt24LE reads a signed 24-bit integer in little-endian byte order,
+// sign-extending it to an int32.
+func ReadInt24LE(bin Reader) (int32, error) {
+	n, err := readUint24LE(bin, "int24LE")
+	return int32(n<<8) >> 8, err
+}
+
 // ReadUint32LE reads an unsigned uint32 in little-endian byte order.
 func ReadUint32LE(bin Reader) (uint32, error) {
+	return readUint32LE(bin, "uint32LE")
+}
+
+// readUint32LE is ReadUint32LE, with the value traced as kind.
+func readUint32LE(bin Reader, kind string) (uint32, error) {
 	var b0, b1, b2, b3 byte
 	var err error
 	if b3, err = bin.ReadByte(); err != nil {
-		return 0, err
+		return 0, readErr(bin, 0, err)
 	}
 	if b2, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 1, err)
 	}
 	if b1, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 2, err)
 	}
 	if b0, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 3, err)
 	}
-	return uint32(b0)<<24 | uint32(b1)<<16 |
-		uint32(b2)<<8 | uint32(b3), nil
+	n := uint32(b0)<<24 | uint32(b1)<<16 |
+		uint32(b2)<<8 | uint32(b3)
+	traceValue(bin, 4, kind, uint64(n))
+	return n, nil
 }
 
-// ReadUint32LE reads a signed int32 in little-endian byte order.
+// ReadInt32LE reads a signed int32 in little-endian byte order.
 func ReadInt32LE(bin Reader) (int32, error) {
-	n, err := ReadUint32LE(bin)
+	n, err := readUint32LE(bin, "int32LE")
 	return int32(n), err
 }
 
-// ReadFloat32LE reads an IEEE-754 32-bit floating point number in big-endian
-// (network) byte order.
+// ReadFloat32LE reads an IEEE-754 32-bit floating point number in
+// little-endian byte order.
 func ReadFloat32LE(bin Reader) (float32, error) {
-	n, err := ReadUint32LE(bin)
+	n, err := readUint32LE(bin, "float32LE")
 	return math.Float32frombits(n), err
 }
 
+// ReadUint48LE reads an unsigned 48-bit integer in little-endian byte order.
+func ReadUint48LE(bin Reader) (uint64, error) {
+	return readUint48LE(bin, "uint48LE")
+}
+
+// readUint48LE is ReadUint48LE, with the value traced as kind.
+func readUint48LE(bin Reader, kind string) (uint64, error) {
+	var b0, b1, b2, b3, b4, b5 byte
+	var err error
+	if b5, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 0, err)
+	}
+	if b4, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 1, err)
+	}
+	if b3, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 2, err)
+	}
+	if b2, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 3, err)
+	}
+	if b1, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 4, err)
+	}
+	if b0, err = bin.ReadByte(); err != nil {
+		return 0, readErr(bin, 5, err)
+	}
+	n := uint64(b0)<<40 | uint64(b1)<<32 |
+		uint64(b2)<<24 | uint64(b3)<<16 |
+		uint64(b4)<<8 | uint64(b5)
+	traceValue(bin, 6, kind, n)
+	return n, nil
+}
+
+// ReadInt48LE reads a signed 48-bit integer in little-endian byte order,
+// sign-extending it to an int64.
+func ReadInt48LE(bin Reader) (int64, error) {
+	n, err := readUint48LE(bin, "int48LE")
+	return int64(n<<16) >> 16, err
+}
+
 // ReadUint64LE reads an unsigned uint64 in little-endian byte order.
 func ReadUint64LE(bin Reader) (uint64, error) {
+	return readUint64LE(bin, "uint64LE")
+}
+
+// readUint64LE is ReadUint64LE, with the value traced as kind.
+func readUint64LE(bin Reader, kind string) (uint64, error) {
 	var b0, b1, b2, b3, b4, b5, b6, b7 byte
 	var err error
 	if b7, err = bin.ReadByte(); err != nil {
-		return 0, err
+		return 0, readErr(bin, 0, err)
 	}
 	if b6, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 1, err)
 	}
 	if b5, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 2, err)
 	}
 	if b4, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 3, err)
 	}
 	if b3, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 4, err)
 	}
 	if b2, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 5, err)
 	}
 	if b1, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 6, err)
 	}
 	if b0, err = bin.ReadByte(); err != nil {
-		if err == io.EOF {
-			return 0, io.ErrUnexpectedEOF
-		}
-		return 0, err
+		return 0, readErr(bin, 7, err)
 	}
-	return uint64(b0)<<56 | uint64(b1)<<48 |
+	n := uint64(b0)<<56 | uint64(b1)<<48 |
 		uint64(b2)<<40 | uint64(b3)<<32 |
 		uint64(b4)<<24 | uint64(b5)<<16 |
-		uint64(b6)<<8 | uint64(b7), nil
+		uint64(b6)<<8 | uint64(b7)
+	traceValue(bin, 8, kind, n)
+	return n, nil
 }
 
 // ReadInt64LE reads a signed int64 in little-endian byte order.
 func ReadInt64LE(bin Reader) (int64, error) {
-	n, err := ReadUint64LE(bin)
+	n, err := readUint64LE(bin, "int64LE")
 	return int64(n), err
 }
 
-// ReadFloat64LE reads an IEEE-754 64-bit floating point number in big-endian
-// (network) byte order.
+// ReadFloat64LE reads an IEEE-754 64-bit floating point number in
+// little-endian byte order.
 func ReadFloat64LE(bin Reader) (float64, error) {
-	n, err := ReadUint64LE(bin)
+	n, err := readUint64LE(bin, "float64LE")
 	return math.Float64frombits(n), err
 }
+
+var errWidth = errors.New("byteio: integer width must be between 1 and 8 bytes")
+
+// ReadUintN reads an unsigned integer of width bytes (1 ≤ width ≤ 8) in the
+// given byte order.
+func ReadUintN(bin Reader, width int, order Order) (uint64, error) {
+	return readUintN(bin, width, order, "uintN")
+}
+
+// readUintN is ReadUintN, with the value traced as kind.
+func readUintN(bin Reader, width int, order Order, kind string) (uint64, error) {
+	if width < 1 || width > 8 {
+		return 0, errWidth
+	}
+	var n uint64
+	for i := 0; i < width; i++ {
+		b, err := bin.ReadByte()
+		if err != nil {
+			return 0, readErr(bin, i, err)
+		}
+		if order == LittleEndian {
+			n |= uint64(b) << uint(8*i)
+		} else {
+			n = n<<8 | uint64(b)
+		}
+	}
+	traceValue(bin, width, kind, n)
+	return n, nil
+}
+
+// ReadIntN reads a signed integer of width bytes (1 ≤ width ≤ 8) in the given
+// byte order, sign-extending it to an int64.
+func ReadIntN(bin Reader, width int, order Order) (int64, error) {
+	n, err := readUintN(bin, width, order, "intN")
+	shift := uint(64 - 8*width)
+	return int64(n<<shift) >> shift, err
+}
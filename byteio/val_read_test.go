@@ -224,7 +224,13 @@ func TestReadIntEOF(t *testing.T) {
 	check("ReadUint32BE", func() error { _, err := byteio.ReadUint32BE(bin); return err })
 	check("ReadUint64LE", func() error { _, err := byteio.ReadUint64LE(bin); return err })
 	check("ReadUint64BE", func() error { _, err := byteio.ReadUint64BE(bin); return err })
-
+	check("ReadUint24LE", func() error { _, err := byteio.ReadUint24LE(bin); return err })
+	check("ReadUint24BE", func() error { _, err := byteio.ReadUint24BE(bin); return err })
+	check("ReadUint48LE", func() error { _, err := byteio.ReadUint48LE(bin); return err })
+	check("ReadUint48BE", func() error { _, err := byteio.ReadUint48BE(bin); return err })
+	for w := 1; w <= 8; w++ {
+		check("ReadUintN", func() error { _, err := byteio.ReadUintN(bin, w, byteio.BigEndian); return err })
+	}
 }
 
 // TestReadIntShort ensures that io.ErrUnexpectedEOF is returned when a ReadInt
@@ -257,6 +263,13 @@ func TestReadIntShort(t *testing.T) {
 	check("ReadUint32BE", 4, func(bin byteio.Reader) error { _, err := byteio.ReadUint32BE(bin); return err })
 	check("ReadUint64LE", 8, func(bin byteio.Reader) error { _, err := byteio.ReadUint64LE(bin); return err })
 	check("ReadUint64BE", 8, func(bin byteio.Reader) error { _, err := byteio.ReadUint64BE(bin); return err })
+	check("ReadUint24LE", 3, func(bin byteio.Reader) error { _, err := byteio.ReadUint24LE(bin); return err })
+	check("ReadUint24BE", 3, func(bin byteio.Reader) error { _, err := byteio.ReadUint24BE(bin); return err })
+	check("ReadUint48LE", 6, func(bin byteio.Reader) error { _, err := byteio.ReadUint48LE(bin); return err })
+	check("ReadUint48BE", 6, func(bin byteio.Reader) error { _, err := byteio.ReadUint48BE(bin); return err })
+	for w := 1; w <= 8; w++ {
+		check("ReadUintN", w, func(bin byteio.Reader) error { _, err := byteio.ReadUintN(bin, w, byteio.LittleEndian); return err })
+	}
 }
 
 // ErrAbortReader is returned by AbortReader when the limit is reached.
@@ -310,6 +323,66 @@ func TestReadIntErr(t *testing.T) {
 	check("ReadUint16LE", 2, func(bin byteio.Reader) error { _, err := byteio.ReadUint16LE(bin); return err })
 	check("ReadUint32LE", 4, func(bin byteio.Reader) error { _, err := byteio.ReadUint32LE(bin); return err })
 	check("ReadUint64LE", 8, func(bin byteio.Reader) error { _, err := byteio.ReadUint64LE(bin); return err })
+	check("ReadUint24BE", 3, func(bin byteio.Reader) error { _, err := byteio.ReadUint24BE(bin); return err })
+	check("ReadUint48BE", 6, func(bin byteio.Reader) error { _, err := byteio.ReadUint48BE(bin); return err })
+	check("ReadUint24LE", 3, func(bin byteio.Reader) error { _, err := byteio.ReadUint24LE(bin); return err })
+	check("ReadUint48LE", 6, func(bin byteio.Reader) error { _, err := byteio.ReadUint48LE(bin); return err })
+	check("ReadUintN", 5, func(bin byteio.Reader) error { _, err := byteio.ReadUintN(bin, 5, byteio.BigEndian); return err })
+}
+
+// TestReadOddWidths verifies the 24- and 48-bit readers, and ReadUintN at
+// every width, against known encodings.
+func TestReadOddWidths(t *testing.T) {
+	in := []byte{0x81, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x88}
+	check := func(name string, act, exp interface{}, err error) {
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		} else if act != exp {
+			t.Errorf("%s: act %X ≠ exp %X", name, act, exp)
+		}
+	}
+
+	u24, err := byteio.ReadUint24BE(bytes.NewReader(in))
+	check("ReadUint24BE", u24, uint32(0x810203), err)
+	u24, err = byteio.ReadUint24LE(bytes.NewReader(in))
+	check("ReadUint24LE", u24, uint32(0x030281), err)
+	i24, err := byteio.ReadInt24BE(bytes.NewReader(in))
+	check("ReadInt24BE", i24, int32(-0x7EFDFD), err)
+	i24, err = byteio.ReadInt24LE(bytes.NewReader(in))
+	check("ReadInt24LE", i24, int32(0x030281), err)
+
+	u48, err := byteio.ReadUint48BE(bytes.NewReader(in))
+	check("ReadUint48BE", u48, uint64(0x810203040506), err)
+	u48, err = byteio.ReadUint48LE(bytes.NewReader(in))
+	check("ReadUint48LE", u48, uint64(0x060504030281), err)
+	i48, err := byteio.ReadInt48BE(bytes.NewReader(in))
+	check("ReadInt48BE", i48, int64(-0x7EFDFCFBFAFA), err)
+	i48, err = byteio.ReadInt48LE(bytes.NewReader(in[2:]))
+	check("ReadInt48LE", i48, int64(-0x77F8F9FAFBFD), err)
+
+	for w := 1; w <= 8; w++ {
+		var be, le uint64
+		for i := 0; i < w; i++ {
+			be = be<<8 | uint64(in[i])
+			le |= uint64(in[i]) << uint(8*i)
+		}
+		shift := uint(64 - 8*w)
+
+		act, err := byteio.ReadUintN(bytes.NewReader(in), w, byteio.BigEndian)
+		check("ReadUintN/BE", act, be, err)
+		act, err = byteio.ReadUintN(bytes.NewReader(in), w, byteio.LittleEndian)
+		check("ReadUintN/LE", act, le, err)
+		sact, err := byteio.ReadIntN(bytes.NewReader(in), w, byteio.BigEndian)
+		check("ReadIntN/BE", sact, int64(be<<shift)>>shift, err)
+		sact, err = byteio.ReadIntN(bytes.NewReader(in), w, byteio.LittleEndian)
+		check("ReadIntN/LE", sact, int64(le<<shift)>>shift, err)
+	}
+
+	for _, w := range []int{0, 9} {
+		if _, err := byteio.ReadUintN(bytes.NewReader(in), w, byteio.BigEndian); err == nil {
+			t.Errorf("ReadUintN(%d): expected error", w)
+		}
+	}
 }
 
 // BenchmarkReadUint32BE is a simple benchmark for reading 32-bit integers.
@@ -327,3 +400,72 @@ func BenchmarkReadUint64BE(b *testing.B) {
 		_, _ = byteio.ReadUint64BE(bin)
 	}
 }
+
+// BenchmarkReadInt32BE is a simple benchmark for reading signed 32-bit
+// integers, which share the unsigned reader.
+func BenchmarkReadInt32BE(b *testing.B) {
+	bin := byteio.NewReader(new(MockReader))
+	for i := 0; i < b.N; i++ {
+		_, _ = byteio.ReadInt32BE(bin)
+	}
+}
+
+// BenchmarkReadFloat64BE is a simple benchmark for reading 64-bit floats.
+func BenchmarkReadFloat64BE(b *testing.B) {
+	bin := byteio.NewReader(new(MockReader))
+	for i := 0; i < b.N; i++ {
+		_, _ = byteio.ReadFloat64BE(bin)
+	}
+}
+
+// BenchmarkReadUint32sLE measures bulk reading of 32-bit integers, for
+// comparison with BenchmarkReadUint32LELoop.
+func BenchmarkReadUint32sLE(b *testing.B) {
+	in := make([]byte, 4096*4)
+	dst := make([]uint32, 4096)
+	bin := bytes.NewReader(in)
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		bin.Reset(in)
+		_ = byteio.ReadUint32sLE(bin, dst)
+	}
+}
+
+// BenchmarkReadUint32LELoop reads the same data as BenchmarkReadUint32sLE with
+// repeated calls to ReadUint32LE.
+func BenchmarkReadUint32LELoop(b *testing.B) {
+	in := make([]byte, 4096*4)
+	dst := make([]uint32, 4096)
+	bin := bytes.NewReader(in)
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		bin.Reset(in)
+		for j := range dst {
+			dst[j], _ = byteio.ReadUint32LE(bin)
+		}
+	}
+}
+
+// BenchmarkReadFloat64sBE measures bulk reading of 64-bit floats through a
+// bufio.Reader, for comparison with BenchmarkReadFloat64BELoop.
+func BenchmarkReadFloat64sBE(b *testing.B) {
+	bin := byteio.NewReader(new(MockReader))
+	dst := make([]float64, 4096)
+	b.SetBytes(int64(len(dst) * 8))
+	for i := 0; i < b.N; i++ {
+		_ = byteio.ReadFloat64sBE(bin, dst)
+	}
+}
+
+// BenchmarkReadFloat64BELoop reads the same data as BenchmarkReadFloat64sBE
+// with repeated calls to ReadFloat64BE.
+func BenchmarkReadFloat64BELoop(b *testing.B) {
+	bin := byteio.NewReader(new(MockReader))
+	dst := make([]float64, 4096)
+	b.SetBytes(int64(len(dst) * 8))
+	for i := 0; i < b.N; i++ {
+		for j := range dst {
+			dst[j], _ = byteio.ReadFloat64BE(bin)
+		}
+	}
+}
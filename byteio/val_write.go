@@ -15,6 +15,24 @@ func WriteInt16BE(bout Writer, i int16) error {
 	return WriteUint16BE(bout, uint16(i))
 }
 
+// WriteUint24BE writes the low 24 bits of n as an unsigned integer in
+// big-endian (network) byte order.
+func WriteUint24BE(bout Writer, n uint32) error {
+	if err := bout.WriteByte(byte(n >> 16)); err != nil {
+		return err
+	}
+	if err := bout.WriteByte(byte(n >> 8)); err != nil {
+		return err
+	}
+	return bout.WriteByte(byte(n))
+}
+
+// WriteInt24BE writes the low 24 bits of i as a signed integer in big-endian
+// (network) byte order.
+func WriteInt24BE(bout Writer, i int32) error {
+	return WriteUint24BE(bout, uint32(i))
+}
+
 // WriteUint32BE writes an unsigned uint32 in big-endian (network) byte order.
 func WriteUint32BE(bout Writer, n uint32) error {
 	if err := bout.WriteByte(byte(n >> 24)); err != nil {
@@ -40,6 +58,33 @@ func WriteFloat32BE(bout Writer, f float32) error {
 	return WriteUint32BE(bout, math.Float32bits(f))
 }
 
+// WriteUint48BE writes the low 48 bits of n as an unsigned integer in
+// big-endian (network) byte order.
+func WriteUint48BE(bout Writer, n uint64) error {
+	if err := bout.WriteByte(byte(n >> 40)); err != nil {
+		return err
+	}
+	if err := bout.WriteByte(byte(n >> 32)); err != nil {
+		return err
+	}
+	if err := bout.WriteByte(byte(n >> 24)); err != nil {
+		return err
+	}
+	if err := bout.WriteByte(byte(n >> 16)); err != nil {
+		return err
+	}
+	if err := bout.WriteByte(byte(n >> 8)); err != nil {
+		return err
+	}
+	return bout.WriteByte(byte(n))
+}
+
+// WriteInt48BE writes the low 48 bits of i as a signed integer in big-endian
+// (network) byte order.
+func WriteInt48BE(bout Writer, i int64) error {
+	return WriteUint48BE(bout, uint64(i))
+}
+
 // WriteUint64BE writes an unsigned uint64 in big-endian (network) byte order.
 func WriteUint64BE(bout Writer, n uint64) error {
 	if err := bout.WriteByte(byte(n >> 56)); err != nil {
@@ -90,6 +135,24 @@ func WriteInt16LE(bout Writer, i int16) error {
 	return WriteUint16LE(bout, uint16(i))
 }
 
+// WriteUint24LE writes the low 24 bits of n as an unsigned integer in
+// little-endian byte order.
+func WriteUint24LE(bout Writer, n uint32) error {
+	if err := bout.WriteByte(byte(n)); err != nil {
+		return err
+	}
+	if err := bout.WriteByte(byte(n >> 8)); err != nil {
+		return err
+	}
+	return bout.WriteByte(byte(n >> 16))
+}
+
+// WriteInt24LE writes the low 24 bits of i as a signed integer in
+// little-endian byte order.
+func WriteInt24LE(bout Writer, i int32) error {
+	return WriteUint24LE(bout, uint32(i))
+}
+
 // WriteUint32LE writes an unsigned uint32 in little-endian byte order.
 func WriteUint32LE(bout Writer, n uint32) error {
 	if err := bout.WriteByte(byte(n)); err != nil {
@@ -115,6 +178,33 @@ func WriteFloat32LE(bout Writer, f float32) error {
 	return WriteUint32LE(bout, math.Float32bits(f))
 }
 
+// WriteUint48LE writes the low 48 bits of n as an unsigned integer in
+// little-endian byte order.
+func WriteUint48LE(bout Writer, n uint64) error {
+	if err := bout.WriteByte(byte(n)); err != nil {
+		return err
+	}
+	if err := bout.WriteByte(byte(n >> 8)); err != nil {
+		return err
+	}
+	if err := bout.WriteByte(byte(n >> 16)); err != nil {
+		return err
+	}
+	if err := bout.WriteByte(byte(n >> 24)); err != nil {
+		return err
+	}
+	if err := bout.WriteByte(byte(n >> 32)); err != nil {
+		return err
+	}
+	return bout.WriteByte(byte(n >> 40))
+}
+
+// WriteInt48LE writes the low 48 bits of i as a signed integer in
+// little-endian byte order.
+func WriteInt48LE(bout Writer, i int64) error {
+	return WriteUint48LE(bout, uint64(i))
+}
+
 // WriteUint64LE writes an unsigned uint64 in little-endian byte order.
 func WriteUint64LE(bout Writer, n uint64) error {
 	if err := bout.WriteByte(byte(n)); err != nil {
@@ -151,3 +241,27 @@ func WriteInt64LE(bout Writer, i int64) error {
 func WriteFloat64LE(bout Writer, f float64) error {
 	return WriteUint64LE(bout, math.Float64bits(f))
 }
+
+// WriteUintN writes the low width bytes of n (1 ≤ width ≤ 8) as an unsigned
+// integer in the given byte order.
+func WriteUintN(bout Writer, width int, order Order, n uint64) error {
+	if width < 1 || width > 8 {
+		return errWidth
+	}
+	for i := 0; i < width; i++ {
+		shift := uint(8 * i)
+		if order != LittleEndian {
+			shift = uint(8 * (width - 1 - i))
+		}
+		if err := bout.WriteByte(byte(n >> shift)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteIntN writes the low width bytes of i (1 ≤ width ≤ 8) as a signed
+// integer in the given byte order.
+func WriteIntN(bout Writer, width int, order Order, i int64) error {
+	return WriteUintN(bout, width, order, uint64(i))
+}
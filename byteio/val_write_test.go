@@ -257,4 +257,68 @@ func TestWriteIntErr(t *testing.T) {
 	check("WriteUint16LE", 2, func(bout byteio.Writer) error { return byteio.WriteUint16LE(bout, 0) })
 	check("WriteUint32LE", 4, func(bout byteio.Writer) error { return byteio.WriteUint32LE(bout, 0) })
 	check("WriteUint64LE", 8, func(bout byteio.Writer) error { return byteio.WriteUint64LE(bout, 0) })
+	check("WriteUint24BE", 3, func(bout byteio.Writer) error { return byteio.WriteUint24BE(bout, 0) })
+	check("WriteUint48BE", 6, func(bout byteio.Writer) error { return byteio.WriteUint48BE(bout, 0) })
+	check("WriteUint24LE", 3, func(bout byteio.Writer) error { return byteio.WriteUint24LE(bout, 0) })
+	check("WriteUint48LE", 6, func(bout byteio.Writer) error { return byteio.WriteUint48LE(bout, 0) })
+	for w := 1; w <= 8; w++ {
+		check("WriteUintN", w, func(bout byteio.Writer) error { return byteio.WriteUintN(bout, w, byteio.BigEndian, 0) })
+	}
+}
+
+// TestWriteOddWidths verifies that the 24- and 48-bit writers, and WriteUintN
+// at every width, round trip through the corresponding readers.
+func TestWriteOddWidths(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	checkErr := func(err error) {
+		if err != nil {
+			t.Fatalf("unexpected I/O error: %v", err)
+		}
+	}
+
+	checkErr(byteio.WriteUint24BE(buf, 0xFF810203))
+	checkErr(byteio.WriteUint24LE(buf, 0x810203))
+	checkErr(byteio.WriteInt24BE(buf, -2))
+	checkErr(byteio.WriteInt24LE(buf, -2))
+	checkErr(byteio.WriteUint48BE(buf, 0xFFFF810203040506))
+	checkErr(byteio.WriteUint48LE(buf, 0x810203040506))
+	checkErr(byteio.WriteInt48BE(buf, -2))
+	checkErr(byteio.WriteInt48LE(buf, -2))
+
+	exp := []byte{
+		0x81, 0x02, 0x03, 0x03, 0x02, 0x81,
+		0xFF, 0xFF, 0xFE, 0xFE, 0xFF, 0xFF,
+		0x81, 0x02, 0x03, 0x04, 0x05, 0x06,
+		0x06, 0x05, 0x04, 0x03, 0x02, 0x81,
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFE,
+		0xFE, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+	}
+	if !bytes.Equal(buf.Bytes(), exp) {
+		t.Errorf("act % X ≠ exp % X", buf.Bytes(), exp)
+	}
+
+	for w := 1; w <= 8; w++ {
+		for _, order := range []byteio.Order{byteio.BigEndian, byteio.LittleEndian} {
+			buf.Reset()
+			checkErr(byteio.WriteIntN(buf, w, order, -3))
+			checkErr(byteio.WriteUintN(buf, w, order, 0x0102030405060708))
+			if buf.Len() != 2*w {
+				t.Fatalf("width %d: wrote %d bytes", w, buf.Len())
+			}
+			if act, err := byteio.ReadIntN(buf, w, order); err != nil || act != -3 {
+				t.Errorf("IntN/%d/%d: act %d, %v ≠ exp -3", w, order, act, err)
+			}
+			exp := uint64(0x0102030405060708)
+			if w < 8 {
+				exp &= 1<<uint(8*w) - 1
+			}
+			if act, err := byteio.ReadUintN(buf, w, order); err != nil || act != exp {
+				t.Errorf("UintN/%d/%d: act %X, %v ≠ exp %X", w, order, act, err, exp)
+			}
+		}
+	}
+
+	if err := byteio.WriteUintN(buf, 9, byteio.BigEndian, 0); err == nil {
+		t.Error("WriteUintN(9): expected error")
+	}
 }
@@ -0,0 +1,125 @@
+package byteio
+
+import "errors"
+
+// MaxVarintLen is the maximum number of bytes occupied by a varint-encoded
+// 64-bit integer.
+const MaxVarintLen = 10
+
+// ErrOverflow is returned when a varint encoding does not fit into a 64-bit
+// integer, either because it is longer than MaxVarintLen bytes or because its
+// final byte carries more significant bits than remain.
+var ErrOverflow = errors.New("byteio: varint overflows a 64-bit integer")
+
+// ReadUvarint reads an unsigned integer encoded as a protobuf-style varint,
+// which is the same as unsigned LEB128 (ULEB128).
+func ReadUvarint(bin Reader) (uint64, error) {
+	x, n, err := readUvarint(bin)
+	if err != nil {
+		return 0, err
+	}
+	traceValue(bin, n, "uvarint", x)
+	return x, nil
+}
+
+// readUvarint is ReadUvarint without tracing. It also returns the length of
+// the encoding.
+func readUvarint(bin Reader) (uint64, int, error) {
+	var (
+		x uint64
+		s uint
+	)
+	for i := 0; i < MaxVarintLen; i++ {
+		b, err := bin.ReadByte()
+		if err != nil {
+			return 0, 0, readErr(bin, i, err)
+		}
+		if b < 0x80 {
+			if i == MaxVarintLen-1 && b > 1 {
+				return 0, 0, readErr(bin, i+1, ErrOverflow)
+			}
+			x |= uint64(b) << s
+			return x, i + 1, nil
+		}
+		x |= uint64(b&0x7F) << s
+		s += 7
+	}
+	return 0, 0, readErr(bin, MaxVarintLen, ErrOverflow)
+}
+
+// ReadVarint reads a signed integer encoded as a zigzag varint, as used by
+// protobuf's sint32/sint64 types and by encoding/binary.
+func ReadVarint(bin Reader) (int64, error) {
+	ux, n, err := readUvarint(bin)
+	if err != nil {
+		return 0, err
+	}
+	x := int64(ux >> 1)
+	if ux&1 != 0 {
+		x = ^x
+	}
+	traceInt(bin, n, "varint", x)
+	return x, nil
+}
+
+// ReadSLEB128 reads a signed integer encoded as plain (two's complement, sign
+// extended) signed LEB128, as used by WASM and DWARF.
+func ReadSLEB128(bin Reader) (int64, error) {
+	var (
+		x uint64
+		s uint
+	)
+	for i := 0; i < MaxVarintLen; i++ {
+		b, err := bin.ReadByte()
+		if err != nil {
+			return 0, readErr(bin, i, err)
+		}
+		if b < 0x80 {
+			// the final byte of a 10-byte encoding holds only bit 63;
+			// the rest must be its sign extension
+			if i == MaxVarintLen-1 && b != 0 && b != 0x7F {
+				return 0, readErr(bin, i+1, ErrOverflow)
+			}
+			x |= uint64(b) << s
+			s += 7
+			if s < 64 && b&0x40 != 0 {
+				x |= ^uint64(0) << s
+			}
+			traceInt(bin, i+1, "sleb128", int64(x))
+			return int64(x), nil
+		}
+		x |= uint64(b&0x7F) << s
+		s += 7
+	}
+	return 0, readErr(bin, MaxVarintLen, ErrOverflow)
+}
+
+// WriteUvarint writes an unsigned integer as a protobuf-style varint (ULEB128).
+func WriteUvarint(bout Writer, n uint64) error {
+	for n >= 0x80 {
+		if err := bout.WriteByte(byte(n) | 0x80); err != nil {
+			return err
+		}
+		n >>= 7
+	}
+	return bout.WriteByte(byte(n))
+}
+
+// WriteVarint writes a signed integer as a zigzag varint.
+func WriteVarint(bout Writer, i int64) error {
+	return WriteUvarint(bout, uint64(i<<1)^uint64(i>>63))
+}
+
+// WriteSLEB128 writes a signed integer as plain signed LEB128.
+func WriteSLEB128(bout Writer, i int64) error {
+	for {
+		b := byte(i & 0x7F)
+		i >>= 7
+		if (i == 0 && b&0x40 == 0) || (i == -1 && b&0x40 != 0) {
+			return bout.WriteByte(b)
+		}
+		if err := bout.WriteByte(b | 0x80); err != nil {
+			return err
+		}
+	}
+}
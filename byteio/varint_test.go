@@ -0,0 +1,164 @@
+package byteio_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// TestUvarint verifies ReadUvarint and WriteUvarint against encoding/binary.
+func TestUvarint(t *testing.T) {
+	vals := []uint64{0, 1, 0x7F, 0x80, 0x3FFF, 0x4000, 624485,
+		math.MaxUint32, math.MaxUint64 >> 1, math.MaxUint64}
+
+	for _, val := range vals {
+		exp := make([]byte, binary.MaxVarintLen64)
+		exp = exp[:binary.PutUvarint(exp, val)]
+
+		buf := bytes.NewBuffer(nil)
+		if err := byteio.WriteUvarint(buf, val); err != nil {
+			t.Fatalf("unexpected I/O error: %v", err)
+		}
+		if !bytes.Equal(buf.Bytes(), exp) {
+			t.Errorf("WriteUvarint(%X): act % X ≠ exp % X",
+				val, buf.Bytes(), exp)
+		}
+
+		act, err := byteio.ReadUvarint(bytes.NewReader(exp))
+		if err != nil {
+			t.Errorf("ReadUvarint(% X): unexpected error %v", exp, err)
+		} else if act != val {
+			t.Errorf("ReadUvarint(% X): act %X ≠ exp %X", exp, act, val)
+		}
+	}
+}
+
+// TestVarint verifies ReadVarint and WriteVarint against encoding/binary.
+func TestVarint(t *testing.T) {
+	vals := []int64{0, 1, -1, 63, -64, 64, -65, math.MaxInt32,
+		math.MinInt32, math.MaxInt64, math.MinInt64}
+
+	for _, val := range vals {
+		exp := make([]byte, binary.MaxVarintLen64)
+		exp = exp[:binary.PutVarint(exp, val)]
+
+		buf := bytes.NewBuffer(nil)
+		if err := byteio.WriteVarint(buf, val); err != nil {
+			t.Fatalf("unexpected I/O error: %v", err)
+		}
+		if !bytes.Equal(buf.Bytes(), exp) {
+			t.Errorf("WriteVarint(%d): act % X ≠ exp % X",
+				val, buf.Bytes(), exp)
+		}
+
+		act, err := byteio.ReadVarint(bytes.NewReader(exp))
+		if err != nil {
+			t.Errorf("ReadVarint(% X): unexpected error %v", exp, err)
+		} else if act != val {
+			t.Errorf("ReadVarint(% X): act %d ≠ exp %d", exp, act, val)
+		}
+	}
+}
+
+// TestSLEB128 verifies ReadSLEB128 and WriteSLEB128 against known encodings.
+func TestSLEB128(t *testing.T) {
+	vals := []struct {
+		val int64
+		enc []byte
+	}{
+		{0, []byte{0x00}},
+		{2, []byte{0x02}},
+		{-2, []byte{0x7E}},
+		{63, []byte{0x3F}},
+		{64, []byte{0xC0, 0x00}},
+		{-64, []byte{0x40}},
+		{-65, []byte{0xBF, 0x7F}},
+		{-123456, []byte{0xC0, 0xBB, 0x78}},
+		{math.MaxInt64, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+			0xFF, 0xFF, 0xFF, 0xFF, 0x00}},
+		{math.MinInt64, []byte{0x80, 0x80, 0x80, 0x80, 0x80,
+			0x80, 0x80, 0x80, 0x80, 0x7F}},
+	}
+
+	for _, v := range vals {
+		buf := bytes.NewBuffer(nil)
+		if err := byteio.WriteSLEB128(buf, v.val); err != nil {
+			t.Fatalf("unexpected I/O error: %v", err)
+		}
+		if !bytes.Equal(buf.Bytes(), v.enc) {
+			t.Errorf("WriteSLEB128(%d): act % X ≠ exp % X",
+				v.val, buf.Bytes(), v.enc)
+		}
+
+		act, err := byteio.ReadSLEB128(bytes.NewReader(v.enc))
+		if err != nil {
+			t.Errorf("ReadSLEB128(% X): unexpected error %v",
+				v.enc, err)
+		} else if act != v.val {
+			t.Errorf("ReadSLEB128(% X): act %d ≠ exp %d",
+				v.enc, act, v.val)
+		}
+	}
+}
+
+// TestVarintEOF ensures that io.EOF is returned when no bytes are available
+// and io.ErrUnexpectedEOF when the stream ends part-way through an encoding.
+func TestVarintEOF(t *testing.T) {
+	check := func(fn string, f func(bin byteio.Reader) error) {
+		if err := f(bytes.NewBuffer(nil)); err != io.EOF {
+			t.Errorf("%s: expected io.EOF, got %v", fn, err)
+		}
+		for i := 1; i < byteio.MaxVarintLen; i++ {
+			bin := bytes.NewBuffer(bytes.Repeat([]byte{0x80}, i))
+			if err := f(bin); err != io.ErrUnexpectedEOF {
+				t.Errorf("%s/%d: expected io.ErrUnexpectedEOF, "+
+					"got %v", fn, i, err)
+			}
+		}
+	}
+
+	check("ReadUvarint", func(bin byteio.Reader) error { _, err := byteio.ReadUvarint(bin); return err })
+	check("ReadVarint", func(bin byteio.Reader) error { _, err := byteio.ReadVarint(bin); return err })
+	check("ReadSLEB128", func(bin byteio.Reader) error { _, err := byteio.ReadSLEB128(bin); return err })
+}
+
+// TestVarintOverflow ensures that over-long encodings and encodings whose
+// final byte does not fit into 64 bits result in ErrOverflow.
+func TestVarintOverflow(t *testing.T) {
+	long := append(bytes.Repeat([]byte{0x80}, byteio.MaxVarintLen), 0)
+	wideU := append(bytes.Repeat([]byte{0xFF}, byteio.MaxVarintLen-1), 0x02)
+	wideS := append(bytes.Repeat([]byte{0xFF}, byteio.MaxVarintLen-1), 0x01)
+
+	if _, err := byteio.ReadUvarint(bytes.NewReader(long)); err != byteio.ErrOverflow {
+		t.Errorf("ReadUvarint(long): unexpected error %v", err)
+	}
+	if _, err := byteio.ReadUvarint(bytes.NewReader(wideU)); err != byteio.ErrOverflow {
+		t.Errorf("ReadUvarint(wide): unexpected error %v", err)
+	}
+	if _, err := byteio.ReadVarint(bytes.NewReader(long)); err != byteio.ErrOverflow {
+		t.Errorf("ReadVarint(long): unexpected error %v", err)
+	}
+	if _, err := byteio.ReadSLEB128(bytes.NewReader(long)); err != byteio.ErrOverflow {
+		t.Errorf("ReadSLEB128(long): unexpected error %v", err)
+	}
+	if _, err := byteio.ReadSLEB128(bytes.NewReader(wideS)); err != byteio.ErrOverflow {
+		t.Errorf("ReadSLEB128(wide): unexpected error %v", err)
+	}
+}
+
+// TestWriteVarintErr ensures that write errors are propagated at every byte
+// position.
+func TestWriteVarintErr(t *testing.T) {
+	for i := 0; i < byteio.MaxVarintLen; i++ {
+		if err := byteio.WriteUvarint(&AbortWriter{when: i}, math.MaxUint64); err != ErrAbortWriter {
+			t.Errorf("WriteUvarint/%d: unexpected error %v", i, err)
+		}
+		if err := byteio.WriteSLEB128(&AbortWriter{when: i}, math.MinInt64); err != ErrAbortWriter {
+			t.Errorf("WriteSLEB128/%d: unexpected error %v", i, err)
+		}
+	}
+}
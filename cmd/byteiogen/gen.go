@@ -0,0 +1,716 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// typeKind classifies the type of a field.
+type typeKind int
+
+const (
+	kindBasic typeKind = iota // bool or a sized integer or float
+	kindArray
+	kindSlice
+	kindString
+	kindStruct
+)
+
+// fieldType is a field type, resolved to its underlying type.
+type fieldType struct {
+	kind  typeKind
+	basic string     // underlying basic type, such as "uint16"
+	expr  string     // the type as written, such as "Kind" or "[4]byte"
+	named bool       // a defined type, so values must be converted
+	elem  *fieldType // array or slice element
+	len   int64      // array length, or -1 if not a literal
+	decl  string     // name of a struct type's declaration
+}
+
+// basicSizes maps the supported basic types to their encoded sizes.
+var basicSizes = map[string]int64{
+	"bool":    1,
+	"uint8":   1,
+	"int8":    1,
+	"uint16":  2,
+	"int16":   2,
+	"uint32":  4,
+	"int32":   4,
+	"float32": 4,
+	"uint64":  8,
+	"int64":   8,
+	"float64": 8,
+}
+
+// size returns the encoded size of t, or -1 if it is not known.
+func (t *fieldType) size() int64 {
+	switch t.kind {
+	case kindBasic:
+		return basicSizes[t.basic]
+	case kindArray:
+		if n := t.elem.size(); n >= 0 && t.len >= 0 {
+			return n * t.len
+		}
+	}
+	return -1
+}
+
+// isByte reports whether t is byte (or uint8), so that arrays and slices of
+// it may be read and written in a single call.
+func (t *fieldType) isByte() bool {
+	return t.kind == kindBasic && t.basic == "uint8" && !t.named
+}
+
+// isInteger reports whether t may be used as a len= field.
+func (t *fieldType) isInteger() bool {
+	return t.kind == kindBasic && t.basic != "bool" &&
+		!strings.HasPrefix(t.basic, "float")
+}
+
+// field is a field of a struct type being generated.
+type field struct {
+	name  string
+	typ   *fieldType
+	le    bool
+	skip  int
+	blank bool
+	len   *field // len= field, if any
+}
+
+// generator holds the state for generating one output file.
+type generator struct {
+	fset    *token.FileSet
+	decls   map[string]*ast.TypeSpec
+	listed  map[string]bool
+	imports map[string]bool
+	suffix  string          // appended to the names of helper functions
+	helpers map[string]bool // helper functions called by the output
+}
+
+// generate returns the formatted source of the methods for the named types,
+// which must be declared in files. cmd is recorded in the header.
+func generate(fset *token.FileSet, files []*ast.File, typeNames []string,
+	cmd string) ([]byte, error) {
+	g := &generator{
+		fset:    fset,
+		decls:   make(map[string]*ast.TypeSpec),
+		listed:  make(map[string]bool),
+		imports: make(map[string]bool),
+		suffix:  typeNames[0],
+		helpers: make(map[string]bool),
+	}
+	pkg := files[0].Name.Name
+	for _, f := range files {
+		if f.Name.Name != pkg {
+			return nil, fmt.Errorf("multiple packages: %s and %s",
+				pkg, f.Name.Name)
+		}
+		for _, decl := range f.Decls {
+			if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.TYPE {
+				for _, spec := range gd.Specs {
+					ts := spec.(*ast.TypeSpec)
+					g.decls[ts.Name.Name] = ts
+				}
+			}
+		}
+	}
+	for _, name := range typeNames {
+		g.listed[name] = true
+	}
+
+	var body bytes.Buffer
+	for _, name := range typeNames {
+		ts, ok := g.decls[name]
+		if !ok {
+			return nil, fmt.Errorf("type %s not found", name)
+		}
+		st, ok := ts.Type.(*ast.StructType)
+		if !ok || ts.Assign.IsValid() || ts.TypeParams != nil {
+			return nil, fmt.Errorf("%s: %s is not a struct type",
+				fset.Position(ts.Pos()), name)
+		}
+		fields, err := g.structFields(name, st)
+		if err != nil {
+			return nil, err
+		}
+		g.decoder(&body, name, fields)
+		g.encoder(&body, name, fields)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by %q; DO NOT EDIT.\n\n", cmd)
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", pkg)
+	var std []string
+	for imp := range g.imports {
+		std = append(std, imp)
+	}
+	sort.Strings(std)
+	for _, imp := range std {
+		fmt.Fprintf(&out, "%q\n", imp)
+	}
+	if len(std) > 0 {
+		out.WriteByte('\n')
+	}
+	out.WriteString("\"github.com/lwithers/pkg/byteio\"\n)\n")
+	out.Write(body.Bytes())
+	for _, h := range helpers {
+		if g.helpers[h.name] {
+			fmt.Fprintf(&out, h.src, g.suffix)
+		}
+	}
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("internal error: %v\n%s", err, out.Bytes())
+	}
+	return src, nil
+}
+
+// structFields interprets the fields of a struct type and their tags, in the
+// same way as byteio.Unmarshal.
+func (g *generator) structFields(typeName string, st *ast.StructType) (
+	[]*field, error) {
+	var fields []*field
+	byName := make(map[string]*field)
+	for _, af := range st.Fields.List {
+		tag := ""
+		if af.Tag != nil {
+			s, _ := strconv.Unquote(af.Tag.Value)
+			tag = reflect.StructTag(s).Get("byteio")
+		}
+		if tag == "-" {
+			continue
+		}
+
+		names := af.Names
+		if len(names) == 0 {
+			// embedded field, named after its type
+			id, ok := af.Type.(*ast.Ident)
+			if !ok {
+				return nil, fmt.Errorf("%s: %s: unsupported "+
+					"embedded field %s", g.fset.Position(af.Pos()),
+					typeName, types.ExprString(af.Type))
+			}
+			names = []*ast.Ident{id}
+		}
+
+		for _, id := range names {
+			f, err := g.field(af, id.Name, tag, byName)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s.%s: %v",
+					g.fset.Position(id.Pos()), typeName,
+					id.Name, err)
+			}
+			fields = append(fields, f)
+			if !f.blank {
+				byName[f.name] = f
+			}
+		}
+	}
+	return fields, nil
+}
+
+// field interprets a single field, given the earlier fields of its struct.
+func (g *generator) field(af *ast.Field, name, tag string,
+	byName map[string]*field) (*field, error) {
+	f := &field{name: name, blank: name == "_"}
+	if !ast.IsExported(name) && !f.blank {
+		return nil, fmt.Errorf("unexported field")
+	}
+
+	lenName := ""
+	if tag != "" {
+		for _, opt := range strings.Split(tag, ",") {
+			switch {
+			case opt == "be":
+				f.le = false
+			case opt == "le":
+				f.le = true
+			case strings.HasPrefix(opt, "len="):
+				lenName = opt[4:]
+			case strings.HasPrefix(opt, "skip="):
+				n, err := strconv.Atoi(opt[5:])
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("invalid option %q", opt)
+				}
+				f.skip = n
+			default:
+				return nil, fmt.Errorf("unknown option %q", opt)
+			}
+		}
+	}
+
+	var err error
+	if f.typ, err = g.resolve(af.Type, 0); err != nil {
+		return nil, err
+	}
+	switch f.typ.kind {
+	case kindSlice, kindString:
+		if lenName == "" {
+			return nil, fmt.Errorf("slice or string requires len= option")
+		}
+		if f.len = byName[lenName]; f.len == nil {
+			return nil, fmt.Errorf("len=%s does not name an earlier "+
+				"field", lenName)
+		}
+		if !f.len.typ.isInteger() {
+			return nil, fmt.Errorf("len=%s is not an integer field",
+				lenName)
+		}
+		if f.typ.kind == kindSlice {
+			err = g.check(f.typ.elem)
+		}
+	default:
+		if lenName != "" {
+			return nil, fmt.Errorf("len= option requires a slice or " +
+				"string")
+		}
+		err = g.check(f.typ)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// resolve determines the underlying type of a type expression.
+func (g *generator) resolve(expr ast.Expr, depth int) (*fieldType, error) {
+	if depth > 100 {
+		return nil, fmt.Errorf("invalid recursive type")
+	}
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return g.resolve(e.X, depth)
+
+	case *ast.Ident:
+		switch e.Name {
+		case "byte":
+			return &fieldType{kind: kindBasic, basic: "uint8", expr: e.Name}, nil
+		case "rune":
+			return &fieldType{kind: kindBasic, basic: "int32", expr: e.Name}, nil
+		case "string":
+			return &fieldType{kind: kindString, expr: e.Name}, nil
+		}
+		if _, ok := basicSizes[e.Name]; ok {
+			return &fieldType{kind: kindBasic, basic: e.Name, expr: e.Name}, nil
+		}
+		ts, ok := g.decls[e.Name]
+		if !ok {
+			// includes int, uint and uintptr
+			return nil, fmt.Errorf("unsupported type %s", e.Name)
+		}
+		if _, ok := ts.Type.(*ast.StructType); ok && !ts.Assign.IsValid() {
+			return &fieldType{kind: kindStruct, expr: e.Name, named: true,
+				decl: e.Name}, nil
+		}
+		t, err := g.resolve(ts.Type, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		nt := *t
+		nt.expr, nt.named = e.Name, !ts.Assign.IsValid() || t.named
+		return &nt, nil
+
+	case *ast.ArrayType:
+		elem, err := g.resolve(e.Elt, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		t := &fieldType{kind: kindSlice, expr: types.ExprString(e),
+			elem: elem, len: -1}
+		if e.Len != nil {
+			t.kind = kindArray
+			if lit, ok := e.Len.(*ast.BasicLit); ok && lit.Kind == token.INT {
+				t.len, _ = strconv.ParseInt(lit.Value, 0, 64)
+			}
+		}
+		return t, nil
+
+	case *ast.StructType:
+		return nil, fmt.Errorf("anonymous struct types are not supported")
+	}
+	return nil, fmt.Errorf("unsupported type %s", types.ExprString(expr))
+}
+
+// check ensures that t may be encoded as a fixed-size value, as the type of a
+// field or an element of an array or slice.
+func (g *generator) check(t *fieldType) error {
+	switch t.kind {
+	case kindArray:
+		return g.check(t.elem)
+	case kindSlice, kindString:
+		return fmt.Errorf("unsupported type %s", t.expr)
+	case kindStruct:
+		if !g.listed[t.decl] {
+			return fmt.Errorf("nested struct type %s must also be "+
+				"listed in -type", t.decl)
+		}
+	}
+	return nil
+}
+
+// emitter accumulates the body of a generated method.
+type emitter struct {
+	g       *generator
+	buf     bytes.Buffer
+	usesErr bool // whether the body assigns to an err declared by the method
+}
+
+// p writes a line of code.
+func (e *emitter) p(format string, args ...interface{}) {
+	fmt.Fprintf(&e.buf, format+"\n", args...)
+}
+
+// check writes stmt, which assigns err, followed by a return if err is set.
+// cond is the started argument for the midRecord helper, or empty if nothing
+// has been read.
+func (e *emitter) check(cond, stmt string) {
+	e.usesErr = true
+	e.p("if %s; err != nil {", stmt)
+	e.p("return %s", e.retErr(cond))
+	e.p("}")
+}
+
+// retErr returns the expression with which to return err from a decoder.
+func (e *emitter) retErr(cond string) string {
+	if cond == "" {
+		return "err"
+	}
+	e.useHelper("midRecord")
+	return "midRecord" + e.g.suffix + "(" + cond + ", err)"
+}
+
+// useHelper records that the generated code calls a helper function.
+func (e *emitter) useHelper(name string) {
+	e.g.helpers[name] = true
+	e.g.imports["io"] = true
+}
+
+// orCond returns the started condition for the elements of an array or slice
+// indexed by iv.
+func orCond(cond, iv string) string {
+	switch cond {
+	case "true":
+		return cond
+	case "":
+		return iv + " > 0"
+	}
+	return cond + " || " + iv + " > 0"
+}
+
+// loopVar returns the name of the index variable for an array nested depth
+// deep.
+func loopVar(depth int) string {
+	if depth < 3 {
+		return string(rune('i' + depth))
+	}
+	return "i" + strconv.Itoa(depth)
+}
+
+// funcSuffix returns the suffix of the byteio function reading or writing
+// basic type b, such as "Uint16LE".
+func funcSuffix(b string, le bool) string {
+	order := "BE"
+	if le {
+		order = "LE"
+	}
+	return strings.ToUpper(b[:1]) + b[1:] + order
+}
+
+// decoder writes the DecodeFrom method of a struct type.
+func (g *generator) decoder(w *bytes.Buffer, name string, fields []*field) {
+	e := &emitter{g: g}
+	cond := ""
+	for _, f := range fields {
+		if f.skip > 0 {
+			e.check(cond, fmt.Sprintf("err = byteio.Skip(bin, %d)", f.skip))
+			cond = "true"
+		}
+		switch dst := "x." + f.name; {
+		case f.blank && f.typ.size() >= 0:
+			e.check(cond, fmt.Sprintf("err = byteio.Skip(bin, %d)",
+				f.typ.size()))
+		case f.blank:
+			e.decodeBlank(f, cond)
+		case f.len != nil:
+			e.decodeCounted(f, dst, cond)
+		default:
+			e.decode(f.typ, dst, f.le, cond, 0)
+		}
+		cond = "true"
+	}
+
+	fmt.Fprintf(w, "\n// DecodeFrom reads x from bin, as byteio.Unmarshal would.\n")
+	fmt.Fprintf(w, "func (x *%s) DecodeFrom(bin byteio.Reader) error {\n", name)
+	if e.usesErr {
+		w.WriteString("var err error\n")
+	}
+	w.Write(e.buf.Bytes())
+	w.WriteString("return nil\n}\n")
+}
+
+// decode writes code reading a value of type t into dst.
+func (e *emitter) decode(t *fieldType, dst string, le bool, cond string,
+	depth int) {
+	switch t.kind {
+	case kindBasic:
+		call := "byteio.Read" + funcSuffix(t.basic, le) + "(bin)"
+		conv := "v"
+		switch t.basic {
+		case "bool":
+			call, conv = "bin.ReadByte()", "v != 0"
+		case "uint8":
+			call = "bin.ReadByte()"
+		case "int8":
+			call, conv = "bin.ReadByte()", "int8(v)"
+		}
+		if t.named {
+			conv = t.expr + "(" + conv + ")"
+		}
+		if conv == "v" {
+			e.check(cond, dst+", err = "+call)
+			return
+		}
+		e.p("{")
+		e.p("v, err := %s", call)
+		e.p("if err != nil {")
+		e.p("return %s", e.retErr(cond))
+		e.p("}")
+		e.p("%s = %s", dst, conv)
+		e.p("}")
+
+	case kindArray:
+		if t.elem.isByte() {
+			e.g.imports["io"] = true
+			e.check(cond, "_, err = io.ReadFull(bin, "+dst+"[:])")
+			return
+		}
+		iv := loopVar(depth)
+		e.p("for %s := range %s {", iv, dst)
+		e.decode(t.elem, dst+"["+iv+"]", le, orCond(cond, iv), depth+1)
+		e.p("}")
+
+	case kindStruct:
+		e.check(cond, "err = "+dst+".DecodeFrom(bin)")
+	}
+}
+
+// decodeBlank writes code reading and discarding a blank field whose size is
+// not fixed.
+func (e *emitter) decodeBlank(f *field, cond string) {
+	t := f.typ
+	if f.len != nil && (t.kind == kindString || t.elem.isByte()) {
+		e.decodeCounted(f, "_", cond)
+		return
+	}
+	e.p("{")
+	e.p("var blank %s", t.expr)
+	if f.len != nil {
+		e.decodeCounted(f, "blank", cond)
+	} else {
+		e.decode(t, "blank", f.le, cond, 0)
+	}
+	e.p("}")
+}
+
+// decodeCounted writes code reading a slice or string whose length is given
+// by a len= field.
+func (e *emitter) decodeCounted(f *field, dst, cond string) {
+	n := "x." + f.len.name
+	e.g.imports["fmt"] = true
+	if strings.HasPrefix(f.len.typ.basic, "int") {
+		e.p("if %s < 0 {", n)
+		e.p("return fmt.Errorf(\"byteio: %s: negative length %%d\", %s)",
+			f.name, n)
+		e.p("}")
+	}
+	if f.len.typ.size() >= 4 && f.len.typ.basic != "int32" {
+		e.g.imports["math"] = true
+		e.p("if uint64(%s) > math.MaxInt32 {", n)
+		e.p("return fmt.Errorf(\"byteio: %s: length %%d too large\", %s)",
+			f.name, n)
+		e.p("}")
+	}
+	count := "int(" + n + ")"
+
+	t := f.typ
+	if t.kind == kindString || t.elem.isByte() {
+		call := "byteio.ReadBytes(bin, " + count + ")"
+		if t.kind == kindSlice && !t.named {
+			e.check(cond, dst+", err = "+call)
+			return
+		}
+		e.p("{")
+		e.p("buf, err := %s", call)
+		e.p("if err != nil {")
+		e.p("return %s", e.retErr(cond))
+		e.p("}")
+		e.p("%s = %s(buf)", dst, t.expr)
+		e.p("}")
+		return
+	}
+
+	// grow the slice as elements arrive, so that a hostile length does
+	// not cause a huge up-front allocation
+	e.p("{")
+	e.p("n, c := %s, %s", count, count)
+	e.p("if c > 1024 {")
+	e.p("c = 1024")
+	e.p("}")
+	e.p("%s = make(%s, 0, c)", dst, t.expr)
+	e.p("for i := 0; i < n; i++ {")
+	e.p("var e %s", t.elem.expr)
+	e.decode(t.elem, "e", f.le, orCond(cond, "i"), 1)
+	e.p("%s = append(%s, e)", dst, dst)
+	e.p("}")
+	e.p("}")
+}
+
+// encoder writes the EncodeTo method of a struct type.
+func (g *generator) encoder(w *bytes.Buffer, name string, fields []*field) {
+	e := &emitter{g: g}
+	for _, f := range fields {
+		if f.skip > 0 {
+			e.zeros(int64(f.skip))
+		}
+		switch src := "x." + f.name; {
+		case f.blank && f.typ.size() >= 0:
+			e.zeros(f.typ.size())
+		case f.blank:
+			// encode the zero value, which for a slice or string
+			// must still agree with its len= field
+			e.p("{")
+			e.p("var blank %s", f.typ.expr)
+			if f.len != nil {
+				e.encodeCounted(f, "blank")
+			} else {
+				e.encode(f.typ, "blank", f.le, 0)
+			}
+			e.p("}")
+		case f.len != nil:
+			e.encodeCounted(f, src)
+		default:
+			e.encode(f.typ, src, f.le, 0)
+		}
+	}
+
+	fmt.Fprintf(w, "\n// EncodeTo writes x to bout, as byteio.Marshal would.\n")
+	fmt.Fprintf(w, "func (x *%s) EncodeTo(bout byteio.Writer) error {\n", name)
+	w.Write(e.buf.Bytes())
+	w.WriteString("return nil\n}\n")
+}
+
+// ret writes stmt, which declares err, followed by a return if err is set.
+func (e *emitter) ret(stmt string) {
+	e.p("if %s; err != nil {", stmt)
+	e.p("return err")
+	e.p("}")
+}
+
+// zeros writes code writing n zero bytes of padding.
+func (e *emitter) zeros(n int64) {
+	e.p("for i := 0; i < %d; i++ {", n)
+	e.ret("err := bout.WriteByte(0)")
+	e.p("}")
+}
+
+// encode writes code writing the value src of type t.
+func (e *emitter) encode(t *fieldType, src string, le bool, depth int) {
+	switch t.kind {
+	case kindBasic:
+		switch t.basic {
+		case "bool":
+			e.p("{")
+			e.p("var b byte")
+			e.p("if %s {", src)
+			e.p("b = 1")
+			e.p("}")
+			e.ret("err := bout.WriteByte(b)")
+			e.p("}")
+		case "uint8", "int8":
+			if t.named || t.basic == "int8" {
+				src = "byte(" + src + ")"
+			}
+			e.ret("err := bout.WriteByte(" + src + ")")
+		default:
+			if t.named {
+				src = t.basic + "(" + src + ")"
+			}
+			e.ret("err := byteio.Write" + funcSuffix(t.basic, le) +
+				"(bout, " + src + ")")
+		}
+
+	case kindArray:
+		if t.elem.isByte() {
+			e.ret("_, err := bout.Write(" + src + "[:])")
+			return
+		}
+		iv := loopVar(depth)
+		e.p("for %s := range %s {", iv, src)
+		e.encode(t.elem, src+"["+iv+"]", le, depth+1)
+		e.p("}")
+
+	case kindStruct:
+		e.ret("err := " + src + ".EncodeTo(bout)")
+	}
+}
+
+// encodeCounted writes code writing a slice or string whose length is given
+// by a len= field.
+func (e *emitter) encodeCounted(f *field, src string) {
+	n := "x." + f.len.name
+	e.p("if uint64(len(%s)) != uint64(%s) {", src, n)
+	e.p("return fmt.Errorf(\"byteio: %s: length %%d does not match len= "+
+		"field value %%d\", len(%s), %s)", f.name, src, n)
+	e.p("}")
+
+	t := f.typ
+	switch {
+	case t.kind == kindString:
+		e.g.imports["io"] = true
+		if t.named {
+			src = "string(" + src + ")"
+		}
+		e.ret("_, err := io.WriteString(bout, " + src + ")")
+	case t.elem.isByte():
+		e.ret("_, err := bout.Write(" + src + ")")
+	default:
+		e.p("for i := range %s {", src)
+		e.encode(t.elem, src+"[i]", f.le, 1)
+		e.p("}")
+	}
+}
+
+// helpers are the functions which may be called by the generated decoders,
+// in the order in which they are written. Each source is formatted with the
+// generator's suffix, naming the helpers after the first type so that several
+// generated files may share a package.
+var helpers = []struct {
+	name, src string
+}{
+	{"midRecord", `
+// midRecord%[1]s adjusts the error from a failed read as byteio.Unmarshal
+// does: if part of the record had already been read, io.EOF becomes
+// io.ErrUnexpectedEOF and a *byteio.AbortError is marked Partial.
+func midRecord%[1]s(started bool, err error) error {
+	if !started {
+		return err
+	}
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if ae, ok := err.(*byteio.AbortError); ok && !ae.Partial {
+		return &byteio.AbortError{Err: ae.Err, Partial: true}
+	}
+	return err
+}
+`},
+}
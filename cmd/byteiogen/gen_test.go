@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// typesRE extracts the -type flag from a //go:generate line.
+var typesRE = regexp.MustCompile(`(?m)^//go:generate .*byteiogen (-type=(\S+))`)
+
+// generateFile runs the generator over a single file, with the types named in
+// its //go:generate line.
+func generateFile(t *testing.T, name string) []byte {
+	t.Helper()
+	src, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := typesRE.FindSubmatch(src)
+	if m == nil {
+		t.Fatalf("%s: no //go:generate line", name)
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, name, src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := generate(fset, []*ast.File{f},
+		strings.Split(string(m[2]), ","), "byteiogen "+string(m[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// generateSource runs the generator over the declarations in src, which are
+// placed in a file x.go.
+func generateSource(t *testing.T, src string, types ...string) ([]byte, error) {
+	t.Helper()
+	src = "package p\n\n" + src + "\n"
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "x.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return generate(fset, []*ast.File{f}, types, "byteiogen")
+}
+
+// TestGolden compares the output for each file in testdata with the
+// corresponding .golden file. Run with -update to rewrite them.
+func TestGolden(t *testing.T) {
+	names, err := filepath.Glob("testdata/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		act := generateFile(t, name)
+		golden := strings.TrimSuffix(name, ".go") + ".golden"
+		if *update {
+			if err := os.WriteFile(golden, act, 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		exp, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(act, exp) {
+			t.Errorf("%s: output differs from %s:\n%s", name, golden, act)
+		}
+	}
+}
+
+// TestExampleUpToDate checks that the generated code in the example package,
+// which is compiled and tested against byteio.Unmarshal, is current.
+func TestExampleUpToDate(t *testing.T) {
+	act := generateFile(t, "internal/example/example.go")
+	exp, err := os.ReadFile("internal/example/header_byteio.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(act, exp) {
+		t.Error("internal/example/header_byteio.go is out of date; " +
+			"run go generate")
+	}
+}
+
+// TestErrors checks that unsupported types and invalid tags are reported
+// with the position and name of the field.
+func TestErrors(t *testing.T) {
+	for _, tc := range []struct {
+		src, exp string
+	}{
+		{`type T struct{ A int }`,
+			`x.go:3:16: T.A: unsupported type int`},
+		{`type T struct{ a uint8 }`,
+			`T.a: unexported field`},
+		{`type T struct{ A uint8 ` + "`byteio:\"xe\"`" + ` }`,
+			`T.A: unknown option "xe"`},
+		{`type T struct{ A uint8 ` + "`byteio:\"skip=x\"`" + ` }`,
+			`T.A: invalid option "skip=x"`},
+		{`type T struct{ A []byte }`,
+			`T.A: slice or string requires len= option`},
+		{`type T struct{ A []byte ` + "`byteio:\"len=N\"`" + ` }`,
+			`T.A: len=N does not name an earlier field`},
+		{`type T struct{ N float32; A string ` + "`byteio:\"len=N\"`" + ` }`,
+			`T.A: len=N is not an integer field`},
+		{`type T struct{ N uint8 ` + "`byteio:\"len=N\"`" + ` }`,
+			`T.N: len= option requires a slice or string`},
+		{`type T struct{ A [2]string }`,
+			`T.A: unsupported type string`},
+		{`type T struct{ A struct{ B uint8 } }`,
+			`T.A: anonymous struct types are not supported`},
+		{`type T struct{ A *uint8 }`,
+			`T.A: unsupported type *uint8`},
+		{`type T struct{ A U }; type U struct{ B uint8 }`,
+			`T.A: nested struct type U must also be listed in -type`},
+		{`type T struct{ _ U }; type U struct{ B uint8 }`,
+			`T._: nested struct type U must also be listed in -type`},
+		{`type T uint8`,
+			`T is not a struct type`},
+		{`type U struct{}`,
+			`type T not found`},
+	} {
+		_, err := generateSource(t, tc.src, "T")
+		if err == nil || !strings.Contains(err.Error(), tc.exp) {
+			t.Errorf("%s: act %v ≠ exp %q", tc.src, err, tc.exp)
+		}
+	}
+}
+
+// TestBlank checks that blank fields of any size are accepted, being skipped
+// on decode and written as their zero value on encode.
+func TestBlank(t *testing.T) {
+	for _, tc := range []struct {
+		src   string
+		types []string
+		exp   string
+	}{
+		{"const n = 2\ntype T struct{ _ [n]byte }", []string{"T"},
+			"var blank [n]byte"},
+		{`type T struct{ _ U }; type U struct{ B uint8 }`, []string{"T", "U"},
+			"var blank U"},
+		{`type T struct{ N uint8; _ []byte ` + "`byteio:\"len=N\"`" + ` }`,
+			[]string{"T"}, "_, err = byteio.ReadBytes(bin, int(x.N))"},
+	} {
+		out, err := generateSource(t, tc.src, tc.types...)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.src, err)
+		} else if !bytes.Contains(out, []byte(tc.exp)) {
+			t.Errorf("%s: output lacks %q:\n%s", tc.src, tc.exp, out)
+		}
+	}
+}
+
+// TestHelpers checks that the midRecord helper is named after the first type,
+// and is only emitted when a decoder can fail part way through a record.
+func TestHelpers(t *testing.T) {
+	for _, tc := range []struct {
+		src, exp string
+	}{
+		{`type T struct{ A uint8 }; type U struct{ B uint8 }`, ""},
+		{`type T struct{ A uint8 }; type U struct{ B, C uint8 }`, "midRecordT"},
+		{`type T struct{ A, B uint8 }; type U struct{ C uint8 }`, "midRecordT"},
+	} {
+		out, err := generateSource(t, tc.src, "T", "U")
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tc.src, err)
+		}
+		act := regexp.MustCompile(`func (midRecord\w*)\(`).FindAllSubmatch(out, -1)
+		switch {
+		case tc.exp == "" && len(act) != 0:
+			t.Errorf("%s: unexpected helper %s", tc.src, act[0][1])
+		case tc.exp != "" && (len(act) != 1 || string(act[0][1]) != tc.exp):
+			t.Errorf("%s: act %q ≠ exp %s", tc.src, act, tc.exp)
+		}
+	}
+}
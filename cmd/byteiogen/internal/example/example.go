@@ -0,0 +1,52 @@
+// Package example holds types whose methods are generated by byteiogen, to
+// check that the generated code compiles and agrees with byteio.Unmarshal and
+// byteio.Marshal.
+package example
+
+//go:generate go run github.com/lwithers/pkg/cmd/byteiogen -type=Header,Inner,Node
+
+// Kind is a named integer type.
+type Kind uint16
+
+// MAC is a named byte array type.
+type MAC [6]byte
+
+// Inner is nested within Header.
+type Inner struct {
+	A uint16 `byteio:"le"`
+	B int8
+}
+
+// Header exercises every supported kind of field.
+type Header struct {
+	Magic   [4]byte
+	Version uint16
+	Flags   uint32 `byteio:"le"`
+	Offset  int64  `byteio:"be,skip=2"`
+	Ratio   float32
+	Scale   float64 `byteio:"le"`
+	Valid   bool
+	_       [3]byte
+	_       Inner
+	Inner   Inner
+	Pair    [2]int16 `byteio:"le"`
+	Kind    Kind     `byteio:"le"`
+	Addr    MAC
+	Grid    [2][3]uint8
+	Count   uint8
+	Items   []uint32 `byteio:"le,len=Count"`
+	Kinds   []Kind   `byteio:"len=Count"`
+	NameLen uint16
+	Name    string  `byteio:"len=NameLen"`
+	DataLen int32   `byteio:"le"`
+	Data    []byte  `byteio:"len=DataLen"`
+	Inners  []Inner `byteio:"len=Count"`
+	Ignored int     `byteio:"-"`
+}
+
+// Node is a recursive type.
+type Node struct {
+	Val      uint8
+	N        uint8
+	Children []Node `byteio:"len=N"`
+}
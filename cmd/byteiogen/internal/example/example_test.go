@@ -0,0 +1,163 @@
+package example
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// testHeader returns a value with every field set.
+func testHeader() Header {
+	return Header{
+		Magic:   [4]byte{'B', 'I', 'O', '1'},
+		Version: 0x0102,
+		Flags:   0x03040506,
+		Offset:  -2,
+		Ratio:   1.5,
+		Scale:   -0.25,
+		Valid:   true,
+		Inner:   Inner{A: 0x0708, B: -3},
+		Pair:    [2]int16{-1, 0x0A0B},
+		Kind:    0x0C0D,
+		Addr:    MAC{0, 1, 2, 3, 4, 5},
+		Grid:    [2][3]uint8{{1, 2, 3}, {4, 5, 6}},
+		Count:   2,
+		Items:   []uint32{0x11121314, 0x15161718},
+		Kinds:   []Kind{7, 8},
+		NameLen: 5,
+		Name:    "hello",
+		DataLen: 3,
+		Data:    []byte{0xAA, 0xBB, 0xCC},
+		Inners:  []Inner{{A: 1, B: 2}, {A: 3, B: -4}},
+	}
+}
+
+// TestGenerated checks that the generated methods produce the same encoding as
+// byteio.Marshal, and decode it to the same value as byteio.Unmarshal.
+func TestGenerated(t *testing.T) {
+	v := testHeader()
+	exp := bytes.NewBuffer(nil)
+	if err := byteio.Marshal(exp, &v); err != nil {
+		t.Fatal(err)
+	}
+	act := bytes.NewBuffer(nil)
+	if err := v.EncodeTo(act); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(act.Bytes(), exp.Bytes()) {
+		t.Errorf("EncodeTo: act % X ≠ exp % X", act.Bytes(), exp.Bytes())
+	}
+
+	var dec Header
+	if err := dec.DecodeFrom(bytes.NewReader(exp.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dec, v) {
+		t.Errorf("DecodeFrom: act %+v ≠ exp %+v", dec, v)
+	}
+
+	// every truncation gives the same error as Unmarshal
+	for n := 0; n < exp.Len(); n++ {
+		var a, b Header
+		errA := a.DecodeFrom(bytes.NewReader(exp.Bytes()[:n]))
+		errB := byteio.Unmarshal(bytes.NewReader(exp.Bytes()[:n]), &b)
+		if errA != errB {
+			t.Errorf("truncated to %d: act %v ≠ exp %v", n, errA, errB)
+		}
+	}
+}
+
+// abortReader fails every read with a *byteio.AbortError.
+type abortReader struct{}
+
+func (abortReader) Read([]byte) (int, error) {
+	return 0, &byteio.AbortError{Err: context.Canceled}
+}
+
+// TestGeneratedAbort checks that an abort after the first field is marked
+// Partial, exactly as Unmarshal marks it.
+func TestGeneratedAbort(t *testing.T) {
+	v := testHeader()
+	buf := bytes.NewBuffer(nil)
+	v.EncodeTo(buf)
+	in := buf.Bytes()
+	for n := 0; n < len(in); n++ {
+		newReader := func() byteio.Reader {
+			return bufio.NewReader(io.MultiReader(
+				bytes.NewReader(in[:n]), abortReader{}))
+		}
+		var a, b Header
+		errA := a.DecodeFrom(newReader())
+		errB := byteio.Unmarshal(newReader(), &b)
+		ae, ok := errA.(*byteio.AbortError)
+		if !ok || !reflect.DeepEqual(errA, errB) {
+			t.Errorf("aborted at %d: act %#v ≠ exp %#v", n, errA, errB)
+		} else if ae.Partial != (n >= len(v.Magic)) {
+			// Magic is read in a single call, so an abort within it
+			// is not partial
+			t.Errorf("aborted at %d: act Partial %v", n, ae.Partial)
+		}
+	}
+}
+
+// TestGeneratedLenMismatch checks that EncodeTo rejects a slice whose length
+// differs from its len= field.
+func TestGeneratedLenMismatch(t *testing.T) {
+	v := testHeader()
+	v.Name = "hi"
+	if err := v.EncodeTo(bytes.NewBuffer(nil)); err == nil {
+		t.Error("expected error")
+	}
+}
+
+// TestGeneratedRecursive checks a type containing a slice of itself.
+func TestGeneratedRecursive(t *testing.T) {
+	in := []byte{1, 2, 2, 0, 3, 1, 4, 0}
+	var act Node
+	if err := act.DecodeFrom(bytes.NewReader(in)); err != nil {
+		t.Fatal(err)
+	}
+	var exp Node
+	byteio.Unmarshal(bytes.NewReader(in), &exp)
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("act %+v ≠ exp %+v", act, exp)
+	}
+	buf := bytes.NewBuffer(nil)
+	if err := act.EncodeTo(buf); err != nil || !bytes.Equal(buf.Bytes(), in) {
+		t.Errorf("act % X/%v ≠ exp % X", buf.Bytes(), err, in)
+	}
+}
+
+func benchmarkDecode(b *testing.B, decode func(bin byteio.Reader, v *Header) error) {
+	v := testHeader()
+	buf := bytes.NewBuffer(nil)
+	v.EncodeTo(buf)
+	in := buf.Bytes()
+	bin := bytes.NewReader(in)
+	b.SetBytes(int64(len(in)))
+	for i := 0; i < b.N; i++ {
+		bin.Reset(in)
+		if err := decode(bin, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDecodeFrom and BenchmarkUnmarshal compare the generated decoder
+// with the reflection-based one.
+func BenchmarkDecodeFrom(b *testing.B) {
+	benchmarkDecode(b, func(bin byteio.Reader, v *Header) error {
+		return v.DecodeFrom(bin)
+	})
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	benchmarkDecode(b, func(bin byteio.Reader, v *Header) error {
+		return byteio.Unmarshal(bin, v)
+	})
+}
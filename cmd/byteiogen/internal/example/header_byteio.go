@@ -0,0 +1,344 @@
+// Code generated by "byteiogen -type=Header,Inner,Node"; DO NOT EDIT.
+
+package example
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lwithers/pkg/byteio"
+)
+
+// DecodeFrom reads x from bin, as byteio.Unmarshal would.
+func (x *Header) DecodeFrom(bin byteio.Reader) error {
+	var err error
+	if _, err = io.ReadFull(bin, x.Magic[:]); err != nil {
+		return err
+	}
+	if x.Version, err = byteio.ReadUint16BE(bin); err != nil {
+		return midRecordHeader(true, err)
+	}
+	if x.Flags, err = byteio.ReadUint32LE(bin); err != nil {
+		return midRecordHeader(true, err)
+	}
+	if err = byteio.Skip(bin, 2); err != nil {
+		return midRecordHeader(true, err)
+	}
+	if x.Offset, err = byteio.ReadInt64BE(bin); err != nil {
+		return midRecordHeader(true, err)
+	}
+	if x.Ratio, err = byteio.ReadFloat32BE(bin); err != nil {
+		return midRecordHeader(true, err)
+	}
+	if x.Scale, err = byteio.ReadFloat64LE(bin); err != nil {
+		return midRecordHeader(true, err)
+	}
+	{
+		v, err := bin.ReadByte()
+		if err != nil {
+			return midRecordHeader(true, err)
+		}
+		x.Valid = v != 0
+	}
+	if err = byteio.Skip(bin, 3); err != nil {
+		return midRecordHeader(true, err)
+	}
+	{
+		var blank Inner
+		if err = blank.DecodeFrom(bin); err != nil {
+			return midRecordHeader(true, err)
+		}
+	}
+	if err = x.Inner.DecodeFrom(bin); err != nil {
+		return midRecordHeader(true, err)
+	}
+	for i := range x.Pair {
+		if x.Pair[i], err = byteio.ReadInt16LE(bin); err != nil {
+			return midRecordHeader(true, err)
+		}
+	}
+	{
+		v, err := byteio.ReadUint16LE(bin)
+		if err != nil {
+			return midRecordHeader(true, err)
+		}
+		x.Kind = Kind(v)
+	}
+	if _, err = io.ReadFull(bin, x.Addr[:]); err != nil {
+		return midRecordHeader(true, err)
+	}
+	for i := range x.Grid {
+		if _, err = io.ReadFull(bin, x.Grid[i][:]); err != nil {
+			return midRecordHeader(true, err)
+		}
+	}
+	if x.Count, err = bin.ReadByte(); err != nil {
+		return midRecordHeader(true, err)
+	}
+	{
+		n, c := int(x.Count), int(x.Count)
+		if c > 1024 {
+			c = 1024
+		}
+		x.Items = make([]uint32, 0, c)
+		for i := 0; i < n; i++ {
+			var e uint32
+			if e, err = byteio.ReadUint32LE(bin); err != nil {
+				return midRecordHeader(true, err)
+			}
+			x.Items = append(x.Items, e)
+		}
+	}
+	{
+		n, c := int(x.Count), int(x.Count)
+		if c > 1024 {
+			c = 1024
+		}
+		x.Kinds = make([]Kind, 0, c)
+		for i := 0; i < n; i++ {
+			var e Kind
+			{
+				v, err := byteio.ReadUint16BE(bin)
+				if err != nil {
+					return midRecordHeader(true, err)
+				}
+				e = Kind(v)
+			}
+			x.Kinds = append(x.Kinds, e)
+		}
+	}
+	if x.NameLen, err = byteio.ReadUint16BE(bin); err != nil {
+		return midRecordHeader(true, err)
+	}
+	{
+		buf, err := byteio.ReadBytes(bin, int(x.NameLen))
+		if err != nil {
+			return midRecordHeader(true, err)
+		}
+		x.Name = string(buf)
+	}
+	if x.DataLen, err = byteio.ReadInt32LE(bin); err != nil {
+		return midRecordHeader(true, err)
+	}
+	if x.DataLen < 0 {
+		return fmt.Errorf("byteio: Data: negative length %d", x.DataLen)
+	}
+	if x.Data, err = byteio.ReadBytes(bin, int(x.DataLen)); err != nil {
+		return midRecordHeader(true, err)
+	}
+	{
+		n, c := int(x.Count), int(x.Count)
+		if c > 1024 {
+			c = 1024
+		}
+		x.Inners = make([]Inner, 0, c)
+		for i := 0; i < n; i++ {
+			var e Inner
+			if err = e.DecodeFrom(bin); err != nil {
+				return midRecordHeader(true, err)
+			}
+			x.Inners = append(x.Inners, e)
+		}
+	}
+	return nil
+}
+
+// EncodeTo writes x to bout, as byteio.Marshal would.
+func (x *Header) EncodeTo(bout byteio.Writer) error {
+	if _, err := bout.Write(x.Magic[:]); err != nil {
+		return err
+	}
+	if err := byteio.WriteUint16BE(bout, x.Version); err != nil {
+		return err
+	}
+	if err := byteio.WriteUint32LE(bout, x.Flags); err != nil {
+		return err
+	}
+	for i := 0; i < 2; i++ {
+		if err := bout.WriteByte(0); err != nil {
+			return err
+		}
+	}
+	if err := byteio.WriteInt64BE(bout, x.Offset); err != nil {
+		return err
+	}
+	if err := byteio.WriteFloat32BE(bout, x.Ratio); err != nil {
+		return err
+	}
+	if err := byteio.WriteFloat64LE(bout, x.Scale); err != nil {
+		return err
+	}
+	{
+		var b byte
+		if x.Valid {
+			b = 1
+		}
+		if err := bout.WriteByte(b); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if err := bout.WriteByte(0); err != nil {
+			return err
+		}
+	}
+	{
+		var blank Inner
+		if err := blank.EncodeTo(bout); err != nil {
+			return err
+		}
+	}
+	if err := x.Inner.EncodeTo(bout); err != nil {
+		return err
+	}
+	for i := range x.Pair {
+		if err := byteio.WriteInt16LE(bout, x.Pair[i]); err != nil {
+			return err
+		}
+	}
+	if err := byteio.WriteUint16LE(bout, uint16(x.Kind)); err != nil {
+		return err
+	}
+	if _, err := bout.Write(x.Addr[:]); err != nil {
+		return err
+	}
+	for i := range x.Grid {
+		if _, err := bout.Write(x.Grid[i][:]); err != nil {
+			return err
+		}
+	}
+	if err := bout.WriteByte(x.Count); err != nil {
+		return err
+	}
+	if uint64(len(x.Items)) != uint64(x.Count) {
+		return fmt.Errorf("byteio: Items: length %d does not match len= field value %d", len(x.Items), x.Count)
+	}
+	for i := range x.Items {
+		if err := byteio.WriteUint32LE(bout, x.Items[i]); err != nil {
+			return err
+		}
+	}
+	if uint64(len(x.Kinds)) != uint64(x.Count) {
+		return fmt.Errorf("byteio: Kinds: length %d does not match len= field value %d", len(x.Kinds), x.Count)
+	}
+	for i := range x.Kinds {
+		if err := byteio.WriteUint16BE(bout, uint16(x.Kinds[i])); err != nil {
+			return err
+		}
+	}
+	if err := byteio.WriteUint16BE(bout, x.NameLen); err != nil {
+		return err
+	}
+	if uint64(len(x.Name)) != uint64(x.NameLen) {
+		return fmt.Errorf("byteio: Name: length %d does not match len= field value %d", len(x.Name), x.NameLen)
+	}
+	if _, err := io.WriteString(bout, x.Name); err != nil {
+		return err
+	}
+	if err := byteio.WriteInt32LE(bout, x.DataLen); err != nil {
+		return err
+	}
+	if uint64(len(x.Data)) != uint64(x.DataLen) {
+		return fmt.Errorf("byteio: Data: length %d does not match len= field value %d", len(x.Data), x.DataLen)
+	}
+	if _, err := bout.Write(x.Data); err != nil {
+		return err
+	}
+	if uint64(len(x.Inners)) != uint64(x.Count) {
+		return fmt.Errorf("byteio: Inners: length %d does not match len= field value %d", len(x.Inners), x.Count)
+	}
+	for i := range x.Inners {
+		if err := x.Inners[i].EncodeTo(bout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeFrom reads x from bin, as byteio.Unmarshal would.
+func (x *Inner) DecodeFrom(bin byteio.Reader) error {
+	var err error
+	if x.A, err = byteio.ReadUint16LE(bin); err != nil {
+		return err
+	}
+	{
+		v, err := bin.ReadByte()
+		if err != nil {
+			return midRecordHeader(true, err)
+		}
+		x.B = int8(v)
+	}
+	return nil
+}
+
+// EncodeTo writes x to bout, as byteio.Marshal would.
+func (x *Inner) EncodeTo(bout byteio.Writer) error {
+	if err := byteio.WriteUint16LE(bout, x.A); err != nil {
+		return err
+	}
+	if err := bout.WriteByte(byte(x.B)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DecodeFrom reads x from bin, as byteio.Unmarshal would.
+func (x *Node) DecodeFrom(bin byteio.Reader) error {
+	var err error
+	if x.Val, err = bin.ReadByte(); err != nil {
+		return err
+	}
+	if x.N, err = bin.ReadByte(); err != nil {
+		return midRecordHeader(true, err)
+	}
+	{
+		n, c := int(x.N), int(x.N)
+		if c > 1024 {
+			c = 1024
+		}
+		x.Children = make([]Node, 0, c)
+		for i := 0; i < n; i++ {
+			var e Node
+			if err = e.DecodeFrom(bin); err != nil {
+				return midRecordHeader(true, err)
+			}
+			x.Children = append(x.Children, e)
+		}
+	}
+	return nil
+}
+
+// EncodeTo writes x to bout, as byteio.Marshal would.
+func (x *Node) EncodeTo(bout byteio.Writer) error {
+	if err := bout.WriteByte(x.Val); err != nil {
+		return err
+	}
+	if err := bout.WriteByte(x.N); err != nil {
+		return err
+	}
+	if uint64(len(x.Children)) != uint64(x.N) {
+		return fmt.Errorf("byteio: Children: length %d does not match len= field value %d", len(x.Children), x.N)
+	}
+	for i := range x.Children {
+		if err := x.Children[i].EncodeTo(bout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// midRecordHeader adjusts the error from a failed read as byteio.Unmarshal
+// does: if part of the record had already been read, io.EOF becomes
+// io.ErrUnexpectedEOF and a *byteio.AbortError is marked Partial.
+func midRecordHeader(started bool, err error) error {
+	if !started {
+		return err
+	}
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if ae, ok := err.(*byteio.AbortError); ok && !ae.Partial {
+		return &byteio.AbortError{Err: ae.Err, Partial: true}
+	}
+	return err
+}
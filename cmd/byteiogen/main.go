@@ -0,0 +1,100 @@
+/*
+Byteiogen generates fast binary encoding and decoding methods for struct
+types, as an alternative to the reflection-based byteio.Unmarshal and
+byteio.Marshal. For each named type it writes methods
+
+	func (x *T) DecodeFrom(bin byteio.Reader) error
+	func (x *T) EncodeTo(bout byteio.Writer) error
+
+which call the byteio fixed-width functions directly. The struct tags and the
+resulting encoding are exactly those described for byteio.Unmarshal, so a
+type may be switched between the two freely.
+
+It is intended to be run by go generate, for example:
+
+	//go:generate go run github.com/lwithers/pkg/cmd/byteiogen -type=Header,Point
+
+Usage:
+
+	byteiogen -type=T,... [-output file] [dir]
+
+The package in dir (default ".") is parsed and the methods for the listed
+types are written to file, by default <t>_byteio.go in dir where <t> is the
+lower-cased name of the first type. Struct types nested within a listed type
+must also be listed, since their fields are encoded by calling their methods.
+Build constraints are not evaluated, so all of the package's non-test files
+are parsed.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of type names; required")
+	output := flag.String("output", "", "output file name; default <dir>/<type>_byteio.go")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: byteiogen -type=T,... [-output file] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *typeNames == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+	types := strings.Split(*typeNames, ",")
+
+	if err := run(dir, types, *output); err != nil {
+		fmt.Fprintln(os.Stderr, "byteiogen:", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the methods for types in the package in dir, writing them to
+// output.
+func run(dir string, types []string, output string) error {
+	if output == "" {
+		output = filepath.Join(dir, strings.ToLower(types[0])+"_byteio.go")
+	}
+
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range names {
+		// skip tests, and the output of any previous run
+		if strings.HasSuffix(name, "_test.go") ||
+			filepath.Clean(name) == filepath.Clean(output) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no Go files in %s", dir)
+	}
+
+	cmd := "byteiogen " + strings.Join(os.Args[1:], " ")
+	src, err := generate(fset, files, types, cmd)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, src, 0644)
+}
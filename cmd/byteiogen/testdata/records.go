@@ -0,0 +1,36 @@
+package records
+
+//go:generate byteiogen -type=Sample,Point
+
+type (
+	Level  int8
+	Flag   bool
+	Blob   []byte
+	Label  string
+	Points []Point
+	Alias  = uint32
+)
+
+type Point struct {
+	X, Y float32 `byteio:"le"`
+}
+
+type Sample struct {
+	Matrix [2][2]int16
+	Lvl    Level
+	On     Flag
+	Ch     rune
+	A      Alias `byteio:"le"`
+	Len    int64
+	Blob   Blob  `byteio:"len=Len"`
+	Label  Label `byteio:"len=Len"`
+	N      uint32
+	Pts    Points  `byteio:"len=N"`
+	Lvls   []Level `byteio:"len=N"`
+	_      [2]uint16
+	Point  `byteio:"skip=1"`
+	_      Point
+	Z      uint8
+	_      Blob    `byteio:"len=Z"`
+	_      []Point `byteio:"len=Z"`
+}
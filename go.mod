@@ -1,3 +1,5 @@
 module github.com/lwithers/pkg
 
+go 1.18
+
 require golang.org/x/sys v0.0.0-20180924175946-90868a75fefd